@@ -61,14 +61,28 @@ func main() {
 			logging.Log.Fatalf("storage.CloseDB in main: %w", err)
 		}
 	}()
-	app := cutter.New(storage)
+	app, err := cutter.New(storage, conf)
+	if err != nil {
+		logging.Log.Fatalf("cutter.New: %w", err)
+	}
 	server := serverapi.New(app, conf)
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 	defer stop()
+	go app.SweepExpired(ctx, conf.GetExpirySweepInterval())
+	go app.PurgeDeleted(ctx, conf.GetPurgeInterval(), conf.GetDeletedRetention())
+	if err = app.StartDeleteWorkers(ctx, conf.GetDeleteWorkers()); err != nil {
+		logging.Log.Fatalf("app.StartDeleteWorkers: %w", err)
+	}
+	app.StartClickWriter(ctx)
 	err = server.Run(ctx)
 	if err != nil {
 		panic(err)
 	}
+	// фоновые обработчики останавливаются по тому же сигналу, что и сервер;
+	// незавершенные задачи удаления сохраняются в хранилище и продолжатся при следующем запуске,
+	// накопленные переходы записываются перед остановкой
+	app.WaitWorkers()
+	logging.Log.Info("background workers stopped")
 }
 
 // initStore отвечает за инициализацию хранилища сокращений.
@@ -17,6 +17,8 @@ require (
 	github.com/swaggo/swag v1.16.3
 	go.uber.org/goleak v1.3.0
 	go.uber.org/zap v1.26.0
+	golang.org/x/crypto v0.22.0
+	golang.org/x/net v0.24.0
 	golang.org/x/tools v0.20.0
 	honnef.co/go/tools v0.4.7
 )
@@ -37,7 +39,6 @@ require (
 	github.com/rogpeppe/go-internal v1.12.0 // indirect
 	github.com/sethvargo/go-retry v0.2.4 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
-	golang.org/x/crypto v0.22.0 // indirect
 	golang.org/x/exp/typeparams v0.0.0-20221208152030-732eee02a75a // indirect
 	golang.org/x/mod v0.17.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
@@ -7,8 +7,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -17,8 +20,35 @@ import (
 
 // Значения по умолчанию.
 const (
-	defHost      = "localhost:8080"
-	defShortHost = "http://localhost:8080"
+	defHost           = "localhost:8080"
+	defShortHost      = "http://localhost:8080"
+	defShortGenerator = "random"
+	defShortLength    = 8
+	defShortAlphabet  = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	defExpirySweep    = time.Minute
+	defAllowedSchemes = "http,https"
+	defMaxURLLength   = 2048
+	defPurgeInterval  = time.Hour
+	defRetention      = 30 * 24 * time.Hour
+	defDeleteWorkers  = 4
+	defRedirectType   = "307"
+	defFileSyncPeriod = time.Second
+	defFileCompact    = 10 * time.Minute
+	// noStripParams отключает удаление параметров запроса при нормализации URL, как и пустое значение
+	noStripParams = "none"
+)
+
+// Режимы уникальности оригинального URL.
+const (
+	URLUniqueGlobal = "global" // URL сокращается один раз для всех пользователей
+	URLUniqueUser   = "user"   // каждый пользователь получает собственное сокращение URL
+)
+
+// Политики сброса журналов файлового хранилища на диск (fsync).
+const (
+	FileSyncAlways   = "always"   // после каждой записи
+	FileSyncInterval = "interval" // периодически, см. GetFileSyncInterval
+	FileSyncNever    = "never"    // сброс на диск выполняет ОС
 )
 
 // Ключи для данных передающихся в контексте.
@@ -32,6 +62,25 @@ type ContextKey struct {
 	name string
 }
 
+// Duration - time.Duration, который в json-файле конфигурации задается строкой, например "1m30s".
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON реализует json.Unmarshaler для Duration.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration: %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("duration: %w", err)
+	}
+	d.Duration = v
+	return nil
+}
+
 // Config хранит параметры для запуска сервера.
 type Config struct {
 	URL           string `json:"server_address"`
@@ -39,7 +88,56 @@ type Config struct {
 	FileStoreName string `json:"file_storage_path"`
 	DBConnName    string `json:"database_dsn"`
 	EnableHTTPS   bool   `json:"enable_https"`
-	filePath      string
+	// AllowPrivateURLs разрешить сокращать URL с адресами локальной сети и loopback
+	AllowPrivateURLs bool `json:"allow_private_urls"`
+	// NormalizeSortQuery сортировать параметры запроса при нормализации URL
+	NormalizeSortQuery bool `json:"normalize_sort_query"`
+	// ComingSoonPage отдавать страницу "скоро" вместо пустого ответа 404 до начала работы сокращения
+	ComingSoonPage bool `json:"coming_soon_page"`
+	// ShortGenerator тип генератора сокращений: random, sequence или hashid
+	ShortGenerator string `json:"short_generator"`
+	// ShortAlphabet символы, из которых состоят сгенерированные сокращения
+	ShortAlphabet string `json:"short_alphabet"`
+	// ShortSalt соль для генератора hashid
+	ShortSalt string `json:"short_salt"`
+	// AllowedSchemes разрешенные схемы сокращаемых URL через запятую
+	AllowedSchemes string `json:"allowed_schemes"`
+	// ShortDomains дополнительные базовые адреса сокращений через запятую, например https://go.brand.com
+	ShortDomains string `json:"short_domains"`
+	// BlocklistFile файл со списком заблокированных хостов и доменов
+	BlocklistFile string `json:"blocklist_file"`
+	// URLUniqueness режим уникальности URL: global или user
+	URLUniqueness string `json:"url_uniqueness"`
+	// FileSync политика сброса журналов файлового хранилища на диск: always, interval или never
+	FileSync string `json:"file_sync"`
+	// TrustedSubnet подсеть в CIDR-нотации, из которой доступны служебные эндпоинты /api/internal
+	TrustedSubnet string `json:"trusted_subnet"`
+	// TrustedProxies подсети прокси в CIDR-нотации через запятую, от которых принимаются заголовки X-Real-IP и X-Forwarded-For
+	TrustedProxies string `json:"trusted_proxies"`
+	// ClickSalt соль хэша IP клиентов в статистике переходов
+	ClickSalt string `json:"click_salt"`
+	// RedirectType способ перехода по сокращениям без собственного способа: 301, 302, 307, 308, meta-refresh или interstitial
+	RedirectType string `json:"redirect_type"`
+	// StripQueryParams параметры запроса через запятую, удаляемые из URL перед сокращением; "utm_*" - по префиксу.
+	// Пусто - параметры не удаляются
+	StripQueryParams string `json:"strip_query_params"`
+	filePath         string
+	// ShortLength длина сокращения (для sequence и hashid - минимальная)
+	ShortLength int `json:"short_length"`
+	// MaxURLLength максимальная длина сокращаемого URL
+	MaxURLLength int `json:"max_url_length"`
+	// DeleteWorkers количество обработчиков очереди удаления сокращений
+	DeleteWorkers int `json:"delete_workers"`
+	// ExpirySweepInterval период поиска сокращений с истекшим сроком действия
+	ExpirySweepInterval Duration `json:"expiry_sweep_interval"`
+	// DeletedRetention срок хранения удаленных пользователем сокращений до окончательного удаления
+	DeletedRetention Duration `json:"deleted_retention"`
+	// PurgeInterval период окончательного удаления сокращений с истекшим сроком хранения
+	PurgeInterval Duration `json:"purge_interval"`
+	// FileSyncInterval период сброса журналов файлового хранилища на диск при политике interval
+	FileSyncInterval Duration `json:"file_sync_interval"`
+	// FileCompactInterval период компактирования файлового хранилища
+	FileCompactInterval Duration `json:"file_compact_interval"`
 }
 
 // ParseConfig - запускает парсинг флагов и анализирует переменные окружения.
@@ -73,6 +171,146 @@ func ParseConfig() (conf Config, err error) {
 		conf.EnableHTTPS = b
 	}
 
+	if os.Getenv("SHORT_DOMAINS") != "" {
+		conf.ShortDomains = os.Getenv("SHORT_DOMAINS")
+	}
+
+	if os.Getenv("SHORT_GENERATOR") != "" {
+		conf.ShortGenerator = os.Getenv("SHORT_GENERATOR")
+	}
+
+	if os.Getenv("SHORT_LENGTH") != "" {
+		l, err := strconv.Atoi(os.Getenv("SHORT_LENGTH"))
+		if err != nil {
+			logging.Log.Errorw("fails to read SHORT_LENGTH", zap.Error(err))
+		}
+		conf.ShortLength = l
+	}
+
+	if os.Getenv("SHORT_ALPHABET") != "" {
+		conf.ShortAlphabet = os.Getenv("SHORT_ALPHABET")
+	}
+
+	if os.Getenv("SHORT_SALT") != "" {
+		conf.ShortSalt = os.Getenv("SHORT_SALT")
+	}
+
+	if os.Getenv("EXPIRY_SWEEP_INTERVAL") != "" {
+		d, err := time.ParseDuration(os.Getenv("EXPIRY_SWEEP_INTERVAL"))
+		if err != nil {
+			logging.Log.Errorw("fails to read EXPIRY_SWEEP_INTERVAL", zap.Error(err))
+		}
+		conf.ExpirySweepInterval.Duration = d
+	}
+
+	if os.Getenv("DELETED_RETENTION") != "" {
+		d, err := time.ParseDuration(os.Getenv("DELETED_RETENTION"))
+		if err != nil {
+			logging.Log.Errorw("fails to read DELETED_RETENTION", zap.Error(err))
+		}
+		conf.DeletedRetention.Duration = d
+	}
+
+	if os.Getenv("PURGE_INTERVAL") != "" {
+		d, err := time.ParseDuration(os.Getenv("PURGE_INTERVAL"))
+		if err != nil {
+			logging.Log.Errorw("fails to read PURGE_INTERVAL", zap.Error(err))
+		}
+		conf.PurgeInterval.Duration = d
+	}
+
+	if os.Getenv("FILE_SYNC") != "" {
+		conf.FileSync = os.Getenv("FILE_SYNC")
+	}
+
+	if os.Getenv("FILE_SYNC_INTERVAL") != "" {
+		d, err := time.ParseDuration(os.Getenv("FILE_SYNC_INTERVAL"))
+		if err != nil {
+			logging.Log.Errorw("fails to read FILE_SYNC_INTERVAL", zap.Error(err))
+		}
+		conf.FileSyncInterval.Duration = d
+	}
+
+	if os.Getenv("FILE_COMPACT_INTERVAL") != "" {
+		d, err := time.ParseDuration(os.Getenv("FILE_COMPACT_INTERVAL"))
+		if err != nil {
+			logging.Log.Errorw("fails to read FILE_COMPACT_INTERVAL", zap.Error(err))
+		}
+		conf.FileCompactInterval.Duration = d
+	}
+
+	if os.Getenv("DELETE_WORKERS") != "" {
+		n, err := strconv.Atoi(os.Getenv("DELETE_WORKERS"))
+		if err != nil {
+			logging.Log.Errorw("fails to read DELETE_WORKERS", zap.Error(err))
+		}
+		conf.DeleteWorkers = n
+	}
+
+	if os.Getenv("TRUSTED_SUBNET") != "" {
+		conf.TrustedSubnet = os.Getenv("TRUSTED_SUBNET")
+	}
+
+	if os.Getenv("TRUSTED_PROXIES") != "" {
+		conf.TrustedProxies = os.Getenv("TRUSTED_PROXIES")
+	}
+
+	if os.Getenv("CLICK_SALT") != "" {
+		conf.ClickSalt = os.Getenv("CLICK_SALT")
+	}
+
+	if os.Getenv("REDIRECT_TYPE") != "" {
+		conf.RedirectType = os.Getenv("REDIRECT_TYPE")
+	}
+
+	if os.Getenv("NORMALIZE_SORT_QUERY") != "" {
+		b, err := strconv.ParseBool(os.Getenv("NORMALIZE_SORT_QUERY"))
+		if err != nil {
+			logging.Log.Errorw("fails to read NORMALIZE_SORT_QUERY", zap.Error(err))
+		}
+		conf.NormalizeSortQuery = b
+	}
+
+	if os.Getenv("STRIP_QUERY_PARAMS") != "" {
+		conf.StripQueryParams = os.Getenv("STRIP_QUERY_PARAMS")
+	}
+
+	if os.Getenv("URL_UNIQUENESS") != "" {
+		conf.URLUniqueness = os.Getenv("URL_UNIQUENESS")
+	}
+
+	if os.Getenv("ALLOWED_SCHEMES") != "" {
+		conf.AllowedSchemes = os.Getenv("ALLOWED_SCHEMES")
+	}
+
+	if os.Getenv("BLOCKLIST_FILE") != "" {
+		conf.BlocklistFile = os.Getenv("BLOCKLIST_FILE")
+	}
+
+	if os.Getenv("MAX_URL_LENGTH") != "" {
+		l, err := strconv.Atoi(os.Getenv("MAX_URL_LENGTH"))
+		if err != nil {
+			logging.Log.Errorw("fails to read MAX_URL_LENGTH", zap.Error(err))
+		}
+		conf.MaxURLLength = l
+	}
+
+	if os.Getenv("ALLOW_PRIVATE_URLS") != "" {
+		b, err := strconv.ParseBool(os.Getenv("ALLOW_PRIVATE_URLS"))
+		if err != nil {
+			logging.Log.Errorw("fails to read ALLOW_PRIVATE_URLS", zap.Error(err))
+		}
+		conf.AllowPrivateURLs = b
+	}
+
+	if os.Getenv("COMING_SOON_PAGE") != "" {
+		b, err := strconv.ParseBool(os.Getenv("COMING_SOON_PAGE"))
+		if err != nil {
+			logging.Log.Errorw("fails to read COMING_SOON_PAGE", zap.Error(err))
+		}
+		conf.ComingSoonPage = b
+	}
+
 	if p, b := os.LookupEnv("CONFIG"); b {
 		conf.filePath = p
 	}
@@ -81,13 +319,49 @@ func ParseConfig() (conf Config, err error) {
 		err = fmt.Errorf("config: loadfromFile: %w", err)
 	}
 
+	if m := conf.GetURLUniqueness(); err == nil && m != URLUniqueGlobal && m != URLUniqueUser {
+		err = fmt.Errorf("config: unknown url uniqueness mode %q", m)
+	}
+
+	if m := conf.GetFileSync(); err == nil && m != FileSyncAlways && m != FileSyncInterval && m != FileSyncNever {
+		err = fmt.Errorf("config: unknown file sync policy %q", m)
+	}
+
+	for _, p := range conf.GetTrustedProxies() {
+		if _, _, errCIDR := net.ParseCIDR(p); err == nil && errCIDR != nil {
+			err = fmt.Errorf("config: trusted proxies: %w", errCIDR)
+		}
+	}
+
 	logging.Log.Infow("starting config ",
 		zap.String("URL", conf.URL),
 		zap.String("shortAddress", conf.ShortAddress),
+		zap.Strings("shortDomains", conf.GetShortDomains()),
 		zap.String("fileStoreName", conf.FileStoreName),
 		zap.String("dbConnName", conf.DBConnName),
+		zap.String("fileSync", conf.GetFileSync()),
+		zap.Duration("fileSyncInterval", conf.GetFileSyncInterval()),
+		zap.Duration("fileCompactInterval", conf.GetFileCompactInterval()),
 		zap.Bool("ENABLE_HTTPS", conf.EnableHTTPS),
 		zap.String("CONFIG", conf.filePath),
+		zap.String("shortGenerator", conf.GetShortGenerator()),
+		zap.Int("shortLength", conf.GetShortLength()),
+		zap.String("shortAlphabet", conf.GetShortAlphabet()),
+		zap.Duration("expirySweepInterval", conf.GetExpirySweepInterval()),
+		zap.Duration("deletedRetention", conf.GetDeletedRetention()),
+		zap.Duration("purgeInterval", conf.GetPurgeInterval()),
+		zap.String("trustedSubnet", conf.GetTrustedSubnet()),
+		zap.Strings("trustedProxies", conf.GetTrustedProxies()),
+		zap.Int("deleteWorkers", conf.GetDeleteWorkers()),
+		zap.String("redirectType", conf.GetRedirectType()),
+		zap.Bool("normalizeSortQuery", conf.GetNormalizeSortQuery()),
+		zap.Strings("stripQueryParams", conf.GetStripQueryParams()),
+		zap.String("urlUniqueness", conf.GetURLUniqueness()),
+		zap.Strings("allowedSchemes", conf.GetAllowedSchemes()),
+		zap.String("blocklistFile", conf.GetBlocklistFile()),
+		zap.Int("maxURLLength", conf.GetMaxURLLength()),
+		zap.Bool("allowPrivateURLs", conf.GetAllowPrivateURLs()),
+		zap.Bool("comingSoonPage", conf.GetComingSoonPage()),
 		zap.Error(err),
 	)
 	return conf, err
@@ -103,11 +377,37 @@ func (c Config) GetShortAddress() string {
 	return c.ShortAddress
 }
 
+// GetShortDomains - получить дополнительные базовые адреса сокращений.
+func (c Config) GetShortDomains() []string {
+	var res []string
+	for _, d := range strings.Split(c.ShortDomains, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			res = append(res, strings.TrimSuffix(d, "/"))
+		}
+	}
+	return res
+}
+
 // GetFileStoreName - получить путь к файлу с сокращениями
 func (c Config) GetFileStoreName() string {
 	return c.FileStoreName
 }
 
+// GetFileSync - получить политику сброса журналов файлового хранилища на диск, см. FileSyncAlways.
+func (c Config) GetFileSync() string {
+	return notEmptyVal(c.FileSync, FileSyncInterval)
+}
+
+// GetFileSyncInterval - получить период сброса журналов файлового хранилища на диск при политике interval.
+func (c Config) GetFileSyncInterval() time.Duration {
+	return notEmptyVal(c.FileSyncInterval.Duration, defFileSyncPeriod)
+}
+
+// GetFileCompactInterval - получить период компактирования файлового хранилища.
+func (c Config) GetFileCompactInterval() time.Duration {
+	return notEmptyVal(c.FileCompactInterval.Duration, defFileCompact)
+}
+
 // GetDBConnName - получить DSN к DB
 func (c Config) GetDBConnName() string {
 	return c.DBConnName
@@ -118,13 +418,150 @@ func (c Config) GetEnableHTTPS() bool {
 	return c.EnableHTTPS
 }
 
+// GetShortGenerator - получить тип генератора сокращений.
+func (c Config) GetShortGenerator() string {
+	return notEmptyVal(c.ShortGenerator, defShortGenerator)
+}
+
+// GetShortLength - получить длину сгенерированного сокращения.
+func (c Config) GetShortLength() int {
+	return notEmptyVal(c.ShortLength, defShortLength)
+}
+
+// GetShortAlphabet - получить алфавит сгенерированных сокращений.
+func (c Config) GetShortAlphabet() string {
+	return notEmptyVal(c.ShortAlphabet, defShortAlphabet)
+}
+
+// GetShortSalt - получить соль генератора hashid.
+func (c Config) GetShortSalt() string {
+	return c.ShortSalt
+}
+
+// GetExpirySweepInterval - получить период поиска сокращений с истекшим сроком действия.
+func (c Config) GetExpirySweepInterval() time.Duration {
+	return notEmptyVal(c.ExpirySweepInterval.Duration, defExpirySweep)
+}
+
+// GetDeletedRetention - получить срок хранения удаленных сокращений до окончательного удаления.
+func (c Config) GetDeletedRetention() time.Duration {
+	return notEmptyVal(c.DeletedRetention.Duration, defRetention)
+}
+
+// GetPurgeInterval - получить период окончательного удаления сокращений с истекшим сроком хранения.
+func (c Config) GetPurgeInterval() time.Duration {
+	return notEmptyVal(c.PurgeInterval.Duration, defPurgeInterval)
+}
+
+// GetDeleteWorkers - получить количество обработчиков очереди удаления сокращений.
+func (c Config) GetDeleteWorkers() int {
+	return notEmptyVal(c.DeleteWorkers, defDeleteWorkers)
+}
+
+// GetClickSalt - получить соль хэша IP клиентов в статистике переходов.
+// Пустое значение заменяется случайной солью на время работы сервиса.
+func (c Config) GetClickSalt() string {
+	return c.ClickSalt
+}
+
+// GetRedirectType - получить способ перехода по сокращениям без собственного способа.
+func (c Config) GetRedirectType() string {
+	return notEmptyVal(c.RedirectType, defRedirectType)
+}
+
+// GetTrustedSubnet - получить подсеть, из которой доступны служебные эндпоинты.
+// Пустое значение закрывает доступ к ним.
+func (c Config) GetTrustedSubnet() string {
+	return c.TrustedSubnet
+}
+
+// GetTrustedProxies - получить подсети прокси, которым доверяются заголовки с IP клиента.
+// По умолчанию прокси нет: IP клиента - адрес соединения.
+func (c Config) GetTrustedProxies() []string {
+	var res []string
+	for _, p := range strings.Split(c.TrustedProxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// GetNormalizeSortQuery - сортировать ли параметры запроса при нормализации URL.
+func (c Config) GetNormalizeSortQuery() bool {
+	return c.NormalizeSortQuery
+}
+
+// GetStripQueryParams - получить параметры запроса, удаляемые из URL перед сокращением.
+// По умолчанию параметры не удаляются: удаление, например utm_*, включается явно.
+func (c Config) GetStripQueryParams() []string {
+	if c.StripQueryParams == "" || c.StripQueryParams == noStripParams {
+		return nil
+	}
+	return strings.Split(c.StripQueryParams, ",")
+}
+
+// GetURLUniqueness - получить режим уникальности URL, см. URLUniqueGlobal и URLUniqueUser.
+func (c Config) GetURLUniqueness() string {
+	return notEmptyVal(c.URLUniqueness, URLUniqueGlobal)
+}
+
+// GetAllowedSchemes - получить разрешенные схемы сокращаемых URL.
+func (c Config) GetAllowedSchemes() []string {
+	return strings.Split(notEmptyVal(c.AllowedSchemes, defAllowedSchemes), ",")
+}
+
+// GetBlocklistFile - получить путь к файлу со списком заблокированных хостов и доменов.
+func (c Config) GetBlocklistFile() string {
+	return c.BlocklistFile
+}
+
+// GetMaxURLLength - получить максимальную длину сокращаемого URL.
+func (c Config) GetMaxURLLength() int {
+	return notEmptyVal(c.MaxURLLength, defMaxURLLength)
+}
+
+// GetAllowPrivateURLs - разрешено ли сокращать URL с адресами локальной сети и loopback.
+func (c Config) GetAllowPrivateURLs() bool {
+	return c.AllowPrivateURLs
+}
+
+// GetComingSoonPage - отдавать ли страницу "скоро" до начала работы сокращения.
+func (c Config) GetComingSoonPage() bool {
+	return c.ComingSoonPage
+}
+
 func (c *Config) initFlags() {
 	flag.StringVar(&c.URL, "a", defHost, "server URL format host:port, :port")
 	flag.StringVar(&c.ShortAddress, "b", defShortHost, "Address for short url")
+	flag.StringVar(&c.ShortDomains, "short-domains", "", "comma separated additional addresses for short urls, e.g. https://go.brand.com")
 	flag.StringVar(&c.FileStoreName, "f", "", "file name for storage")
+	flag.StringVar(&c.FileSync, "file-sync", "", "file storage fsync policy: always, interval or never (default interval)")
+	flag.DurationVar(&c.FileSyncInterval.Duration, "file-sync-interval", 0, "interval of file storage fsync for interval policy (default 1s)")
+	flag.DurationVar(&c.FileCompactInterval.Duration, "file-compact-interval", 0, "interval of file storage compaction (default 10m)")
 	flag.StringVar(&c.DBConnName, "d", "", "database connection addres, format host=? port=? user=? password=? dbname=? sslmode=?")
 	flag.BoolVar(&c.EnableHTTPS, "s", false, "true for htts server start")
 	flag.StringVar(&c.filePath, "c", "", "path to config json file")
+	flag.StringVar(&c.ShortGenerator, "short-generator", "", "short url generator: random, sequence or hashid (default random)")
+	flag.IntVar(&c.ShortLength, "short-length", 0, "short url length, minimal for sequence and hashid (default 8)")
+	flag.StringVar(&c.ShortAlphabet, "short-alphabet", "", "short url alphabet (default base62)")
+	flag.StringVar(&c.ShortSalt, "short-salt", "", "salt for hashid short url generator")
+	flag.DurationVar(&c.ExpirySweepInterval.Duration, "expiry-sweep", 0, "interval of marking expired short urls (default 1m)")
+	flag.DurationVar(&c.DeletedRetention.Duration, "deleted-retention", 0, "how long deleted short urls are kept before purge (default 720h)")
+	flag.DurationVar(&c.PurgeInterval.Duration, "purge-interval", 0, "interval of purging deleted short urls (default 1h)")
+	flag.IntVar(&c.DeleteWorkers, "delete-workers", 0, "number of delete queue workers (default 4)")
+	flag.StringVar(&c.TrustedSubnet, "t", "", "trusted subnet in CIDR notation for /api/internal endpoints")
+	flag.StringVar(&c.TrustedProxies, "trusted-proxies", "", "comma separated proxy subnets in CIDR notation whose X-Real-IP and X-Forwarded-For headers are trusted")
+	flag.StringVar(&c.ClickSalt, "click-salt", "", "salt for hashing client ip in click stats (default random per run)")
+	flag.StringVar(&c.RedirectType, "redirect-type", "", "default redirect type: 301, 302, 307, 308, meta-refresh or interstitial (default 307)")
+	flag.StringVar(&c.URLUniqueness, "url-uniqueness", "", "url uniqueness scope: global or user (default global)")
+	flag.StringVar(&c.AllowedSchemes, "allowed-schemes", "", "comma separated allowed url schemes (default "+defAllowedSchemes+")")
+	flag.StringVar(&c.BlocklistFile, "blocklist-file", "", "file with blocked hosts and *.domains, one per line")
+	flag.IntVar(&c.MaxURLLength, "max-url-length", 0, "max length of url to cut (default 2048)")
+	flag.BoolVar(&c.AllowPrivateURLs, "allow-private-urls", false, "allow urls with private and loopback addresses")
+	flag.BoolVar(&c.NormalizeSortQuery, "normalize-sort-query", false, "sort query parameters of url before cut")
+	flag.BoolVar(&c.ComingSoonPage, "coming-soon-page", false, "show coming soon page instead of empty 404 for links before active_from")
+	flag.StringVar(&c.StripQueryParams, "strip-query-params", "", "comma separated query parameters removed from url before cut, e.g. utm_*,fbclid,gclid,yclid (default none)")
 	flag.Parse()
 }
 
@@ -145,9 +582,33 @@ func (c *Config) loadFromFile() error {
 
 	c.URL = notEmptyVal(c.URL, jConf.URL)
 	c.ShortAddress = notEmptyVal(c.ShortAddress, jConf.ShortAddress)
+	c.ShortDomains = notEmptyVal(c.ShortDomains, jConf.ShortDomains)
 	c.FileStoreName = notEmptyVal(c.FileStoreName, jConf.FileStoreName)
 	c.DBConnName = notEmptyVal(c.DBConnName, jConf.DBConnName)
+	c.FileSync = notEmptyVal(c.FileSync, jConf.FileSync)
+	c.FileSyncInterval = notEmptyVal(c.FileSyncInterval, jConf.FileSyncInterval)
+	c.FileCompactInterval = notEmptyVal(c.FileCompactInterval, jConf.FileCompactInterval)
 	c.EnableHTTPS = notEmptyVal(c.EnableHTTPS, jConf.EnableHTTPS)
+	c.ShortGenerator = notEmptyVal(c.ShortGenerator, jConf.ShortGenerator)
+	c.ShortLength = notEmptyVal(c.ShortLength, jConf.ShortLength)
+	c.ShortAlphabet = notEmptyVal(c.ShortAlphabet, jConf.ShortAlphabet)
+	c.ShortSalt = notEmptyVal(c.ShortSalt, jConf.ShortSalt)
+	c.ExpirySweepInterval = notEmptyVal(c.ExpirySweepInterval, jConf.ExpirySweepInterval)
+	c.DeletedRetention = notEmptyVal(c.DeletedRetention, jConf.DeletedRetention)
+	c.PurgeInterval = notEmptyVal(c.PurgeInterval, jConf.PurgeInterval)
+	c.DeleteWorkers = notEmptyVal(c.DeleteWorkers, jConf.DeleteWorkers)
+	c.TrustedSubnet = notEmptyVal(c.TrustedSubnet, jConf.TrustedSubnet)
+	c.TrustedProxies = notEmptyVal(c.TrustedProxies, jConf.TrustedProxies)
+	c.ClickSalt = notEmptyVal(c.ClickSalt, jConf.ClickSalt)
+	c.RedirectType = notEmptyVal(c.RedirectType, jConf.RedirectType)
+	c.NormalizeSortQuery = notEmptyVal(c.NormalizeSortQuery, jConf.NormalizeSortQuery)
+	c.StripQueryParams = notEmptyVal(c.StripQueryParams, jConf.StripQueryParams)
+	c.URLUniqueness = notEmptyVal(c.URLUniqueness, jConf.URLUniqueness)
+	c.AllowedSchemes = notEmptyVal(c.AllowedSchemes, jConf.AllowedSchemes)
+	c.BlocklistFile = notEmptyVal(c.BlocklistFile, jConf.BlocklistFile)
+	c.MaxURLLength = notEmptyVal(c.MaxURLLength, jConf.MaxURLLength)
+	c.AllowPrivateURLs = notEmptyVal(c.AllowPrivateURLs, jConf.AllowPrivateURLs)
+	c.ComingSoonPage = notEmptyVal(c.ComingSoonPage, jConf.ComingSoonPage)
 	return nil
 }
 func notEmptyVal[T comparable](c T, j T) T {
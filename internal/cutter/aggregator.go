@@ -0,0 +1,151 @@
+package cutter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// Окно объединения запросов на удаление.
+const (
+	deleteFlushInterval = 50 * time.Millisecond // максимальное ожидание первого запроса в пачке
+	deleteFlushSize     = 1000                  // количество сокращений, при котором пачка удаляется сразу
+)
+
+// deleteRequest запрос обработчика очереди на удаление сокращений пользователя.
+type deleteRequest struct {
+	res    chan deleteResponse
+	userID string
+	codes  []string
+}
+
+type deleteResponse struct {
+	err error
+	res jsonobject.DeleteResult
+}
+
+// deleteAggregator объединяет запросы на удаление от всех обработчиков очереди
+// и удаляет их одним вызовом хранилища: по истечении deleteFlushInterval с первого запроса пачки,
+// при накоплении deleteFlushSize сокращений или когда все обработчики ждут результата.
+type deleteAggregator struct {
+	storage  Store
+	requests chan deleteRequest
+	// producers количество обработчиков очереди, отправляющих запросы
+	producers int
+	interval  time.Duration
+	size      int
+}
+
+func newDeleteAggregator(s Store, producers int) *deleteAggregator {
+	return &deleteAggregator{
+		storage:   s,
+		requests:  make(chan deleteRequest),
+		producers: producers,
+		interval:  deleteFlushInterval,
+		size:      deleteFlushSize,
+	}
+}
+
+// delete передает сокращения пользователя userID в текущую пачку и ждет ее удаления.
+func (g *deleteAggregator) delete(userID string, codes []string) (jsonobject.DeleteResult, error) {
+	req := deleteRequest{userID: userID, codes: codes, res: make(chan deleteResponse, 1)}
+	g.requests <- req
+	resp := <-req.res
+	return resp.res, resp.err
+}
+
+// stop завершает run после удаления накопленной пачки.
+// Вызывается, когда все обработчики очереди остановлены.
+func (g *deleteAggregator) stop() {
+	close(g.requests)
+}
+
+// run собирает запросы в пачки и удаляет их, пока не будет вызван stop.
+// Удаление не прерывается остановкой сервиса: запросы ждут его обработчики очереди.
+func (g *deleteAggregator) run(ctx context.Context) {
+	var (
+		batch []deleteRequest
+		size  int
+		timer *time.Timer
+		fire  <-chan time.Time
+	)
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, fire = nil, nil
+		}
+		g.flush(ctx, batch)
+		batch, size = nil, 0
+	}
+	for {
+		select {
+		case req, ok := <-g.requests:
+			if !ok {
+				if len(batch) > 0 {
+					flush()
+				}
+				return
+			}
+			batch = append(batch, req)
+			size += len(req.codes)
+			if len(batch) == 1 {
+				timer = time.NewTimer(g.interval)
+				fire = timer.C
+			}
+			if size >= g.size || len(batch) >= g.producers {
+				flush()
+			}
+		case <-fire:
+			flush()
+		}
+	}
+}
+
+// flush удаляет пачку одним вызовом хранилища и раздает результаты запросам.
+// Сокращение, которое в пачке удаляют несколько запросов одного пользователя, засчитывается первому из них.
+func (g *deleteAggregator) flush(ctx context.Context, batch []deleteRequest) {
+	index := make(map[jsonobject.DeleteItem]int)
+	var items []jsonobject.DeleteItem
+	for _, req := range batch {
+		for _, code := range req.codes {
+			item := jsonobject.DeleteItem{UserID: req.userID, Short: code}
+			if _, isFound := index[item]; !isFound {
+				index[item] = len(items)
+				items = append(items, item)
+			}
+		}
+	}
+	statuses, err := g.storage.DeleteURLs(ctx, items)
+	if err == nil && len(statuses) != len(items) {
+		err = fmt.Errorf("storage returned %d statuses for %d urls", len(statuses), len(items))
+	}
+	if err != nil {
+		for _, req := range batch {
+			req.res <- deleteResponse{err: fmt.Errorf("delete batch: %w", err)}
+		}
+		return
+	}
+
+	reported := make(map[int]struct{}, len(items))
+	for _, req := range batch {
+		var res jsonobject.DeleteResult
+		for _, code := range req.codes {
+			i := index[jsonobject.DeleteItem{UserID: req.userID, Short: code}]
+			if _, isFound := reported[i]; isFound {
+				continue
+			}
+			reported[i] = struct{}{}
+			switch statuses[i] {
+			case jsonobject.DeleteDone:
+				res.Deleted++
+			case jsonobject.DeleteNotFound:
+				res.NotFound = append(res.NotFound, code)
+			case jsonobject.DeleteNotOwned:
+				res.NotOwned = append(res.NotOwned, code)
+			}
+		}
+		req.res <- deleteResponse{res: res}
+	}
+}
@@ -0,0 +1,126 @@
+package cutter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/mocks"
+)
+
+// startAggregator запускает deleteAggregator для одного обработчика очереди.
+func startAggregator(s Store) *deleteAggregator {
+	agg := newDeleteAggregator(s, 1)
+	go agg.run(context.Background())
+	return agg
+}
+
+// testCodes возвращает n различных сокращений.
+func testCodes(n int) []string {
+	res := make([]string, n)
+	for i := range res {
+		res[i] = fmt.Sprintf("code%d", i)
+	}
+	return res
+}
+
+// deletedStatuses отвечает, что удалены все сокращения.
+func deletedStatuses(items []jsonobject.DeleteItem) []jsonobject.DeleteStatus {
+	res := make([]jsonobject.DeleteStatus, len(items))
+	for i := range res {
+		res[i] = jsonobject.DeleteDone
+	}
+	return res
+}
+
+func TestDeleteAggregator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+
+	m.EXPECT().DeleteURLs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, items []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error) {
+			assert.ElementsMatch(t, []jsonobject.DeleteItem{
+				{UserID: "first", Short: "a"},
+				{UserID: "first", Short: "b"},
+				{UserID: "first", Short: "c"},
+				{UserID: "second", Short: "a"},
+				{UserID: "second", Short: "d"},
+			}, items, "requests of all users in one call, duplicates removed")
+			res := make([]jsonobject.DeleteStatus, len(items))
+			for i, item := range items {
+				switch item.Short {
+				case "a":
+					res[i] = jsonobject.DeleteDone
+				case "b":
+					res[i] = jsonobject.DeleteSkipped
+				case "c":
+					res[i] = jsonobject.DeleteNotFound
+				case "d":
+					res[i] = jsonobject.DeleteNotOwned
+				}
+			}
+			return res, nil
+		}).Times(1)
+
+	agg := newDeleteAggregator(m, 3)
+	agg.interval = time.Minute
+	go agg.run(context.Background())
+	defer agg.stop()
+
+	var wg sync.WaitGroup
+	results := make(map[string]jsonobject.DeleteResult)
+	var mu sync.Mutex
+	send := func(name, userID string, codes ...string) {
+		defer wg.Done()
+		res, err := agg.delete(userID, codes)
+		require.NoError(t, err)
+		mu.Lock()
+		results[name] = res
+		mu.Unlock()
+	}
+	wg.Add(3)
+	go send("first", "first", "a", "b", "c", "a")
+	go send("second", "second", "a", "d")
+	go send("first again", "first", "a")
+	wg.Wait()
+
+	total := results["first"].Deleted + results["first again"].Deleted
+	assert.Equal(t, int64(1), total, "code deleted once is counted once")
+	assert.Equal(t, jsonobject.ShortIds{"c"}, results["first"].NotFound)
+	assert.Equal(t, jsonobject.DeleteResult{Deleted: 1, NotOwned: jsonobject.ShortIds{"d"}}, results["second"])
+}
+
+func TestDeleteAggregatorFlush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	m.EXPECT().DeleteURLs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, items []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error) {
+			return deletedStatuses(items), nil
+		}).Times(2)
+
+	agg := newDeleteAggregator(m, 10)
+	agg.interval = 20 * time.Millisecond
+	go agg.run(context.Background())
+	defer agg.stop()
+
+	start := time.Now()
+	res, err := agg.delete("user", []string{"a"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.Deleted)
+	assert.GreaterOrEqual(t, time.Since(start), agg.interval, "single request waits for the window")
+
+	agg.size = 2
+	agg.interval = time.Minute
+	res, err = agg.delete("user", []string{"b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Deleted, "full batch is flushed without waiting")
+}
@@ -0,0 +1,187 @@
+package cutter
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/logging"
+)
+
+// Параметры записи переходов.
+const (
+	clickBufferSize    = 4096        // переходов в очереди на запись, при переполнении новые переходы отбрасываются
+	clickFlushSize     = 500         // переходов в одной записи в хранилище
+	clickFlushInterval = time.Second // период записи неполной пачки
+	// maxClickFieldLength максимальная длина сохраняемых Referer и User-Agent
+	maxClickFieldLength = 512
+)
+
+// Параметры статистики переходов.
+const (
+	statsTopReferrers = 10         // количество источников перехода в статистике
+	directReferrer    = "(direct)" // источник прямых переходов, без Referer
+)
+
+// newClickSalt возвращает соль хэша IP клиентов.
+// Без соли в конфигурации используется случайная: уникальные посетители считаются только в пределах одного запуска.
+func newClickSalt(salt string) ([]byte, error) {
+	if salt != "" {
+		return []byte(salt), nil
+	}
+	res := make([]byte, sha256.Size)
+	if _, err := rand.Read(res); err != nil {
+		return nil, fmt.Errorf("random click salt: %w", err)
+	}
+	logging.Log.Warn("click salt is not configured, unique visitors are counted within one run")
+	return res, nil
+}
+
+// RecordClick ставит в очередь на запись переход по сокращению short на вариант URL variant.
+// Вместо IP клиента сохраняется его хэш с солью, см. newClickSalt.
+// Не блокирует переход: переходы записываются в хранилище пачками, см. StartClickWriter,
+// при переполнении очереди переход отбрасывается.
+func (a *App) RecordClick(short, variant, referrer, userAgent, ip string) {
+	event := jsonobject.ClickEvent{
+		At:        a.now().UTC(),
+		Short:     short,
+		Variant:   variant,
+		Referrer:  truncate(referrer, maxClickFieldLength),
+		UserAgent: truncate(userAgent, maxClickFieldLength),
+		IPHash:    a.hashIP(ip),
+	}
+	select {
+	case a.clicks <- event:
+	default:
+		logging.Log.Warnw("RecordClick: click queue is full, click dropped", "short", short)
+	}
+}
+
+// StartClickWriter запускает запись переходов из очереди в хранилище.
+// Работает до отмены контекста, после нее записывает оставшиеся в очереди переходы,
+// дождаться этого можно через WaitWorkers.
+func (a *App) StartClickWriter(ctx context.Context) {
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		a.writeClicks(ctx)
+	}()
+}
+
+// writeClicks записывает переходы пачками: по заполнении пачки или раз в clickFlushInterval.
+func (a *App) writeClicks(ctx context.Context) {
+	opCtx := context.WithoutCancel(ctx)
+	ticker := time.NewTicker(clickFlushInterval)
+	defer ticker.Stop()
+	batch := make([]jsonobject.ClickEvent, 0, clickFlushSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := a.storage.AddClicks(opCtx, batch); err != nil {
+			logging.Log.Errorw("writeClicks", "error", err, "count", len(batch))
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case event := <-a.clicks:
+			if batch = append(batch, event); len(batch) >= clickFlushSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			for {
+				select {
+				case event := <-a.clicks:
+					if batch = append(batch, event); len(batch) >= clickFlushSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// hashIP возвращает HMAC-SHA256 IP клиента с солью App.
+func (a *App) hashIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	h := hmac.New(sha256.New, a.clickSalt)
+	h.Write([]byte(ip))
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// LinkStats выдает статистику переходов по сокращению пользователя userID.
+// Браузеры и операционные системы определяются по User-Agent переходов, см. parseUserAgent.
+func (a *App) LinkStats(ctx context.Context, userID, short string) (jsonobject.LinkStats, error) {
+	stats, err := a.storage.GetClickStats(ctx, userID, short)
+	if err != nil {
+		return jsonobject.LinkStats{}, fmt.Errorf("linkStats: %w", err)
+	}
+	referrers := make(map[string]int64, len(stats.Referrers))
+	for _, r := range stats.Referrers {
+		if r.Name == "" {
+			r.Name = directReferrer
+		}
+		referrers[r.Name] += r.Clicks
+	}
+	browsers := make(map[string]int64)
+	systems := make(map[string]int64)
+	for _, ua := range stats.UserAgents {
+		browser, system := parseUserAgent(ua.Name)
+		browsers[browser] += ua.Clicks
+		systems[system] += ua.Clicks
+	}
+	if len(stats.Variants) > 0 {
+		variants := make(map[string]int64, len(stats.Variants))
+		for _, v := range stats.Variants {
+			variants[v.Name] += v.Clicks
+		}
+		stats.Variants = topCounts(variants, len(variants))
+	}
+	stats.Referrers = topCounts(referrers, statsTopReferrers)
+	stats.Browsers = topCounts(browsers, len(browsers))
+	stats.OS = topCounts(systems, len(systems))
+	stats.UserAgents = nil
+	if stats.Daily == nil {
+		stats.Daily = []jsonobject.DailyClicks{}
+	}
+	return stats, nil
+}
+
+// topCounts возвращает не более n самых частых значений, при равенстве - в алфавитном порядке.
+func topCounts(counts map[string]int64, n int) []jsonobject.ClickCount {
+	res := make([]jsonobject.ClickCount, 0, len(counts))
+	for name, clicks := range counts {
+		res = append(res, jsonobject.ClickCount{Name: name, Clicks: clicks})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Clicks != res[j].Clicks {
+			return res[i].Clicks > res[j].Clicks
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res[:min(n, len(res))]
+}
+
+// truncate обрезает строку до n байт и удаляет из нее некорректные UTF-8 последовательности,
+// в том числе разрезанный последний символ.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		s = s[:n]
+	}
+	return strings.ToValidUTF8(s, "")
+}
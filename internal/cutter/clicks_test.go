@@ -0,0 +1,141 @@
+package cutter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/mocks"
+)
+
+func TestClickWriter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+
+	var written []jsonobject.ClickEvent
+	m.EXPECT().AddClicks(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, events []jsonobject.ClickEvent) error {
+			written = append(written, events...)
+			return nil
+		}).MinTimes(1)
+
+	app.RecordClick("a", "", "https://ya.ru/", "curl/8.0", "10.0.0.1")
+	app.RecordClick("a", "", "", strings.Repeat("x", maxClickFieldLength+10), "10.0.0.1")
+	app.RecordClick("b", "", "", "", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	app.StartClickWriter(ctx)
+	cancel()
+	app.WaitWorkers()
+
+	require.Len(t, written, 3, "queued clicks are written on stop")
+	assert.Equal(t, "a", written[0].Short)
+	assert.Equal(t, "https://ya.ru/", written[0].Referrer)
+	assert.NotEmpty(t, written[0].IPHash)
+	assert.NotContains(t, written[0].IPHash, "10.0.0.1", "raw ip is not stored")
+	assert.Equal(t, written[0].IPHash, written[1].IPHash, "same ip - same visitor")
+	assert.Len(t, written[1].UserAgent, maxClickFieldLength)
+	assert.Empty(t, written[2].IPHash)
+	assert.False(t, written[0].At.IsZero())
+}
+
+func TestClickSalt(t *testing.T) {
+	a, b := newApp(EmptyStore{}), newApp(EmptyStore{})
+	assert.NotEqual(t, a.hashIP("10.0.0.1"), b.hashIP("10.0.0.1"), "random salt per run")
+
+	salt, err := newClickSalt("salt")
+	require.NoError(t, err)
+	a.clickSalt, b.clickSalt = salt, salt
+	assert.Equal(t, a.hashIP("10.0.0.1"), b.hashIP("10.0.0.1"), "configured salt is stable")
+}
+
+func TestLinkStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+
+	const (
+		chromeWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+		edgeWindows   = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0"
+		safariIPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+	)
+	referrers := []jsonobject.ClickCount{{Name: "", Clicks: 5}}
+	for i := 0; i < statsTopReferrers+2; i++ {
+		referrers = append(referrers, jsonobject.ClickCount{Name: string(rune('a' + i)), Clicks: 1})
+	}
+	m.EXPECT().GetClickStats(gomock.Any(), "user", "short").Return(jsonobject.LinkStats{
+		Total:     17,
+		Unique:    3,
+		Referrers: referrers,
+		UserAgents: []jsonobject.ClickCount{
+			{Name: chromeWindows, Clicks: 10},
+			{Name: edgeWindows, Clicks: 4},
+			{Name: safariIPhone, Clicks: 3},
+		},
+	}, nil).Times(1)
+	m.EXPECT().GetClickStats(gomock.Any(), "user", "foreign").Return(jsonobject.LinkStats{}, ErrorNotOwner).Times(1)
+
+	stats, err := app.LinkStats(context.Background(), "user", "short")
+	require.NoError(t, err)
+	assert.Equal(t, int64(17), stats.Total)
+	assert.Equal(t, int64(3), stats.Unique)
+	require.Len(t, stats.Referrers, statsTopReferrers)
+	assert.Equal(t, jsonobject.ClickCount{Name: directReferrer, Clicks: 5}, stats.Referrers[0])
+	assert.Equal(t, "a", stats.Referrers[1].Name, "equal counts in alphabetical order")
+	assert.Equal(t, []jsonobject.ClickCount{{Name: "Chrome", Clicks: 10}, {Name: "Edge", Clicks: 4}, {Name: "Safari", Clicks: 3}}, stats.Browsers)
+	assert.Equal(t, []jsonobject.ClickCount{{Name: "Windows", Clicks: 14}, {Name: "iOS", Clicks: 3}}, stats.OS)
+	assert.Nil(t, stats.UserAgents)
+	assert.NotNil(t, stats.Daily)
+
+	_, err = app.LinkStats(context.Background(), "user", "foreign")
+	assert.ErrorIs(t, err, ErrorNotOwner)
+}
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		ua      string
+		browser string
+		os      string
+	}{
+		{
+			ua:      "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			browser: "Firefox", os: "Linux",
+		},
+		{
+			ua:      "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			browser: "Chrome", os: "Android",
+		},
+		{
+			ua:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_2) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+			browser: "Safari", os: "macOS",
+		},
+		{
+			ua:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 YaBrowser/24.1.0.0 Safari/537.36",
+			browser: "Yandex Browser", os: "Windows",
+		},
+		{
+			ua:      "Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 OPR/106.0.0.0",
+			browser: "Opera", os: "ChromeOS",
+		},
+		{
+			ua:      "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			browser: "Bot", os: "Other",
+		},
+		{ua: "curl/8.4.0", browser: "curl", os: "Other"},
+		{ua: "", browser: "Other", os: "Other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.browser+" "+tt.os, func(t *testing.T) {
+			browser, os := parseUserAgent(tt.ua)
+			assert.Equal(t, tt.browser, browser)
+			assert.Equal(t, tt.os, os)
+		})
+	}
+}
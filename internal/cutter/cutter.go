@@ -3,11 +3,15 @@ package cutter
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"math/rand"
 	_ "net/http/pprof"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -18,48 +22,238 @@ import (
 
 const batchSize = 100
 
+// maxTTL максимальный срок действия сокращения в секундах (100 лет):
+// больший ttl переполнил бы time.Duration.
+const maxTTL = 100 * 365 * 24 * 60 * 60
+
 var errorRandStringParamN = errors.New("randStringBytes: param n must be more then 0")
 
+// Ошибки пользовательских сокращений (alias).
+var (
+	ErrorShortURLTaken = errors.New("short url is already taken")                           // сокращение уже занято другим URL
+	ErrorInvalidAlias  = errors.New("alias must contain only letters, digits, '-' and '_'") // недопустимые символы или длина
+	ErrorReservedAlias = errors.New("alias is reserved")                                    // совпадает с путем сервиса
+)
+
+// Ошибки срока действия сокращения.
+var (
+	ErrorExpiredURL    = errors.New("url has expired")                                   // срок действия сокращения истек
+	ErrorInvalidExpiry = errors.New("expires_at must be in the future and ttl positive") // некорректный срок действия
+)
+
+// ErrorDeletedURL сокращение удалено пользователем.
+var ErrorDeletedURL = errors.New("url was deleted")
+
+// Ошибки ограничения количества переходов.
+var (
+	ErrorClicksExhausted  = errors.New("url click limit is exhausted")    // переходы по сокращению закончились
+	ErrorInvalidMaxClicks = errors.New("max_clicks must not be negative") // некорректное ограничение
+)
+
+// Ошибки изменения сокращений пользователя.
+var (
+	ErrorURLNotFound = errors.New("short url not found")                    // сокращения нет в хранилище
+	ErrorNotOwner    = errors.New("short url belongs to another user")      // сокращение создано другим пользователем
+	ErrorNoHistory   = errors.New("short url has no previous destinations") // откатывать некуда
+)
+
+// aliasPattern допустимый формат пользовательского сокращения.
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// reservedAliases пути, занятые роутами serverapi.
+// Такие сокращения перекрыли бы эндпоинты сервиса.
+var reservedAliases = map[string]struct{}{
+	"ping":  {},
+	"api":   {},
+	"debug": {},
+}
+
 // Store интерфейс слоя хранилища.
 type Store interface {
 	GetShortURL(ctx context.Context, key string) (string, error)
-	Add(ctx context.Context, original, short string) error
-	GetOriginalURL(ctx context.Context, value string) (res string, err error)
+	Add(ctx context.Context, original, short string, opts jsonobject.LinkOptions) error
+	GetOriginalURL(ctx context.Context, value string) (jsonobject.Item, error)
+	UseClick(ctx context.Context, short string) error
 	Ping(context.Context) error
 	CloseDB() error
 	UploadBatch(ctx context.Context, batch jsonobject.Batch) (jsonobject.Batch, error)
 	GetUserURLs(ctx context.Context) (jsonobject.Batch, error)
-	DeleteURLs(ctx context.Context, userID string, ids []string) error
+	DeleteURLs(ctx context.Context, items []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error)
+	NextID(ctx context.Context) (int64, error)
+	MarkExpired(ctx context.Context) (int64, error)
+	UpdateURL(ctx context.Context, userID, short, original string) error
+	GetURLHistory(ctx context.Context, userID, short string) (jsonobject.History, error)
+	RollbackURL(ctx context.Context, userID, short string) (string, error)
+	GetDeletedURLs(ctx context.Context, userID string) (jsonobject.Batch, error)
+	RestoreURLs(ctx context.Context, userID string, ids []string) (jsonobject.ShortIds, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	CreateDeleteJob(ctx context.Context, job jsonobject.DeleteJob) error
+	UpdateDeleteJob(ctx context.Context, job jsonobject.DeleteJob) error
+	GetDeleteJob(ctx context.Context, id string) (jsonobject.DeleteJob, error)
+	ClaimDeleteJob(ctx context.Context) (jsonobject.DeleteJob, error)
+	RequeueDeleteJobs(ctx context.Context) (int64, error)
+	AddClicks(ctx context.Context, events []jsonobject.ClickEvent) error
+	GetClickStats(ctx context.Context, userID, short string) (jsonobject.LinkStats, error)
+	CountClicks(ctx context.Context, short string) (int64, error)
+	GetRules(ctx context.Context, userID, short string) (jsonobject.Rules, error)
+	UpdateRules(ctx context.Context, userID, short string, change func(jsonobject.Rules) (jsonobject.Rules, error)) (jsonobject.Rules, error)
+}
+
+type configer interface {
+	GetShortGenerator() string
+	GetShortLength() int
+	GetShortAlphabet() string
+	GetShortSalt() string
+	GetNormalizeSortQuery() bool
+	GetStripQueryParams() []string
+	GetShortAddress() string
+	GetShortDomains() []string
+	GetAllowedSchemes() []string
+	GetBlocklistFile() string
+	GetMaxURLLength() int
+	GetAllowPrivateURLs() bool
+	GetClickSalt() string
+	GetRedirectType() string
 }
 
 // App структура с бизнес-логикой.
 type App struct {
-	storage Store
+	storage    Store
+	generator  Generator
+	limiter    *attemptLimiter
+	normalizer *Normalizer
+	policy     *Policy
+	// wake сигнал обработчикам очереди удаления о новой задаче
+	wake chan struct{}
+	// clicks очередь переходов на запись, см. RecordClick
+	clicks    chan jsonobject.ClickEvent
+	clickSalt []byte
+	// redirectType способ перехода для сокращений без собственного способа
+	redirectType string
+	// defaultHost хост домена сокращений по умолчанию
+	defaultHost string
+	// domains хосты дополнительных доменов сокращений, см. LinkKey
+	domains map[string]struct{}
+	// randIntn случайное число из [0, n) для выбора варианта URL, см. pickVariant
+	randIntn func(n int) int
+	// now часы App, см. SetClock
+	now     func() time.Time
+	purge   purgeStats
+	workers sync.WaitGroup
 }
 
-// New Создает App
-func New(s Store) *App {
-	return &App{storage: s}
+// New Создает App.
+// Генератор сокращений выбирается по конфигурации, см. NewGenerator.
+// Политика допустимых URL строится по конфигурации, см. newPolicy.
+func New(s Store, c configer) (*App, error) {
+	g, err := NewGenerator(c.GetShortGenerator(), s, c.GetShortAlphabet(), c.GetShortLength(), c.GetShortSalt())
+	if err != nil {
+		return nil, fmt.Errorf("cutter.New: %w", err)
+	}
+	n := NewNormalizer(c.GetNormalizeSortQuery(), c.GetStripQueryParams())
+	p, err := newPolicy(c, n)
+	if err != nil {
+		return nil, fmt.Errorf("cutter.New: %w", err)
+	}
+	salt, err := newClickSalt(c.GetClickSalt())
+	if err != nil {
+		return nil, fmt.Errorf("cutter.New: %w", err)
+	}
+	if err = checkRedirectType(c.GetRedirectType()); err != nil {
+		return nil, fmt.Errorf("cutter.New: default %w", err)
+	}
+	domains, err := newDomains(c)
+	if err != nil {
+		return nil, fmt.Errorf("cutter.New: %w", err)
+	}
+	return &App{
+		storage:      s,
+		generator:    g,
+		limiter:      newAttemptLimiter(maxPasswordAttempts, passwordAttemptsWindow),
+		normalizer:   n,
+		policy:       p,
+		wake:         make(chan struct{}, 1),
+		clicks:       make(chan jsonobject.ClickEvent, clickBufferSize),
+		clickSalt:    salt,
+		redirectType: c.GetRedirectType(),
+		defaultHost:  DomainHost(c.GetShortAddress()),
+		domains:      domains,
+		randIntn:     rand.Intn,
+		now:          time.Now,
+	}, nil
 }
 
-// Cut создает и записывает в хранилище сокращение для переданного URL.
-// Количество символов сокращений - 8.
-//
-//	short, err = randStringBytes(8)
-//
-// Для проверки на уникальность URL, вызывается метод storage.Add и анализируется его ошибка.
-// Для хранилища - файла анализируется ошибка UniqueURLError.
-// Для хранилища - БД анализируется  ошибка *pgconn.PgError и ее код.
-func (a *App) Cut(ctx context.Context, url string) (short string, err error) {
-	short, err = randStringBytes(8)
+// newPolicy создает Policy по конфигурации.
+// Хостами сервиса для проверки петель считаются хосты нормализованных адресов всех доменов сокращений.
+func newPolicy(c configer, n *Normalizer) (*Policy, error) {
+	var selfHosts []string
+	for _, address := range append([]string{c.GetShortAddress()}, c.GetShortDomains()...) {
+		if address == "" {
+			continue
+		}
+		short, err := n.Normalize(address)
+		if err != nil {
+			return nil, fmt.Errorf("short address: %w", err)
+		}
+		u, err := url.Parse(short)
+		if err != nil {
+			return nil, fmt.Errorf("short address: %w", err)
+		}
+		selfHosts = append(selfHosts, u.Host)
+	}
+	p := NewPolicy(c.GetAllowedSchemes(), c.GetMaxURLLength(), c.GetAllowPrivateURLs(), selfHosts)
+	if c.GetBlocklistFile() != "" {
+		if err := p.LoadBlocklistFile(c.GetBlocklistFile()); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// prepareURL нормализует URL и проверяет его политикой App.
+func (a *App) prepareURL(raw string) (string, error) {
+	normalized, err := a.normalizer.Normalize(raw)
 	if err != nil {
-		return "", fmt.Errorf("cut: while generating path: %w", err)
+		return "", &PolicyError{URL: raw, Err: fmt.Errorf("%w: %v", ErrorInvalidURL, err)}
+	}
+	if err = a.policy.Check(normalized); err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+// Cut создает сокращение для URL в нормализованном виде; сокращением служит opts.Alias, иначе его создает генератор App.
+// Возвращает ключ сокращения в хранилище с учетом домена opts.Domain, см. LinkKey.
+// Недопустимый политикой URL возвращает *PolicyError, уже сокращенный - UniqueURLError с прежним сокращением,
+// занятый Alias - ErrorShortURLTaken.
+func (a *App) Cut(ctx context.Context, url string, opts jsonobject.LinkOptions) (short string, err error) {
+	if url, err = a.prepareURL(url); err != nil {
+		return "", fmt.Errorf("cut: %w", err)
+	}
+	if err = resolveOptions(&opts, a.now()); err != nil {
+		return "", fmt.Errorf("cut: %w", err)
+	}
+	if err = a.prepareVariants(&opts); err != nil {
+		return "", fmt.Errorf("cut: %w", err)
+	}
+	if opts.Domain, err = a.linkDomain(opts.Domain); err != nil {
+		return "", fmt.Errorf("cut: %w", err)
+	}
+	if opts.Alias != "" {
+		if err = checkAlias(opts.Alias); err != nil {
+			return "", fmt.Errorf("cut: %w", err)
+		}
+		short = LinkKey(opts.Domain, opts.Alias)
+		err = a.storage.Add(ctx, url, short, opts)
+	} else {
+		short, err = a.addGenerated(ctx, url, opts)
 	}
-	err = a.storage.Add(ctx, url, short)
 	if err != nil {
 		var uniq UniqueURLError
 		var pgErr *pgconn.PgError
 		switch {
+		case errors.Is(err, ErrorShortURLTaken):
+			return "", fmt.Errorf("cut: alias %q: %w", short, err)
 		case errors.Is(err, &uniq):
 			return "", err
 		case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation:
@@ -75,13 +269,76 @@ func (a *App) Cut(ctx context.Context, url string) (short string, err error) {
 	return
 }
 
-// GetKeyByValue выдает по переданному сокращению оригинальный URL.
+// addGenerated записывает URL со сгенерированным сокращением.
+// Пока сокращение оказывается занятым, генерирует новое.
+func (a *App) addGenerated(ctx context.Context, url string, opts jsonobject.LinkOptions) (short string, err error) {
+	for i := 0; i < maxGenerateAttempts; i++ {
+		short, err = a.generate(ctx)
+		if err != nil {
+			return "", fmt.Errorf("while generating path: %w", err)
+		}
+		short = LinkKey(opts.Domain, short)
+		err = a.storage.Add(ctx, url, short, opts)
+		if !errors.Is(err, ErrorShortURLTaken) {
+			return short, err
+		}
+		logging.Log.Debugw("short url collision", "short", short, "attempt", i+1)
+	}
+	return "", fmt.Errorf("%d attempts: %w", maxGenerateAttempts, errorAttemptsExceeded)
+}
+
+// generate запрашивает у генератора сокращение, не совпадающее с путями сервиса.
+func (a *App) generate(ctx context.Context) (string, error) {
+	for i := 0; i < maxGenerateAttempts; i++ {
+		short, err := a.generator.Generate(ctx)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := reservedAliases[strings.ToLower(short)]; !ok {
+			return short, nil
+		}
+	}
+	return "", fmt.Errorf("%d attempts: %w", maxGenerateAttempts, errorAttemptsExceeded)
+}
+
+// GetKeyByValue выдает по переданному сокращению URL перехода, см. Redirect.
 func (a *App) GetKeyByValue(ctx context.Context, value string) (res string, err error) {
-	res, err = a.storage.GetOriginalURL(ctx, value)
+	t, err := a.Redirect(ctx, jsonobject.Visit{Short: value})
+	return t.URL, err
+}
+
+// Redirect выдает переход по сокращению v.Short: URL и способ перехода, см. follow.
+// Путь и параметры запроса перехода передаются в URL по настройкам сокращения, см. passthrough.
+// Для сокращений с ограничением переходов атомарно списывает один переход,
+// когда переходы закончились - возвращает ErrorClicksExhausted.
+// Для сокращений с паролем возвращает ErrorPasswordRequired, переход выполняется через Unlock.
+// До начала работы сокращения возвращает *NotActiveError, см. checkActive.
+func (a *App) Redirect(ctx context.Context, v jsonobject.Visit) (jsonobject.Target, error) {
+	item, err := a.storage.GetOriginalURL(ctx, v.Short)
 	if err != nil {
-		return "", fmt.Errorf("getKeyByValue: while getting value by key:%s: %w", value, err)
+		return jsonobject.Target{}, fmt.Errorf("redirect: while getting value by key:%s: %w", v.Short, err)
 	}
-	return
+	if err = a.checkActive(item); err != nil {
+		return jsonobject.Target{}, fmt.Errorf("redirect: %s: %w", v.Short, err)
+	}
+	if item.Protected() {
+		return jsonobject.Target{}, fmt.Errorf("redirect: %s: %w", v.Short, ErrorPasswordRequired)
+	}
+	res, err := a.follow(ctx, item, v)
+	if err != nil {
+		return jsonobject.Target{}, fmt.Errorf("redirect: %s: %w", v.Short, err)
+	}
+	return res, nil
+}
+
+// CheckLink проверяет, что сокращение short существует и работает: не удалено, не истекло
+// и переходы по нему не закончились. Переход при этом не списывается.
+// Запланированное сокращение, см. checkActive, считается работающим: его QR-код готовят до запуска.
+func (a *App) CheckLink(ctx context.Context, short string) error {
+	if _, err := a.storage.GetOriginalURL(ctx, short); err != nil {
+		return fmt.Errorf("checkLink: %s: %w", short, err)
+	}
+	return nil
 }
 
 // PingDB прокси метод для проверки доступности БД.
@@ -90,19 +347,67 @@ func (a *App) PingDB(ctx context.Context) error {
 }
 
 // UploadBatch обрабатывает список URL: присваивает каждому сокращение и отправляет на запись.
+// Элементы с заполненным Alias получают его в качестве сокращения.
+// Если занятым оказалось сгенерированное сокращение, пакет отправляется повторно с новыми сокращениями,
+// но не более maxGenerateAttempts раз. Занятый Alias возвращает ErrorShortURLTaken сразу.
+// URL записываются в нормализованном виде, см. Normalizer. URL, недопустимый политикой App, возвращает *PolicyError.
 func (a *App) UploadBatch(ctx context.Context, batch jsonobject.Batch) (jsonobject.Batch, error) {
+	aliases := make(map[string]struct{})
 	for i := 0; i < len(batch); i++ {
-		short, err := randStringBytes(8)
+		normalized, err := a.prepareURL(batch[i].OriginalURL)
 		if err != nil {
 			return batch, fmt.Errorf("uploadBatch: %w", err)
 		}
-		(batch)[i].ShortURL = short
+		batch[i].OriginalURL = normalized
+		if err := resolveOptions(&batch[i].LinkOptions, a.now()); err != nil {
+			return batch, fmt.Errorf("uploadBatch: %s: %w", batch[i].OriginalURL, err)
+		}
+		if err := a.prepareVariants(&batch[i].LinkOptions); err != nil {
+			return batch, fmt.Errorf("uploadBatch: %s: %w", batch[i].OriginalURL, err)
+		}
+		domain, err := a.linkDomain(batch[i].Domain)
+		if err != nil {
+			return batch, fmt.Errorf("uploadBatch: %s: %w", batch[i].OriginalURL, err)
+		}
+		batch[i].Domain = domain
+		if batch[i].Alias != "" {
+			if err := checkAlias(batch[i].Alias); err != nil {
+				return batch, fmt.Errorf("uploadBatch: %w", err)
+			}
+			aliases[LinkKey(domain, batch[i].Alias)] = struct{}{}
+		}
 	}
-	batch, err := a.storage.UploadBatch(ctx, batch)
-	if err != nil {
-		return batch, fmt.Errorf("UploadBacth: %w", err)
+	for attempt := 1; ; attempt++ {
+		// хранилище изменяет переданный пакет, поэтому каждая попытка работает с копией
+		attemptBatch := make(jsonobject.Batch, len(batch))
+		copy(attemptBatch, batch)
+		for i := 0; i < len(attemptBatch); i++ {
+			if attemptBatch[i].Alias != "" {
+				attemptBatch[i].ShortURL = LinkKey(attemptBatch[i].Domain, attemptBatch[i].Alias)
+				continue
+			}
+			short, err := a.generate(ctx)
+			if err != nil {
+				return batch, fmt.Errorf("uploadBatch: %w", err)
+			}
+			attemptBatch[i].ShortURL = LinkKey(attemptBatch[i].Domain, short)
+		}
+		res, err := a.storage.UploadBatch(ctx, attemptBatch)
+		var taken *ShortURLTakenError
+		if !errors.As(err, &taken) {
+			if err != nil {
+				return res, fmt.Errorf("UploadBacth: %w", err)
+			}
+			return res, nil
+		}
+		if _, isAlias := aliases[taken.Short]; isAlias {
+			return res, fmt.Errorf("UploadBacth: %w", err)
+		}
+		if attempt >= maxGenerateAttempts {
+			return res, fmt.Errorf("UploadBacth: %d attempts: %w", attempt, errorAttemptsExceeded)
+		}
+		logging.Log.Debugw("short url collision in batch", "short", taken.Short, "attempt", attempt)
 	}
-	return batch, nil
 }
 
 // GetUserURLs получение  всех сокращенных  URL по ID пользователя.
@@ -112,39 +417,93 @@ func (a *App) GetUserURLs(ctx context.Context) (jsonobject.Batch, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cutter: %w", err)
 	}
+	now := a.now()
+	for i := range res {
+		res[i].Scheduled = res[i].ActiveFrom != nil && now.Before(*res[i].ActiveFrom)
+	}
 	return res, nil
 }
 
-// DeleteUrls разделяет переданные URL на слайс по 100 и удаляет.
-// Метод работает в отдельной горутине.
-// Каждый слайс передается в отдельную горутину через канал, где вызывается процедура удаления.
-func (a *App) DeleteUrls(userID string, ids jsonobject.ShortIds) {
-	ctx, cancel := context.WithCancel(context.Background())
-	bs := batchSize
-	lenIds := len(ids)
-	if lenIds < batchSize {
-		bs = lenIds
-	}
-	batchCh := make(chan []string)
-	go func(ctx context.Context, bCh chan []string) {
-		for b := range bCh {
-			err := a.storage.DeleteURLs(ctx, userID, b)
-			if err != nil {
-				logging.Log.Error(fmt.Errorf("DeleteURLs: %w", err))
-				cancel()
-			}
-		}
-	}(ctx, batchCh)
+// Retarget заменяет оригинальный URL сокращения пользователя userID.
+// Прежний URL сохраняется в истории сокращения, см. History и Rollback.
+// Новый URL нормализуется и проверяется политикой App, как при создании сокращения.
+// Если URL уже сокращен, хранилище возвращает *UniqueURLError.
+// Возвращает сохраненный (нормализованный) URL.
+func (a *App) Retarget(ctx context.Context, userID, short, url string) (string, error) {
+	url, err := a.prepareURL(url)
+	if err != nil {
+		return "", fmt.Errorf("retarget: %w", err)
+	}
+	if err = a.storage.UpdateURL(ctx, userID, short, url); err != nil {
+		return "", fmt.Errorf("retarget: %w", err)
+	}
+	return url, nil
+}
+
+// History выдает прежние оригинальные URL сокращения пользователя userID, начиная с самого позднего.
+func (a *App) History(ctx context.Context, userID, short string) (jsonobject.History, error) {
+	h, err := a.storage.GetURLHistory(ctx, userID, short)
+	if err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	for i, j := 0, len(h)-1; i < j; i, j = i+1, j-1 {
+		h[i], h[j] = h[j], h[i]
+	}
+	return h, nil
+}
 
-	for i := 0; i < lenIds; i = i + min(bs, lenIds-i) {
-		j := i + min(bs, lenIds-i)
+// Rollback возвращает сокращению пользователя userID предыдущий оригинальный URL.
+// Восстановленный URL удаляется из истории, поэтому повторный Rollback откатывает на шаг дальше.
+// Если история пуста - возвращает ErrorNoHistory.
+func (a *App) Rollback(ctx context.Context, userID, short string) (string, error) {
+	url, err := a.storage.RollbackURL(ctx, userID, short)
+	if err != nil {
+		return "", fmt.Errorf("rollback: %w", err)
+	}
+	return url, nil
+}
+
+// Trash выдает удаленные сокращения пользователя userID с моментом удаления.
+func (a *App) Trash(ctx context.Context, userID string) (jsonobject.Batch, error) {
+	res, err := a.storage.GetDeletedURLs(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("trash: %w", err)
+	}
+	return res, nil
+}
+
+// Restore восстанавливает удаленные сокращения пользователя userID.
+// Чужие, не удаленные и несуществующие сокращения пропускаются.
+// Возвращает восстановленные сокращения.
+func (a *App) Restore(ctx context.Context, userID string, ids jsonobject.ShortIds) (jsonobject.ShortIds, error) {
+	res, err := a.storage.RestoreURLs(ctx, userID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	return res, nil
+}
+
+// SweepExpired периодически отмечает в хранилище сокращения с истекшим сроком действия,
+// чтобы они не попадали в GetUserURLs.
+// Работает до отмены контекста, поэтому запускается в отдельной горутине.
+func (a *App) SweepExpired(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
 		select {
 		case <-ctx.Done():
 			return
-		case batchCh <- ids[i:j]:
+		case <-ticker.C:
+			n, err := a.storage.MarkExpired(ctx)
+			if err != nil {
+				logging.Log.Errorw("SweepExpired", "error", err)
+				continue
+			}
+			if n > 0 {
+				logging.Log.Infow("SweepExpired: marked expired urls", "count", n)
+			}
 		}
 	}
-	close(batchCh)
 }
 
 // UniqueURLError ошибка уникальности URL.
@@ -172,15 +531,69 @@ func (ue *UniqueURLError) Unwrap() error {
 	return ue.Err
 }
 
-// randStringBytes генерирует рандомную строку длины n.
-func randStringBytes(n int) (string, error) {
-	if n <= 0 {
-		return "", errorRandStringParamN
+// ShortURLTakenError ошибка занятого сокращения.
+// Хранилища возвращают ее, чтобы App мог отличить занятый Alias от коллизии сгенерированного сокращения.
+// Для errors.Is эквивалентна ErrorShortURLTaken.
+type ShortURLTakenError struct {
+	Short string
+}
+
+// NewShortURLTakenError создает новую ошибку.
+func NewShortURLTakenError(short string) error {
+	return &ShortURLTakenError{Short: short}
+}
+
+// Error реализует интерфейс error для ShortURLTakenError.
+func (te *ShortURLTakenError) Error() string {
+	return fmt.Sprintf("%s: %s", te.Short, ErrorShortURLTaken)
+}
+
+// Unwrap реализует интерфейс error для ShortURLTakenError.
+func (te *ShortURLTakenError) Unwrap() error {
+	return ErrorShortURLTaken
+}
+
+// resolveOptions проверяет срок действия и ограничение переходов сокращения, переводит TTL в ExpiresAt.
+// Одновременно можно указать только один из параметров. TTL отсчитывается от now.
+// Пароль заменяется его хэшем, см. hashPassword.
+func resolveOptions(opts *jsonobject.LinkOptions, now time.Time) error {
+	if opts.MaxClicks < 0 {
+		return fmt.Errorf("max_clicks %d: %w", opts.MaxClicks, ErrorInvalidMaxClicks)
 	}
-	b := make([]byte, n)
-	_, err := rand.Read(b)
-	if err != nil {
-		return "", fmt.Errorf("randStringBytes: Generating random string: %w", err)
+	if err := checkPassQuery(opts.PassQuery); err != nil {
+		return err
+	}
+	if err := checkRedirectType(opts.RedirectType); err != nil {
+		return err
+	}
+	if err := checkTitle(opts.Title); err != nil {
+		return err
+	}
+	opts.PasswordHash = ""
+	if err := hashPassword(opts); err != nil {
+		return err
+	}
+	switch {
+	case opts.TTL < 0 || opts.TTL > maxTTL:
+		return fmt.Errorf("ttl %d: %w", opts.TTL, ErrorInvalidExpiry)
+	case opts.TTL > 0 && opts.ExpiresAt != nil:
+		return fmt.Errorf("only one of expires_at and ttl is allowed: %w", ErrorInvalidExpiry)
+	case opts.TTL > 0:
+		exp := now.Add(time.Duration(opts.TTL) * time.Second)
+		opts.ExpiresAt = &exp
+	case opts.ExpiresAt != nil && !opts.ExpiresAt.After(now):
+		return fmt.Errorf("expires_at %s: %w", opts.ExpiresAt.Format(time.RFC3339), ErrorInvalidExpiry)
+	}
+	return checkActiveFrom(opts)
+}
+
+// checkAlias проверяет, что пользовательское сокращение допустимо.
+func checkAlias(alias string) error {
+	if !aliasPattern.MatchString(alias) {
+		return fmt.Errorf("alias %q: %w", alias, ErrorInvalidAlias)
+	}
+	if _, ok := reservedAliases[strings.ToLower(alias)]; ok {
+		return fmt.Errorf("alias %q: %w", alias, ErrorReservedAlias)
 	}
-	return base64.URLEncoding.EncodeToString(b), nil
+	return nil
 }
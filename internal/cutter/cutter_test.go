@@ -10,8 +10,10 @@ import (
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 
+	"github.com/dmad1989/urlcut/internal/config"
 	"github.com/dmad1989/urlcut/internal/jsonobject"
 	"github.com/dmad1989/urlcut/internal/logging"
 	"github.com/dmad1989/urlcut/internal/mocks"
@@ -107,10 +109,10 @@ func TestCut(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m.EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.mockParams.addErrReturn).MaxTimes(1)
+			m.EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.mockParams.addErrReturn).MaxTimes(1)
 			m.EXPECT().GetShortURL(gomock.Any(), gomock.Any()).Return(tt.mockParams.getURLReturn, tt.mockParams.getErrReturn).MaxTimes(tt.mockParams.getTimes)
-			app := New(m)
-			res, err := app.Cut(context.TODO(), "someurl")
+			app := newApp(m)
+			res, err := app.Cut(context.TODO(), "http://someurl.ru", jsonobject.LinkOptions{})
 			assert.Equal(t, tt.expected.isEmptyRes, res == "")
 			if tt.expected.isNoError {
 				assert.Empty(t, err)
@@ -121,12 +123,273 @@ func TestCut(t *testing.T) {
 	}
 }
 
-func TestCheckUrls(t *testing.T) {
-	defer goleak.VerifyNone(t)
+func TestCutAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		addErrReturn  error
+		errorExpected error
+		name          string
+		alias         string
+		addTimes      int
+	}{{
+		name:     "positive",
+		alias:    "spring-sale",
+		addTimes: 1,
+	}, {
+		name:          "negative - invalid symbols",
+		alias:         "spring/sale",
+		errorExpected: ErrorInvalidAlias,
+	}, {
+		name:          "negative - reserved",
+		alias:         "Ping",
+		errorExpected: ErrorReservedAlias,
+	}, {
+		name:          "negative - taken",
+		alias:         "spring-sale",
+		addTimes:      1,
+		addErrReturn:  ErrorShortURLTaken,
+		errorExpected: ErrorShortURLTaken,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mocks.NewMockStore(ctrl)
+			m.EXPECT().Add(gomock.Any(), "http://someurl.ru", tt.alias, gomock.Any()).Return(tt.addErrReturn).Times(tt.addTimes)
+			app := newApp(m)
+			res, err := app.Cut(context.TODO(), "http://someurl.ru", jsonobject.LinkOptions{Alias: tt.alias})
+			if tt.errorExpected != nil {
+				assert.ErrorIs(t, err, tt.errorExpected)
+				assert.Empty(t, res)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.alias, res)
+		})
+	}
+}
+
+func TestCutRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		errorExpected error
+		name          string
+		takenTimes    int
+		addTimes      int
+	}{{
+		name:       "positive - free after collisions",
+		takenTimes: 2,
+		addTimes:   3,
+	}, {
+		name:          "negative - attempts exceeded",
+		takenTimes:    maxGenerateAttempts,
+		addTimes:      maxGenerateAttempts,
+		errorExpected: errorAttemptsExceeded,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mocks.NewMockStore(ctrl)
+			gomock.InOrder(
+				m.EXPECT().Add(gomock.Any(), "http://someurl.ru", gomock.Any(), gomock.Any()).Return(NewShortURLTakenError("taken")).Times(tt.takenTimes),
+				m.EXPECT().Add(gomock.Any(), "http://someurl.ru", gomock.Any(), gomock.Any()).Return(nil).Times(tt.addTimes-tt.takenTimes),
+			)
+			app := newApp(m)
+			res, err := app.Cut(context.TODO(), "http://someurl.ru", jsonobject.LinkOptions{})
+			if tt.errorExpected != nil {
+				assert.ErrorIs(t, err, tt.errorExpected)
+				assert.NotErrorIs(t, err, ErrorShortURLTaken)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, res, defaultShortLength)
+		})
+	}
+}
+
+func TestUploadBatchRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		errorExpected error
+		name          string
+		takenShort    string
+		uploadTimes   int
+	}{{
+		name:        "positive - generated short collision",
+		takenShort:  "generated",
+		uploadTimes: 2,
+	}, {
+		name:          "negative - alias taken",
+		takenShort:    "spring-sale",
+		uploadTimes:   1,
+		errorExpected: ErrorShortURLTaken,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mocks.NewMockStore(ctrl)
+			gomock.InOrder(
+				m.EXPECT().UploadBatch(gomock.Any(), gomock.Any()).Return(nil, NewShortURLTakenError(tt.takenShort)),
+				m.EXPECT().UploadBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, b jsonobject.Batch) (jsonobject.Batch, error) {
+						return b, nil
+					}).Times(tt.uploadTimes-1),
+			)
+			batch := jsonobject.Batch{
+				{ID: "1", OriginalURL: "http://ya.ru", LinkOptions: jsonobject.LinkOptions{Alias: "spring-sale"}},
+				{ID: "2", OriginalURL: "http://mail.ru"},
+			}
+			app := newApp(m)
+			res, err := app.UploadBatch(context.TODO(), batch)
+			if tt.errorExpected != nil {
+				assert.ErrorIs(t, err, tt.errorExpected)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "spring-sale", res[0].ShortURL)
+			assert.Len(t, res[1].ShortURL, defaultShortLength)
+		})
+	}
+}
+
+func TestResolveOptions(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		opts          jsonobject.LinkOptions
+		err           error
+		name          string
+		isError       bool
+		expectExpires bool
+	}{{
+		name: "positive - no expiration",
+	}, {
+		name:          "positive - ttl",
+		opts:          jsonobject.LinkOptions{TTL: 60},
+		expectExpires: true,
+	}, {
+		name:          "positive - expires_at",
+		opts:          jsonobject.LinkOptions{ExpiresAt: &future},
+		expectExpires: true,
+	}, {
+		name:    "negative - expires_at in past",
+		opts:    jsonobject.LinkOptions{ExpiresAt: &past},
+		isError: true,
+	}, {
+		name:    "negative - negative ttl",
+		opts:    jsonobject.LinkOptions{TTL: -1},
+		isError: true,
+		err:     ErrorInvalidExpiry,
+	}, {
+		name:    "negative - ttl overflows duration",
+		opts:    jsonobject.LinkOptions{TTL: 10000000000000},
+		isError: true,
+		err:     ErrorInvalidExpiry,
+	}, {
+		name:          "positive - max ttl",
+		opts:          jsonobject.LinkOptions{TTL: maxTTL},
+		expectExpires: true,
+	}, {
+		name:    "negative - both ttl and expires_at",
+		opts:    jsonobject.LinkOptions{TTL: 60, ExpiresAt: &future},
+		isError: true,
+		err:     ErrorInvalidExpiry,
+	}, {
+		name:    "negative - negative max_clicks",
+		opts:    jsonobject.LinkOptions{MaxClicks: -1},
+		isError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolveOptions(&tt.opts, time.Now())
+			if tt.isError {
+				assert.Error(t, err)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectExpires, tt.opts.ExpiresAt != nil)
+		})
+	}
+}
+
+func TestGetKeyByValueClicks(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+	clicks := 1
+	tests := []struct {
+		useClickErr error
+		expectedErr error
+		item        jsonobject.Item
+		name        string
+		useTimes    int
+	}{{
+		name:     "positive - unlimited link",
+		item:     jsonobject.Item{OriginalURL: "http://ya.ru"},
+		useTimes: 0,
+	}, {
+		name:     "positive - limited link",
+		item:     jsonobject.Item{OriginalURL: "http://ya.ru", ClicksLeft: &clicks},
+		useTimes: 1,
+	}, {
+		name:        "negative - clicks exhausted concurrently",
+		item:        jsonobject.Item{OriginalURL: "http://ya.ru", ClicksLeft: &clicks},
+		useClickErr: ErrorClicksExhausted,
+		expectedErr: ErrorClicksExhausted,
+		useTimes:    1,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.EXPECT().GetOriginalURL(gomock.Any(), "short").Return(tt.item, nil).Times(1)
+			m.EXPECT().UseClick(gomock.Any(), "short").Return(tt.useClickErr).Times(tt.useTimes)
+			res, err := app.GetKeyByValue(context.Background(), "short")
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.item.OriginalURL, res)
+		})
+	}
+}
 
+func TestRetargetHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+
+	m.EXPECT().UpdateURL(gomock.Any(), "user", "short", "http://mail.ru").Return(nil).Times(1)
+	res, err := app.Retarget(context.Background(), "user", "short", "HTTP://Mail.ru/")
+	require.NoError(t, err)
+	assert.Equal(t, "http://mail.ru", res, "url must be normalized")
+
+	_, err = app.Retarget(context.Background(), "user", "short", "file:///etc/passwd")
+	assert.ErrorIs(t, err, ErrorSchemeNotAllowed)
+
+	m.EXPECT().GetURLHistory(gomock.Any(), "user", "short").Return(jsonobject.History{
+		{OriginalURL: "http://first.ru"}, {OriginalURL: "http://second.ru"},
+	}, nil).Times(1)
+	h, err := app.History(context.Background(), "user", "short")
+	require.NoError(t, err)
+	require.Len(t, h, 2)
+	assert.Equal(t, "http://second.ru", h[0].OriginalURL, "latest first")
+
+	m.EXPECT().RollbackURL(gomock.Any(), "user", "short").Return("", ErrorNoHistory).Times(1)
+	_, err = app.Rollback(context.Background(), "user", "short")
+	assert.ErrorIs(t, err, ErrorNoHistory)
+}
+
+func TestCheckUrls(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
 	tests := []struct {
 		dbError  error
@@ -135,37 +398,37 @@ func TestCheckUrls(t *testing.T) {
 		maxTimes int
 	}{{
 		name:     "10els",
-		inputSl:  make([]string, 10),
+		inputSl:  testCodes(10),
 		maxTimes: 1,
 	},
 		{
 			name:     "100els",
-			inputSl:  make([]string, 100),
+			inputSl:  testCodes(100),
 			maxTimes: 1,
 		},
 		{
 			name:     "1000els",
-			inputSl:  make([]string, 1000),
+			inputSl:  testCodes(1000),
 			maxTimes: 10,
 		},
 		{
 			name:     "1001els",
-			inputSl:  make([]string, 1001),
+			inputSl:  testCodes(1001),
 			maxTimes: 11,
 		},
 		{
 			name:     "999els",
-			inputSl:  make([]string, 999),
+			inputSl:  testCodes(999),
 			maxTimes: 10,
 		},
 		{
 			name:     "165els",
-			inputSl:  make([]string, 165),
+			inputSl:  testCodes(165),
 			maxTimes: 2,
 		},
 		{
 			name:     "100els_error",
-			inputSl:  make([]string, 100),
+			inputSl:  testCodes(100),
 			maxTimes: 1,
 			dbError:  errors.New("custom db error"),
 		},
@@ -173,14 +436,30 @@ func TestCheckUrls(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m.EXPECT().DeleteURLs(gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.dbError).MaxTimes(tt.maxTimes)
-			app := New(m)
-			go app.DeleteUrls("", tt.inputSl)
+			m := mocks.NewMockStore(ctrl)
+			m.EXPECT().DeleteURLs(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, items []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error) {
+					return deletedStatuses(items), tt.dbError
+				}).Times(tt.maxTimes)
+			var last jsonobject.DeleteJob
+			m.EXPECT().UpdateDeleteJob(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, job jsonobject.DeleteJob) error {
+					last = job
+					return nil
+				}).AnyTimes()
+			app := newApp(m)
+			agg := startAggregator(m)
+			defer agg.stop()
+			app.runDeleteJob(context.Background(), agg, jsonobject.DeleteJob{ID: "job", UserID: "user", Codes: tt.inputSl})
+			if tt.dbError != nil {
+				assert.Equal(t, JobFailed, last.State)
+				assert.Contains(t, last.Error, tt.dbError.Error())
+				return
+			}
+			assert.Equal(t, JobDone, last.State)
+			assert.Equal(t, int64(len(tt.inputSl)), last.Deleted)
 		})
-
 	}
-	time.Sleep(time.Second * 30)
-	defer goleak.VerifyNone(t)
 }
 
 func TestRandStringBytes(t *testing.T) {
@@ -209,7 +488,7 @@ func TestRandStringBytes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s, err := randStringBytes(tt.n)
+			s, err := randStringBytes(tt.n, defaultAlphabet)
 
 			if tt.isErrorRes {
 				assert.NotEmpty(t, err)
@@ -247,7 +526,7 @@ func TestUploadBatch(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m.EXPECT().UploadBatch(gomock.Any(), gomock.Any()).Return(tt.batch, tt.storeUploadError).MaxTimes(1)
-			app := New(m)
+			app := newApp(m)
 			res, err := app.UploadBatch(context.TODO(), tt.batch)
 
 			if tt.storeUploadError != nil {
@@ -264,17 +543,17 @@ func TestUploadBatch(t *testing.T) {
 func prepareBatch(size int) jsonobject.Batch {
 	batch := make(jsonobject.Batch, 0, size)
 	for i := 0; i < size; i++ {
-		str, err := randStringBytes(8)
+		str, err := randStringBytes(8, defaultAlphabet)
 		if err != nil {
 			panic("randStringBytes out of control")
 		}
-		batch = append(batch, jsonobject.BatchItem{ID: str, OriginalURL: str})
+		batch = append(batch, jsonobject.BatchItem{ID: str, OriginalURL: "http://" + str + ".ru"})
 	}
 	return batch
 }
 func BenchmarkUploadBatch(b *testing.B) {
 	m := EmptyStore{}
-	a := New(m)
+	a := newApp(m)
 	batch := prepareBatch(200)
 	// b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -287,27 +566,29 @@ func BenchmarkUploadBatch(b *testing.B) {
 
 func BenchmarkDeleteUrls(b *testing.B) {
 	m := EmptyStore{}
-	a := New(m)
+	a := newApp(m)
 	ids := make(jsonobject.ShortIds, 0, 200)
 	for i := 0; i < 200; i++ {
-		str, err := randStringBytes(8)
+		str, err := randStringBytes(8, defaultAlphabet)
 		if err != nil {
 			panic("randStringBytes out of control")
 		}
 		ids = append(ids, str)
 	}
+	agg := startAggregator(m)
+	defer agg.stop()
 	for i := 0; i < b.N; i++ {
-		a.DeleteUrls("customID", ids)
+		a.runDeleteJob(context.Background(), agg, jsonobject.DeleteJob{ID: "job", UserID: "customID", Codes: ids})
 	}
 }
 
 func BenchmarkCut(b *testing.B) {
 	b.StopTimer()
 	m := EmptyStore{}
-	a := New(m)
+	a := newApp(m)
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := a.Cut(context.TODO(), "someurl")
+		_, err := a.Cut(context.TODO(), "http://someurl.ru", jsonobject.LinkOptions{})
 		if err != nil {
 			logging.Log.Infof("benchmarkCut: cut^ %w", err)
 		}
@@ -319,11 +600,14 @@ type EmptyStore struct{}
 func (s EmptyStore) GetShortURL(ctx context.Context, key string) (string, error) {
 	return "", nil
 }
-func (s EmptyStore) Add(ctx context.Context, original, short string) error {
+func (s EmptyStore) Add(ctx context.Context, original, short string, opts jsonobject.LinkOptions) error {
 	return nil
 }
-func (s EmptyStore) GetOriginalURL(ctx context.Context, value string) (res string, err error) {
-	return "", nil
+func (s EmptyStore) GetOriginalURL(ctx context.Context, value string) (jsonobject.Item, error) {
+	return jsonobject.Item{}, nil
+}
+func (s EmptyStore) UseClick(ctx context.Context, short string) error {
+	return nil
 }
 func (s EmptyStore) Ping(context.Context) error {
 	return nil
@@ -337,6 +621,68 @@ func (s EmptyStore) UploadBatch(ctx context.Context, batch jsonobject.Batch) (js
 func (s EmptyStore) GetUserURLs(ctx context.Context) (jsonobject.Batch, error) {
 	return jsonobject.Batch{}, nil
 }
-func (s EmptyStore) DeleteURLs(ctx context.Context, userID string, ids []string) error {
+func (s EmptyStore) DeleteURLs(ctx context.Context, items []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error) {
+	return deletedStatuses(items), nil
+}
+func (s EmptyStore) NextID(ctx context.Context) (int64, error) {
+	return 1, nil
+}
+func (s EmptyStore) MarkExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+func (s EmptyStore) UpdateURL(ctx context.Context, userID, short, original string) error {
 	return nil
 }
+func (s EmptyStore) GetURLHistory(ctx context.Context, userID, short string) (jsonobject.History, error) {
+	return jsonobject.History{}, nil
+}
+func (s EmptyStore) RollbackURL(ctx context.Context, userID, short string) (string, error) {
+	return "", nil
+}
+
+func newApp(s Store) *App {
+	a, err := New(s, config.Config{})
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+func (s EmptyStore) GetDeletedURLs(ctx context.Context, userID string) (jsonobject.Batch, error) {
+	return jsonobject.Batch{}, nil
+}
+func (s EmptyStore) RestoreURLs(ctx context.Context, userID string, ids []string) (jsonobject.ShortIds, error) {
+	return jsonobject.ShortIds{}, nil
+}
+func (s EmptyStore) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+func (s EmptyStore) CreateDeleteJob(ctx context.Context, job jsonobject.DeleteJob) error {
+	return nil
+}
+func (s EmptyStore) UpdateDeleteJob(ctx context.Context, job jsonobject.DeleteJob) error {
+	return nil
+}
+func (s EmptyStore) GetDeleteJob(ctx context.Context, id string) (jsonobject.DeleteJob, error) {
+	return jsonobject.DeleteJob{}, nil
+}
+func (s EmptyStore) ClaimDeleteJob(ctx context.Context) (jsonobject.DeleteJob, error) {
+	return jsonobject.DeleteJob{}, ErrorNoPendingJobs
+}
+func (s EmptyStore) RequeueDeleteJobs(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+func (s EmptyStore) AddClicks(ctx context.Context, events []jsonobject.ClickEvent) error {
+	return nil
+}
+func (s EmptyStore) GetClickStats(ctx context.Context, userID, short string) (jsonobject.LinkStats, error) {
+	return jsonobject.LinkStats{}, nil
+}
+func (s EmptyStore) CountClicks(ctx context.Context, short string) (int64, error) {
+	return 0, nil
+}
+func (s EmptyStore) GetRules(ctx context.Context, userID, short string) (jsonobject.Rules, error) {
+	return nil, nil
+}
+func (s EmptyStore) UpdateRules(ctx context.Context, userID, short string, change func(jsonobject.Rules) (jsonobject.Rules, error)) (jsonobject.Rules, error) {
+	return change(nil)
+}
@@ -0,0 +1,174 @@
+package cutter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/logging"
+)
+
+// Состояния задачи удаления сокращений.
+const (
+	JobPending = "pending" // задача в очереди: удаление не начато или прервано остановкой сервиса
+	JobRunning = "running" // задача выполняется обработчиком очереди
+	JobDone    = "done"    // все сокращения обработаны
+	JobFailed  = "failed"  // удаление прервано ошибкой хранилища
+)
+
+// deleteJobsPoll период, с которым обработчики проверяют очередь без сигнала о новой задаче.
+const deleteJobsPoll = 5 * time.Second
+
+// Ошибки очереди задач удаления.
+var (
+	ErrorJobNotFound   = errors.New("delete job not found")   // задачи нет в хранилище или она создана другим пользователем
+	ErrorNoPendingJobs = errors.New("no pending delete jobs") // очередь пуста
+)
+
+// DeleteUrls ставит в очередь задачу удаления сокращений пользователя userID.
+// Задача сохраняется в хранилище и выполняется обработчиком очереди, см. StartDeleteWorkers.
+// Состояние задачи доступно через GetDeleteJob.
+// Возвращает созданную задачу.
+func (a *App) DeleteUrls(ctx context.Context, userID string, ids jsonobject.ShortIds) (jsonobject.DeleteJob, error) {
+	now := a.now()
+	job := jsonobject.DeleteJob{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		State:     JobPending,
+		Codes:     ids,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := a.storage.CreateDeleteJob(ctx, job); err != nil {
+		return jsonobject.DeleteJob{}, fmt.Errorf("DeleteUrls: %w", err)
+	}
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// StartDeleteWorkers возвращает в очередь задачи, прерванные предыдущим запуском сервиса,
+// и запускает n обработчиков очереди. Сокращения всех обработчиков удаляются общими пачками, см. deleteAggregator.
+// Обработчики работают до отмены контекста, дождаться их завершения можно через WaitWorkers.
+func (a *App) StartDeleteWorkers(ctx context.Context, n int) error {
+	requeued, err := a.storage.RequeueDeleteJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("StartDeleteWorkers: %w", err)
+	}
+	if requeued > 0 {
+		logging.Log.Infow("StartDeleteWorkers: resumed interrupted delete jobs", "count", requeued)
+	}
+	agg := newDeleteAggregator(a.storage, n)
+	var pool sync.WaitGroup
+	pool.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer pool.Done()
+			a.deleteWorker(ctx, agg)
+		}()
+	}
+	go func() {
+		pool.Wait()
+		agg.stop()
+	}()
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		agg.run(context.WithoutCancel(ctx))
+	}()
+	return nil
+}
+
+// WaitWorkers ждет, пока фоновые обработчики после отмены контекста сохранят свое состояние:
+// обработчики очереди удаления - состояние задач, запись переходов - накопленные переходы.
+func (a *App) WaitWorkers() {
+	a.workers.Wait()
+}
+
+// deleteWorker выполняет задачи из очереди, пока она не опустеет, затем ждет новую задачу.
+func (a *App) deleteWorker(ctx context.Context, agg *deleteAggregator) {
+	ticker := time.NewTicker(deleteJobsPoll)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil {
+			job, err := a.storage.ClaimDeleteJob(ctx)
+			if errors.Is(err, ErrorNoPendingJobs) {
+				break
+			}
+			if err != nil {
+				logging.Log.Errorw("deleteWorker: claim job", "error", err)
+				break
+			}
+			a.runDeleteJob(ctx, agg, job)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetDeleteJob выдает задачу удаления id пользователя userID.
+// Задачи других пользователей не выдаются: для них возвращается ErrorJobNotFound.
+func (a *App) GetDeleteJob(ctx context.Context, userID, id string) (jsonobject.DeleteJob, error) {
+	job, err := a.storage.GetDeleteJob(ctx, id)
+	if err != nil {
+		return jsonobject.DeleteJob{}, fmt.Errorf("GetDeleteJob: %w", err)
+	}
+	if job.UserID != userID {
+		return jsonobject.DeleteJob{}, fmt.Errorf("GetDeleteJob: %w", ErrorJobNotFound)
+	}
+	return job, nil
+}
+
+// runDeleteJob удаляет сокращения задачи пачками по batchSize и сохраняет ее состояние после каждой пачки.
+// Пачки передаются в agg и удаляются вместе с пачками других задач.
+// Ошибка хранилища прерывает задачу, она переходит в состояние JobFailed.
+// При отмене контекста задача возвращается в очередь с сохраненным прогрессом и продолжается при следующем запуске.
+func (a *App) runDeleteJob(ctx context.Context, agg *deleteAggregator, job jsonobject.DeleteJob) {
+	// начатая пачка и сохранение состояния не прерываются остановкой сервиса
+	opCtx := context.WithoutCancel(ctx)
+	for job.Processed < len(job.Codes) {
+		if ctx.Err() != nil {
+			job.State = JobPending
+			a.saveDeleteJob(opCtx, &job)
+			return
+		}
+		end := min(job.Processed+batchSize, len(job.Codes))
+		res, err := agg.delete(job.UserID, job.Codes[job.Processed:end])
+		job.Deleted += res.Deleted
+		job.NotFound = append(job.NotFound, res.NotFound...)
+		job.NotOwned = append(job.NotOwned, res.NotOwned...)
+		if err != nil {
+			logging.Log.Error(fmt.Errorf("DeleteURLs: %w", err))
+			job.State = JobFailed
+			job.Error = err.Error()
+			a.saveDeleteJob(opCtx, &job)
+			return
+		}
+		job.Processed = end
+		if job.Processed < len(job.Codes) {
+			a.saveDeleteJob(opCtx, &job)
+		}
+	}
+	job.State = JobDone
+	a.saveDeleteJob(opCtx, &job)
+}
+
+// saveDeleteJob сохраняет состояние задачи в хранилище.
+// Ошибка сохранения только логируется: удаление продолжается.
+func (a *App) saveDeleteJob(ctx context.Context, job *jsonobject.DeleteJob) {
+	job.UpdatedAt = a.now()
+	if err := a.storage.UpdateDeleteJob(ctx, *job); err != nil {
+		logging.Log.Errorw("saveDeleteJob", "job", job.ID, "error", err)
+	}
+}
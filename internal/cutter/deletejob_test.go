@@ -0,0 +1,122 @@
+package cutter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/mocks"
+)
+
+// jobRecorder запоминает последнее сохраненное состояние задачи удаления.
+type jobRecorder struct {
+	job jsonobject.DeleteJob
+	mu  sync.Mutex
+}
+
+func (r *jobRecorder) save(_ context.Context, job jsonobject.DeleteJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.job = job
+	return nil
+}
+
+func (r *jobRecorder) last() jsonobject.DeleteJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.job
+}
+
+func TestDeleteJob(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+
+	rec := &jobRecorder{}
+	var claimed jsonobject.DeleteJob
+	m.EXPECT().CreateDeleteJob(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, job jsonobject.DeleteJob) error {
+			claimed = job
+			claimed.State = JobRunning
+			return rec.save(ctx, job)
+		}).Times(1)
+	m.EXPECT().UpdateDeleteJob(gomock.Any(), gomock.Any()).DoAndReturn(rec.save).AnyTimes()
+	m.EXPECT().DeleteURLs(gomock.Any(), []jsonobject.DeleteItem{{UserID: "user", Short: "a"}, {UserID: "user", Short: "b"}, {UserID: "user", Short: "c"}}).
+		Return([]jsonobject.DeleteStatus{jsonobject.DeleteDone, jsonobject.DeleteNotFound, jsonobject.DeleteNotOwned}, nil).Times(1)
+
+	job, err := app.DeleteUrls(context.Background(), "user", jsonobject.ShortIds{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, JobPending, job.State)
+
+	gomock.InOrder(
+		m.EXPECT().ClaimDeleteJob(gomock.Any()).DoAndReturn(
+			func(context.Context) (jsonobject.DeleteJob, error) { return claimed, nil }).Times(1),
+		m.EXPECT().ClaimDeleteJob(gomock.Any()).Return(jsonobject.DeleteJob{}, ErrorNoPendingJobs).AnyTimes(),
+	)
+	m.EXPECT().RequeueDeleteJobs(gomock.Any()).Return(int64(0), nil).Times(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, app.StartDeleteWorkers(ctx, 2))
+	require.Eventually(t, func() bool { return rec.last().State == JobDone }, time.Second, 10*time.Millisecond)
+	cancel()
+	app.WaitWorkers()
+
+	done := rec.last()
+	assert.Equal(t, job.ID, done.ID)
+	assert.Equal(t, 3, done.Processed)
+	assert.Equal(t, int64(1), done.Deleted)
+	assert.Equal(t, jsonobject.ShortIds{"b"}, done.NotFound)
+	assert.Equal(t, jsonobject.ShortIds{"c"}, done.NotOwned)
+
+	m.EXPECT().GetDeleteJob(gomock.Any(), job.ID).Return(done, nil).Times(2)
+	res, err := app.GetDeleteJob(context.Background(), "user", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, JobDone, res.State)
+	_, err = app.GetDeleteJob(context.Background(), "other", job.ID)
+	assert.ErrorIs(t, err, ErrorJobNotFound, "jobs of other users are hidden")
+}
+
+func TestDeleteJobCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := &jobRecorder{}
+	m.EXPECT().UpdateDeleteJob(gomock.Any(), gomock.Any()).DoAndReturn(rec.save).AnyTimes()
+	m.EXPECT().DeleteURLs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, items []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error) {
+			// сервис останавливается во время удаления первой пачки
+			cancel()
+			return deletedStatuses(items), nil
+		}).Times(1)
+
+	agg := startAggregator(m)
+	defer agg.stop()
+	codes := jsonobject.ShortIds(testCodes(batchSize + 10))
+	app.runDeleteJob(ctx, agg, jsonobject.DeleteJob{ID: "job", UserID: "user", State: JobRunning, Codes: codes})
+	job := rec.last()
+	assert.Equal(t, JobPending, job.State, "interrupted job must return to the queue")
+	assert.Equal(t, batchSize, job.Processed, "started batch must be finished and checkpointed")
+
+	m.EXPECT().DeleteURLs(gomock.Any(), gomock.Len(10)).DoAndReturn(
+		func(_ context.Context, items []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error) {
+			assert.Equal(t, codes[batchSize], items[0].Short)
+			return deletedStatuses(items), nil
+		}).Times(1)
+	job.State = JobRunning
+	app.runDeleteJob(context.Background(), agg, job)
+	job = rec.last()
+	assert.Equal(t, JobDone, job.State)
+	assert.Equal(t, int64(batchSize+10), job.Deleted, "resumed job continues from checkpoint")
+}
@@ -0,0 +1,66 @@
+package cutter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrorUnknownDomain домен сокращения не входит в домены сокращений сервиса.
+var ErrorUnknownDomain = errors.New("unknown short domain")
+
+// LinkKey возвращает ключ сокращения code домена domain в хранилище.
+// Сокращения домена по умолчанию хранятся под своим кодом, сокращения дополнительных доменов - как "домен/код",
+// поэтому один код может существовать на разных доменах.
+func LinkKey(domain, code string) string {
+	if domain == "" {
+		return code
+	}
+	return domain + "/" + code
+}
+
+// SplitLinkKey разбирает ключ сокращения на домен и код, см. LinkKey.
+// Для сокращений домена по умолчанию домен пустой.
+func SplitLinkKey(key string) (domain, code string) {
+	if domain, code, isFound := strings.Cut(key, "/"); isFound {
+		return domain, code
+	}
+	return "", key
+}
+
+// DomainHost возвращает хост базового адреса сокращений base в нижнем регистре.
+func DomainHost(base string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// newDomains возвращает хосты дополнительных доменов сокращений, см. LinkKey.
+func newDomains(c configer) (map[string]struct{}, error) {
+	res := make(map[string]struct{}, len(c.GetShortDomains()))
+	for _, base := range c.GetShortDomains() {
+		host := DomainHost(base)
+		if host == "" {
+			return nil, fmt.Errorf("short domain %q: host is empty", base)
+		}
+		res[host] = struct{}{}
+	}
+	delete(res, DomainHost(c.GetShortAddress()))
+	return res, nil
+}
+
+// linkDomain проверяет домен создаваемого сокращения: пусто и хост домена по умолчанию - домен по умолчанию,
+// дополнительный домен возвращается в нижнем регистре, иначе - ErrorUnknownDomain.
+func (a *App) linkDomain(domain string) (string, error) {
+	domain = strings.ToLower(domain)
+	if domain == "" || domain == a.defaultHost {
+		return "", nil
+	}
+	if _, isFound := a.domains[domain]; !isFound {
+		return "", fmt.Errorf("domain %q: %w", domain, ErrorUnknownDomain)
+	}
+	return domain, nil
+}
@@ -0,0 +1,60 @@
+package cutter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/config"
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+func TestLinkKey(t *testing.T) {
+	assert.Equal(t, "abc", LinkKey("", "abc"))
+	assert.Equal(t, "go.brand.com/abc", LinkKey("go.brand.com", "abc"))
+	domain, code := SplitLinkKey("go.brand.com/abc")
+	assert.Equal(t, "go.brand.com", domain)
+	assert.Equal(t, "abc", code)
+	domain, code = SplitLinkKey("abc")
+	assert.Empty(t, domain)
+	assert.Equal(t, "abc", code)
+	assert.Equal(t, "brand.link:8443", DomainHost("https://Brand.link:8443/"))
+}
+
+func TestCutDomain(t *testing.T) {
+	app, err := New(EmptyStore{}, config.Config{
+		ShortAddress: "http://localhost:8080",
+		ShortDomains: "https://go.brand.com, https://Brand.link/",
+	})
+	require.NoError(t, err)
+	tests := []struct {
+		name   string
+		domain string
+		want   string
+		err    error
+	}{
+		{name: "default domain", want: "sale"},
+		{name: "default domain by host", domain: "localhost:8080", want: "sale"},
+		{name: "additional domain", domain: "go.brand.com", want: "go.brand.com/sale"},
+		{name: "domain ignores case", domain: "BRAND.link", want: "brand.link/sale"},
+		{name: "unknown domain", domain: "evil.com", err: ErrorUnknownDomain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := app.Cut(context.Background(), "https://ya.ru", jsonobject.LinkOptions{Alias: "sale", Domain: tt.domain})
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+
+	_, err = app.Cut(context.Background(), "https://go.brand.com/other", jsonobject.LinkOptions{})
+	assert.ErrorIs(t, err, ErrorSelfLoop, "all short domains are checked for loops")
+	_, err = New(EmptyStore{}, config.Config{ShortDomains: "go.brand.com"})
+	assert.Error(t, err, "short domain without scheme")
+}
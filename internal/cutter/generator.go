@@ -0,0 +1,217 @@
+package cutter
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Типы генераторов сокращений.
+const (
+	GeneratorRandom   = "random"   // случайная строка
+	GeneratorSequence = "sequence" // base62 значения последовательности хранилища
+	GeneratorHashid   = "hashid"   // значение последовательности, закодированное в стиле hashids
+)
+
+// Параметры генерации по умолчанию.
+const (
+	defaultAlphabet    = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	defaultShortLength = 8
+	// maxGenerateAttempts ограничивает число попыток подобрать свободное сокращение.
+	maxGenerateAttempts = 10
+)
+
+var (
+	errorAttemptsExceeded = errors.New("no free short url found")
+	errorInvalidAlphabet  = errors.New("alphabet must contain at least 2 unique symbols from [A-Za-z0-9_-]")
+)
+
+// Generator генерирует сокращения для URL.
+// Уникальность результата не гарантируется: при коллизии App запрашивает новое сокращение.
+type Generator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// Sequencer выдает следующее значение монотонной последовательности хранилища.
+type Sequencer interface {
+	NextID(ctx context.Context) (int64, error)
+}
+
+// NewGenerator создает встроенный генератор по его типу.
+// Пустой тип соответствует GeneratorRandom.
+// Длина для GeneratorRandom - точная, для остальных генераторов - минимальная.
+func NewGenerator(kind string, seq Sequencer, alphabet string, length int, salt string) (Generator, error) {
+	if alphabet == "" {
+		alphabet = defaultAlphabet
+	}
+	if err := checkAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+	if length <= 0 {
+		length = defaultShortLength
+	}
+	switch kind {
+	case "", GeneratorRandom:
+		return NewRandomGenerator(alphabet, length), nil
+	case GeneratorSequence:
+		return NewSequenceGenerator(seq, alphabet, length), nil
+	case GeneratorHashid:
+		return NewHashidGenerator(seq, alphabet, length, salt), nil
+	default:
+		return nil, fmt.Errorf("unknown generator %q", kind)
+	}
+}
+
+// RandomGenerator генерирует случайные сокращения фиксированной длины.
+type RandomGenerator struct {
+	alphabet string
+	length   int
+}
+
+// NewRandomGenerator создает RandomGenerator.
+func NewRandomGenerator(alphabet string, length int) *RandomGenerator {
+	return &RandomGenerator{alphabet: alphabet, length: length}
+}
+
+// Generate реализует интерфейс Generator.
+func (g *RandomGenerator) Generate(_ context.Context) (string, error) {
+	return randStringBytes(g.length, g.alphabet)
+}
+
+// SequenceGenerator кодирует очередное значение последовательности хранилища в base62 (или в заданный алфавит).
+type SequenceGenerator struct {
+	seq       Sequencer
+	alphabet  string
+	minLength int
+}
+
+// NewSequenceGenerator создает SequenceGenerator.
+func NewSequenceGenerator(seq Sequencer, alphabet string, minLength int) *SequenceGenerator {
+	return &SequenceGenerator{seq: seq, alphabet: alphabet, minLength: minLength}
+}
+
+// Generate реализует интерфейс Generator.
+func (g *SequenceGenerator) Generate(ctx context.Context) (string, error) {
+	id, err := g.seq.NextID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("sequence generator: %w", err)
+	}
+	return padLeft(encodeNumber(id, g.alphabet), g.alphabet[0], g.minLength), nil
+}
+
+// HashidGenerator кодирует очередное значение последовательности так, чтобы соседние значения
+// давали непохожие сокращения (по мотивам hashids).
+// Первый символ результата (lottery) выбирает перемешивание алфавита для остальных символов,
+// поэтому разные значения всегда дают разные сокращения.
+type HashidGenerator struct {
+	seq       Sequencer
+	alphabet  string
+	salt      string
+	minLength int
+}
+
+// NewHashidGenerator создает HashidGenerator.
+// Алфавит перемешивается солью salt.
+func NewHashidGenerator(seq Sequencer, alphabet string, minLength int, salt string) *HashidGenerator {
+	return &HashidGenerator{
+		seq:       seq,
+		alphabet:  consistentShuffle(alphabet, salt),
+		salt:      salt,
+		minLength: minLength,
+	}
+}
+
+// Generate реализует интерфейс Generator.
+func (g *HashidGenerator) Generate(ctx context.Context) (string, error) {
+	id, err := g.seq.NextID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("hashid generator: %w", err)
+	}
+	return g.encode(id), nil
+}
+
+func (g *HashidGenerator) encode(id int64) string {
+	lottery := g.alphabet[id%int64(len(g.alphabet))]
+	alphabet := consistentShuffle(g.alphabet, string(lottery)+g.salt)
+	hash := padLeft(encodeNumber(id, alphabet), alphabet[0], g.minLength-1)
+	return string(lottery) + hash
+}
+
+// consistentShuffle детерминированно перемешивает алфавит по соли.
+func consistentShuffle(alphabet, salt string) string {
+	if salt == "" {
+		return alphabet
+	}
+	res := []byte(alphabet)
+	for i, v, p := len(res)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		c := int(salt[v])
+		p += c
+		j := (c + v + p) % i
+		res[i], res[j] = res[j], res[i]
+		v++
+	}
+	return string(res)
+}
+
+// encodeNumber записывает неотрицательное число в системе счисления алфавита.
+func encodeNumber(n int64, alphabet string) string {
+	base := int64(len(alphabet))
+	if n == 0 {
+		return alphabet[:1]
+	}
+	var res []byte
+	for ; n > 0; n /= base {
+		res = append(res, alphabet[n%base])
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return string(res)
+}
+
+// padLeft дополняет s символом zero (нулем алфавита) слева до длины n.
+func padLeft(s string, zero byte, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	pad := make([]byte, n-len(s))
+	for i := range pad {
+		pad[i] = zero
+	}
+	return string(pad) + s
+}
+
+// checkAlphabet проверяет, что символы алфавита уникальны и допустимы в пути URL.
+func checkAlphabet(alphabet string) error {
+	seen := make(map[rune]struct{}, len(alphabet))
+	for _, r := range alphabet {
+		if _, ok := seen[r]; ok || !aliasPattern.MatchString(string(r)) {
+			return fmt.Errorf("alphabet %q: %w", alphabet, errorInvalidAlphabet)
+		}
+		seen[r] = struct{}{}
+	}
+	if len(seen) < 2 {
+		return fmt.Errorf("alphabet %q: %w", alphabet, errorInvalidAlphabet)
+	}
+	return nil
+}
+
+// randStringBytes генерирует рандомную строку длины n из символов alphabet.
+func randStringBytes(n int, alphabet string) (string, error) {
+	if n <= 0 {
+		return "", errorRandStringParamN
+	}
+	base := big.NewInt(int64(len(alphabet)))
+	b := make([]byte, n)
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, base)
+		if err != nil {
+			return "", fmt.Errorf("randStringBytes: Generating random string: %w", err)
+		}
+		b[i] = alphabet[idx.Int64()]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,102 @@
+package cutter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type counterSequence struct {
+	last int64
+}
+
+func (c *counterSequence) NextID(ctx context.Context) (int64, error) {
+	c.last++
+	return c.last, nil
+}
+
+func TestNewGenerator(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		alphabet string
+		isError  bool
+	}{{
+		name: "positive - default",
+	}, {
+		name: "positive - sequence",
+		kind: GeneratorSequence,
+	}, {
+		name:     "positive - hashid custom alphabet",
+		kind:     GeneratorHashid,
+		alphabet: "abcdef",
+	}, {
+		name:    "negative - unknown kind",
+		kind:    "uuid",
+		isError: true,
+	}, {
+		name:     "negative - duplicate symbols",
+		alphabet: "aab",
+		isError:  true,
+	}, {
+		name:     "negative - symbol not allowed in path",
+		alphabet: "ab/",
+		isError:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGenerator(tt.kind, &counterSequence{}, tt.alphabet, 0, "salt")
+			if tt.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, g)
+		})
+	}
+}
+
+func TestRandomGenerator(t *testing.T) {
+	g := NewRandomGenerator("ab", 12)
+	for i := 0; i < 100; i++ {
+		s, err := g.Generate(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, s, 12)
+		assert.Empty(t, strings.Trim(s, "ab"))
+	}
+}
+
+func TestSequenceGenerator(t *testing.T) {
+	g := NewSequenceGenerator(&counterSequence{last: 59}, defaultAlphabet, 2)
+	expected := []string{"0Y", "0Z", "10", "11"}
+	for _, e := range expected {
+		s, err := g.Generate(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, e, s)
+	}
+}
+
+func TestHashidGenerator(t *testing.T) {
+	g := NewHashidGenerator(&counterSequence{}, defaultAlphabet, 6, "urlcut")
+	seen := make(map[string]struct{})
+	prev := ""
+	for i := 0; i < 100000; i++ {
+		s, err := g.Generate(context.Background())
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(s), 6)
+		_, dup := seen[s]
+		require.False(t, dup, "duplicate short %s", s)
+		seen[s] = struct{}{}
+		assert.NotEqual(t, prev[:min(len(prev), 5)], s[:5], "neighbour shorts must differ in prefix")
+		prev = s
+	}
+	other := NewHashidGenerator(&counterSequence{}, defaultAlphabet, 6, "other salt")
+	s1, err := NewHashidGenerator(&counterSequence{}, defaultAlphabet, 6, "urlcut").Generate(context.Background())
+	require.NoError(t, err)
+	s2, err := other.Generate(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, s1, s2, "salt must change shorts")
+}
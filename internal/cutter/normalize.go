@@ -0,0 +1,136 @@
+package cutter
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// defaultPorts порты, которые не указываются в нормализованном URL.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalizer приводит URL к каноническому виду, чтобы разные записи одного адреса
+// получали одно сокращение.
+type Normalizer struct {
+	// stripExact имена удаляемых параметров запроса
+	stripExact map[string]struct{}
+	// stripPrefix префиксы имен удаляемых параметров, задаются шаблоном вида "utm_*"
+	stripPrefix []string
+	sortQuery   bool
+}
+
+// NewNormalizer создает Normalizer.
+// strip - имена параметров запроса, которые удаляются из URL. Шаблон, оканчивающийся на "*",
+// удаляет все параметры с таким префиксом.
+// sortQuery - сортировать параметры запроса по имени.
+func NewNormalizer(sortQuery bool, strip []string) *Normalizer {
+	n := &Normalizer{
+		stripExact: make(map[string]struct{}),
+		sortQuery:  sortQuery,
+	}
+	for _, p := range strip {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "":
+		case strings.HasSuffix(p, "*"):
+			n.stripPrefix = append(n.stripPrefix, strings.TrimSuffix(p, "*"))
+		default:
+			n.stripExact[p] = struct{}{}
+		}
+	}
+	return n
+}
+
+// Normalize возвращает канонический вид URL:
+// схема и хост в нижнем регистре, без порта по умолчанию, IDN-хост в punycode,
+// путь "/" заменяется на пустой, параметры из списка удаления убираются.
+// URL без схемы или хоста возвращаются без изменений.
+func (n *Normalizer) Normalize(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("normalize %q: %w", raw, err)
+	}
+	if u.Scheme == "" || u.Host == "" || u.Opaque != "" {
+		return raw, nil
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	// Punycode выполняет только преобразование, не проверяя допустимость символов хоста
+	host, err = idna.Punycode.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("normalize host %q: %w", u.Hostname(), err)
+	}
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	if u.Path == "/" {
+		u.Path, u.RawPath = "", ""
+	}
+	u.RawQuery = n.normalizeQuery(u.RawQuery)
+	if u.RawQuery == "" {
+		u.ForceQuery = false
+	}
+	return u.String(), nil
+}
+
+// normalizeQuery удаляет параметры из списка удаления и при необходимости сортирует остальные.
+// Порядок и кодирование оставшихся параметров без сортировки не изменяются.
+func (n *Normalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	params := strings.Split(rawQuery, "&")
+	kept := params[:0]
+	for _, p := range params {
+		if p == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(p, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if n.stripped(key) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if n.sortQuery {
+		sort.SliceStable(kept, func(i, j int) bool {
+			ki, _, _ := strings.Cut(kept[i], "=")
+			kj, _, _ := strings.Cut(kept[j], "=")
+			return ki < kj
+		})
+	}
+	return strings.Join(kept, "&")
+}
+
+// stripped сообщает, что параметр запроса нужно удалить.
+func (n *Normalizer) stripped(key string) bool {
+	key = strings.ToLower(key)
+	if _, ok := n.stripExact[key]; ok {
+		return true
+	}
+	for _, p := range n.stripPrefix {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
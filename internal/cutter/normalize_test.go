@@ -0,0 +1,112 @@
+package cutter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/mocks"
+)
+
+func TestNormalize(t *testing.T) {
+	strip := []string{"utm_*", "fbclid"}
+	tests := []struct {
+		name      string
+		raw       string
+		expected  string
+		sortQuery bool
+		isError   bool
+	}{{
+		name:     "positive - scheme and host case, root path",
+		raw:      "HTTP://Example.COM/",
+		expected: "http://example.com",
+	}, {
+		name:     "positive - default http port",
+		raw:      "http://example.com:80/",
+		expected: "http://example.com",
+	}, {
+		name:     "positive - default https port",
+		raw:      "https://example.com:443/a",
+		expected: "https://example.com/a",
+	}, {
+		name:     "positive - non default port kept",
+		raw:      "http://example.com:8080/",
+		expected: "http://example.com:8080",
+	}, {
+		name:     "positive - path case kept",
+		raw:      "http://example.com/Path/To",
+		expected: "http://example.com/Path/To",
+	}, {
+		name:     "positive - idn host",
+		raw:      "http://Пример.рф/путь",
+		expected: "http://xn--e1afmkfd.xn--p1ai/%D0%BF%D1%83%D1%82%D1%8C",
+	}, {
+		name:     "positive - ipv6 host",
+		raw:      "http://[::1]:80/",
+		expected: "http://[::1]",
+	}, {
+		name:     "positive - tracking params stripped, order kept",
+		raw:      "http://example.com/?b=2&utm_source=x&a=1&UTM_medium=y&fbclid=z",
+		expected: "http://example.com?b=2&a=1",
+	}, {
+		name:     "positive - only tracking params",
+		raw:      "http://example.com/?utm_source=x",
+		expected: "http://example.com",
+	}, {
+		name:      "positive - sorted query",
+		raw:       "http://example.com/?b=2&a=1&a=0",
+		expected:  "http://example.com?a=1&a=0&b=2",
+		sortQuery: true,
+	}, {
+		name:     "positive - fragment kept",
+		raw:      "http://example.com/#top",
+		expected: "http://example.com#top",
+	}, {
+		name:     "positive - not absolute url unchanged",
+		raw:      "sss",
+		expected: "sss",
+	}, {
+		name:    "negative - invalid url",
+		raw:     "http://exa mple.com/",
+		isError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewNormalizer(tt.sortQuery, strip).Normalize(tt.raw)
+			if tt.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestCutNormalizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+
+	for _, raw := range []string{"HTTP://Example.com/", "http://example.com", "http://example.com:80/"} {
+		m.EXPECT().Add(gomock.Any(), "http://example.com", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		_, err := app.Cut(context.Background(), raw, jsonobject.LinkOptions{})
+		require.NoError(t, err)
+	}
+	m.EXPECT().Add(gomock.Any(), "http://example.com?utm_campaign=spring", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	_, err := app.Cut(context.Background(), "http://example.com:80/?utm_campaign=spring", jsonobject.LinkOptions{})
+	require.NoError(t, err, "query parameters are kept unless stripping is configured")
+
+	m.EXPECT().UploadBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, batch jsonobject.Batch) (jsonobject.Batch, error) {
+			assert.Equal(t, "http://example.com", batch[0].OriginalURL)
+			return batch, nil
+		}).Times(1)
+	_, err = app.UploadBatch(context.Background(), jsonobject.Batch{{ID: "1", OriginalURL: "HTTP://EXAMPLE.com:80"}})
+	require.NoError(t, err)
+}
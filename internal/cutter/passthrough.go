@@ -0,0 +1,110 @@
+package cutter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// Режимы передачи параметров запроса перехода в URL сокращения, см. jsonobject.LinkOptions.
+const (
+	PassQueryKeep     = "keep"     // параметр URL сокращения сохраняется, параметр перехода отбрасывается
+	PassQueryOverride = "override" // параметр перехода заменяет параметр URL сокращения
+	PassQueryAppend   = "append"   // значения перехода добавляются к значениям параметра URL сокращения
+)
+
+// Ошибки передачи пути и параметров запроса перехода.
+var (
+	ErrorInvalidPassQuery = errors.New("pass_query must be keep, override or append") // недопустимый режим передачи параметров
+	ErrorInvalidPassPath  = errors.New("path must not contain . or .. segments")      // путь мог бы выйти за путь URL сокращения
+)
+
+// checkPassQuery проверяет режим передачи параметров запроса.
+func checkPassQuery(mode string) error {
+	switch mode {
+	case "", PassQueryKeep, PassQueryOverride, PassQueryAppend:
+		return nil
+	}
+	return fmt.Errorf("pass_query %q: %w", mode, ErrorInvalidPassQuery)
+}
+
+// passthrough возвращает URL перехода: оригинальный URL сокращения с путем и параметрами перехода.
+// Путь перехода дописывается к пути URL, если у сокращения включен PassPath;
+// путь с сегментами "." и ".." отклоняется с ErrorInvalidPassPath, см. checkPassPath.
+// Параметры перехода передаются, если у сокращения задан режим PassQuery, см. mergeQuery.
+func passthrough(item jsonobject.Item, v jsonobject.Visit) (string, error) {
+	passPath := item.PassPath && v.Path != ""
+	passQuery := item.PassQuery != "" && len(v.Query) > 0
+	if !passPath && !passQuery {
+		return item.OriginalURL, nil
+	}
+	u, err := url.Parse(item.OriginalURL)
+	if err != nil {
+		return "", fmt.Errorf("parse original url: %w", err)
+	}
+	if passPath {
+		if err = checkPassPath(v.Path); err != nil {
+			return "", err
+		}
+		u = u.JoinPath(v.Path)
+	}
+	if passQuery {
+		u.RawQuery = mergeQuery(u.RawQuery, v.Query, item.PassQuery)
+	}
+	return u.String(), nil
+}
+
+// checkPassPath проверяет, что путь перехода не содержит сегментов "." и "..", в том числе закодированных:
+// JoinPath разрешает их, и путь перехода мог бы выйти за путь URL сокращения.
+func checkPassPath(path string) error {
+	for _, segment := range strings.Split(path, "/") {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("path %q: %w", path, ErrorInvalidPassPath)
+		}
+	}
+	return nil
+}
+
+// mergeQuery объединяет запрос raw URL сокращения с параметрами перехода query.
+// Параметры URL сохраняют свой порядок и кодирование, параметры перехода дописываются после них в порядке имен.
+// Параметр, который есть и в URL, и в переходе, объединяется по режиму mode:
+// keep - остается значение URL, override - остается значение перехода, append - остаются оба.
+func mergeQuery(raw string, query url.Values, mode string) string {
+	var parts []string
+	stored := make(map[string]struct{})
+	for _, pair := range strings.Split(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		stored[key] = struct{}{}
+		if _, isPassed := query[key]; isPassed && mode == PassQueryOverride {
+			continue
+		}
+		parts = append(parts, pair)
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, isStored := stored[k]; isStored && mode == PassQueryKeep {
+			continue
+		}
+		for _, v := range query[k] {
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
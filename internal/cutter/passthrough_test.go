@@ -0,0 +1,58 @@
+package cutter
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+func TestPassthrough(t *testing.T) {
+	const original = "https://ya.ru/landing?utm_source=site&b=1#top"
+	query := url.Values{"utm_source": {"mail"}, "a": {"x y"}}
+	tests := []struct {
+		name      string
+		passQuery string
+		passPath  bool
+		path      string
+		query     url.Values
+		err       error
+		want      string
+	}{
+		{name: "passthrough is off", path: "extra", query: query, want: original},
+		{name: "keep", passQuery: PassQueryKeep, query: query, want: "https://ya.ru/landing?utm_source=site&b=1&a=x+y#top"},
+		{name: "override", passQuery: PassQueryOverride, query: query, want: "https://ya.ru/landing?b=1&a=x+y&utm_source=mail#top"},
+		{name: "append", passQuery: PassQueryAppend, query: query, want: "https://ya.ru/landing?utm_source=site&b=1&a=x+y&utm_source=mail#top"},
+		{name: "no query in visit", passQuery: PassQueryAppend, want: original},
+		{name: "path", passPath: true, path: "extra/path", query: query, want: "https://ya.ru/landing/extra/path?utm_source=site&b=1#top"},
+		{name: "path can not leave url path", passPath: true, path: "abc/../../admin", err: ErrorInvalidPassPath},
+		{name: "encoded dot segment", passPath: true, path: "%2e%2e/admin", err: ErrorInvalidPassPath},
+		{name: "current dir segment", passPath: true, path: "./x", err: ErrorInvalidPassPath},
+		{name: "dots inside segment", passPath: true, path: "a..b/.well", want: "https://ya.ru/landing/a..b/.well?utm_source=site&b=1#top"},
+		{name: "path and query", passQuery: PassQueryKeep, passPath: true, path: "x", query: url.Values{"c": {"1"}},
+			want: "https://ya.ru/landing/x?utm_source=site&b=1&c=1#top"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := jsonobject.Item{OriginalURL: original, PassQuery: tt.passQuery, PassPath: tt.passPath}
+			res, err := passthrough(item, jsonobject.Visit{Short: "short", Path: tt.path, Query: tt.query})
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+
+	res, err := passthrough(jsonobject.Item{OriginalURL: "https://ya.ru", PassPath: true}, jsonobject.Visit{Path: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://ya.ru/a", res, "url without path")
+
+	opts := jsonobject.LinkOptions{PassQuery: "merge"}
+	assert.ErrorIs(t, resolveOptions(&opts, time.Now()), ErrorInvalidPassQuery)
+}
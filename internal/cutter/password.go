@@ -0,0 +1,137 @@
+package cutter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// Ограничение неверных попыток ввода пароля для одного сокращения.
+const (
+	maxPasswordAttempts    = 5
+	passwordAttemptsWindow = time.Minute
+	// limiterPruneSize размер, после которого из limiter удаляются устаревшие записи.
+	limiterPruneSize = 1024
+)
+
+// Ошибки сокращений с паролем.
+var (
+	ErrorPasswordRequired = errors.New("url is password protected")          // переход требует ввода пароля
+	ErrorWrongPassword    = errors.New("wrong password")                     // пароль не подходит
+	ErrorTooManyAttempts  = errors.New("too many wrong password attempts")   // превышено число неверных попыток
+	ErrorInvalidPassword  = errors.New("password must be at most 72 bytes")  // пароль не может быть захэширован
+	errorNotProtected     = errors.New("url is not protected with password") // у сокращения нет пароля
+)
+
+// Unlock проверяет пароль сокращения v.Short и выдает переход по нему, как Redirect.
+// Попытки считаются для каждого сокращения отдельно: попытка учитывается до проверки пароля,
+// поэтому даже параллельно в течение passwordAttemptsWindow проверяется не больше maxPasswordAttempts паролей,
+// остальные получают ErrorTooManyAttempts. Верный пароль сбрасывает счетчик.
+// Для сокращений с ограничением переходов переход списывается только после верного пароля.
+func (a *App) Unlock(ctx context.Context, v jsonobject.Visit, password string) (jsonobject.Target, error) {
+	value := v.Short
+	item, err := a.storage.GetOriginalURL(ctx, value)
+	if err != nil {
+		return jsonobject.Target{}, fmt.Errorf("unlock: while getting value by key:%s: %w", value, err)
+	}
+	if err = a.checkActive(item); err != nil {
+		return jsonobject.Target{}, fmt.Errorf("unlock: %s: %w", value, err)
+	}
+	if !item.Protected() {
+		return jsonobject.Target{}, fmt.Errorf("unlock: %s: %w", value, errorNotProtected)
+	}
+	if !a.limiter.reserve(value) {
+		return jsonobject.Target{}, fmt.Errorf("unlock: %s: %w", value, ErrorTooManyAttempts)
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(item.PasswordHash), []byte(password)); err != nil {
+		return jsonobject.Target{}, fmt.Errorf("unlock: %s: %w", value, ErrorWrongPassword)
+	}
+	a.limiter.reset(value)
+	res, err := a.follow(ctx, item, v)
+	if err != nil {
+		return jsonobject.Target{}, fmt.Errorf("unlock: %s: %w", value, err)
+	}
+	return res, nil
+}
+
+// hashPassword переносит в opts.PasswordHash bcrypt-хэш пароля.
+// Открытый пароль удаляется из opts, чтобы не попасть в ответ сервиса.
+func hashPassword(opts *jsonobject.LinkOptions) error {
+	if opts.Password == "" {
+		return nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return ErrorInvalidPassword
+	}
+	if err != nil {
+		return fmt.Errorf("hashing password: %w", err)
+	}
+	opts.PasswordHash = string(hash)
+	opts.Password = ""
+	return nil
+}
+
+// attemptLimiter считает попытки ввода пароля по сокращениям в фиксированном окне.
+type attemptLimiter struct {
+	attempts map[string]*attemptWindow
+	window   time.Duration
+	limit    int
+	mu       sync.Mutex
+}
+
+type attemptWindow struct {
+	start time.Time
+	count int
+}
+
+func newAttemptLimiter(limit int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{
+		attempts: make(map[string]*attemptWindow),
+		window:   window,
+		limit:    limit,
+	}
+}
+
+// reserve учитывает попытку проверки пароля сокращения key, если лимит попыток в текущем окне не исчерпан.
+// Проверка и учет выполняются под одной блокировкой, поэтому параллельные попытки не превышают лимит.
+func (l *attemptLimiter) reserve(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	w, ok := l.attempts[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		if len(l.attempts) >= limiterPruneSize {
+			l.prune(now)
+		}
+		l.attempts[key] = &attemptWindow{start: now, count: 1}
+		return true
+	}
+	if w.count >= l.limit {
+		return false
+	}
+	w.count++
+	return true
+}
+
+// reset сбрасывает счетчик после верного пароля.
+func (l *attemptLimiter) reset(key string) {
+	l.mu.Lock()
+	delete(l.attempts, key)
+	l.mu.Unlock()
+}
+
+// prune удаляет устаревшие окна. Вызывается под блокировкой.
+func (l *attemptLimiter) prune(now time.Time) {
+	for k, w := range l.attempts {
+		if now.Sub(w.start) >= l.window {
+			delete(l.attempts, k)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package cutter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/mocks"
+)
+
+func TestHashPassword(t *testing.T) {
+	opts := jsonobject.LinkOptions{Password: "secret"}
+	require.NoError(t, resolveOptions(&opts, time.Now()))
+	assert.Empty(t, opts.Password, "plain password must not stay in options")
+	assert.NotEmpty(t, opts.PasswordHash)
+	assert.NotContains(t, opts.PasswordHash, "secret")
+
+	opts = jsonobject.LinkOptions{PasswordHash: "client hash"}
+	require.NoError(t, resolveOptions(&opts, time.Now()))
+	assert.Empty(t, opts.PasswordHash, "hash from client must be ignored")
+
+	opts = jsonobject.LinkOptions{Password: string(make([]byte, 73))}
+	assert.ErrorIs(t, resolveOptions(&opts, time.Now()), ErrorInvalidPassword)
+}
+
+func TestUnlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+
+	opts := jsonobject.LinkOptions{Password: "secret"}
+	require.NoError(t, resolveOptions(&opts, time.Now()))
+	clicks := 1
+	protected := jsonobject.Item{OriginalURL: "http://ya.ru", PasswordHash: opts.PasswordHash, ClicksLeft: &clicks}
+	m.EXPECT().GetOriginalURL(gomock.Any(), "short").Return(protected, nil).AnyTimes()
+
+	_, err := app.GetKeyByValue(context.Background(), "short")
+	assert.ErrorIs(t, err, ErrorPasswordRequired)
+
+	_, err = app.Unlock(context.Background(), jsonobject.Visit{Short: "short"}, "wrong")
+	assert.ErrorIs(t, err, ErrorWrongPassword)
+
+	m.EXPECT().UseClick(gomock.Any(), "short").Return(nil).Times(1)
+	res, err := app.Unlock(context.Background(), jsonobject.Visit{Short: "short"}, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, protected.OriginalURL, res.URL)
+
+	for i := 0; i < maxPasswordAttempts; i++ {
+		_, err = app.Unlock(context.Background(), jsonobject.Visit{Short: "short"}, "wrong")
+		assert.ErrorIs(t, err, ErrorWrongPassword)
+	}
+	_, err = app.Unlock(context.Background(), jsonobject.Visit{Short: "short"}, "secret")
+	assert.ErrorIs(t, err, ErrorTooManyAttempts, "correct password must be rejected after too many attempts")
+}
+
+func TestUnlockConcurrentAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+	opts := jsonobject.LinkOptions{Password: "secret"}
+	require.NoError(t, resolveOptions(&opts, time.Now()))
+	m.EXPECT().GetOriginalURL(gomock.Any(), "short").
+		Return(jsonobject.Item{OriginalURL: "http://ya.ru", PasswordHash: opts.PasswordHash}, nil).AnyTimes()
+
+	const guesses = 4 * maxPasswordAttempts
+	var wg sync.WaitGroup
+	var checked, rejected atomic.Int32
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := app.Unlock(context.Background(), jsonobject.Visit{Short: "short"}, "wrong")
+			switch {
+			case errors.Is(err, ErrorWrongPassword):
+				checked.Add(1)
+			case errors.Is(err, ErrorTooManyAttempts):
+				rejected.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(maxPasswordAttempts), checked.Load(), "only limit guesses reach bcrypt")
+	assert.Equal(t, int32(guesses-maxPasswordAttempts), rejected.Load())
+}
+
+func TestAttemptLimiter(t *testing.T) {
+	l := newAttemptLimiter(2, 50*time.Millisecond)
+	assert.True(t, l.reserve("a"))
+	assert.True(t, l.reserve("a"))
+	assert.False(t, l.reserve("a"))
+	assert.True(t, l.reserve("b"), "limits are per link")
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, l.reserve("a"), "window must expire")
+
+	assert.True(t, l.reserve("a"))
+	l.reset("a")
+	assert.True(t, l.reserve("a"))
+}
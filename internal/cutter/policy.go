@@ -0,0 +1,214 @@
+package cutter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Правила политики допустимых URL.
+var (
+	ErrorInvalidURL       = errors.New("url must be absolute with host")                    // URL не разбирается или без хоста
+	ErrorSchemeNotAllowed = errors.New("url scheme is not allowed")                         // схема не из списка разрешенных
+	ErrorHostBlocked      = errors.New("url host is blocked")                               // хост или домен в списке блокировки
+	ErrorPrivateAddress   = errors.New("url points to private or loopback address")         // адрес локальной сети
+	ErrorURLTooLong       = errors.New("url is too long")                                   // превышена длина URL
+	ErrorSelfLoop         = errors.New("url points to the shortener itself, redirect loop") // URL ведет на сам сервис
+)
+
+// PolicyError ошибка URL, отклоненного политикой.
+// Для errors.Is эквивалентна ошибке нарушенного правила.
+type PolicyError struct {
+	Err error
+	URL string
+}
+
+// Error реализует интерфейс error для PolicyError.
+func (pe *PolicyError) Error() string {
+	return fmt.Sprintf("url %q rejected: %v", pe.URL, pe.Err)
+}
+
+// Unwrap реализует интерфейс error для PolicyError.
+func (pe *PolicyError) Unwrap() error {
+	return pe.Err
+}
+
+// Policy проверяет, что URL допустим для сокращения.
+// Проверяется нормализованный URL, см. Normalizer.
+type Policy struct {
+	schemes map[string]struct{}
+	// blockedHosts хосты, заблокированные точно
+	blockedHosts map[string]struct{}
+	// blockedDomains домены, заблокированные вместе с поддоменами
+	blockedDomains map[string]struct{}
+	// selfHosts хосты сервиса, ссылки на которые создадут петлю переходов
+	selfHosts    map[string]struct{}
+	maxLength    int
+	allowPrivate bool
+}
+
+// NewPolicy создает Policy без списка блокировки.
+// schemes - разрешенные схемы, maxLength - максимальная длина URL (0 - без ограничения),
+// allowPrivate - разрешить адреса локальной сети, selfHosts - хосты сервиса с портом, если он не по умолчанию.
+func NewPolicy(schemes []string, maxLength int, allowPrivate bool, selfHosts []string) *Policy {
+	p := &Policy{
+		schemes:        make(map[string]struct{}, len(schemes)),
+		blockedHosts:   make(map[string]struct{}),
+		blockedDomains: make(map[string]struct{}),
+		selfHosts:      make(map[string]struct{}, len(selfHosts)),
+		maxLength:      maxLength,
+		allowPrivate:   allowPrivate,
+	}
+	for _, s := range schemes {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			p.schemes[s] = struct{}{}
+		}
+	}
+	for _, h := range selfHosts {
+		if h != "" {
+			p.selfHosts[strings.ToLower(h)] = struct{}{}
+		}
+	}
+	return p
+}
+
+// LoadBlocklist читает список блокировки: одна запись в строке, "#" начинает комментарий.
+// Запись "example.com" блокирует только этот хост,
+// запись "*.example.com" или ".example.com" - домен вместе со всеми поддоменами.
+func (p *Policy) LoadBlocklist(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.ToLower(strings.TrimSpace(line))
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "*."):
+			p.blockedDomains[strings.TrimPrefix(line, "*.")] = struct{}{}
+		case strings.HasPrefix(line, "."):
+			p.blockedDomains[strings.TrimPrefix(line, ".")] = struct{}{}
+		default:
+			p.blockedHosts[line] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read blocklist: %w", err)
+	}
+	return nil
+}
+
+// LoadBlocklistFile читает список блокировки из файла, см. LoadBlocklist.
+func (p *Policy) LoadBlocklistFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("open blocklist: %w", err)
+	}
+	defer f.Close()
+	return p.LoadBlocklist(f)
+}
+
+// Check проверяет URL по всем правилам политики.
+// Нарушение правила возвращается как *PolicyError.
+func (p *Policy) Check(raw string) error {
+	if err := p.check(raw); err != nil {
+		return &PolicyError{URL: raw, Err: err}
+	}
+	return nil
+}
+
+func (p *Policy) check(raw string) error {
+	if p.maxLength > 0 && len(raw) > p.maxLength {
+		return fmt.Errorf("%w: %d > %d", ErrorURLTooLong, len(raw), p.maxLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ErrorInvalidURL
+	}
+	if _, ok := p.schemes[strings.ToLower(u.Scheme)]; !ok {
+		return fmt.Errorf("%w: %q", ErrorSchemeNotAllowed, u.Scheme)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return ErrorInvalidURL
+	}
+	if _, ok := p.selfHosts[strings.ToLower(u.Host)]; ok {
+		return ErrorSelfLoop
+	}
+	if p.blocked(host) {
+		return fmt.Errorf("%w: %s", ErrorHostBlocked, host)
+	}
+	if !p.allowPrivate && isPrivateHost(host) {
+		return fmt.Errorf("%w: %s", ErrorPrivateAddress, host)
+	}
+	return nil
+}
+
+// blocked сообщает, что хост или один из его родительских доменов заблокирован.
+func (p *Policy) blocked(host string) bool {
+	if _, ok := p.blockedHosts[host]; ok {
+		return true
+	}
+	for d := host; d != ""; {
+		if _, ok := p.blockedDomains[d]; ok {
+			return true
+		}
+		_, parent, found := strings.Cut(d, ".")
+		if !found {
+			break
+		}
+		d = parent
+	}
+	return false
+}
+
+// isPrivateHost сообщает, что хост - localhost или IP-адрес локальной сети, loopback, link-local.
+// IPv4 разбирается также в сокращенных формах, которые понимают браузеры: "127.1", "2130706433", "0x7f.0.0.1".
+func isPrivateHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ip = parseIPv4Loose(host)
+	}
+	if ip == nil {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// parseIPv4Loose разбирает IPv4 в формате inet_aton: от 1 до 4 частей, каждая в десятичной,
+// восьмеричной (с ведущим 0) или шестнадцатеричной (0x) записи. Последняя часть занимает оставшиеся байты.
+func parseIPv4Loose(host string) net.IP {
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+	nums := make([]uint64, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 0, 32)
+		if err != nil {
+			return nil
+		}
+		nums[i] = n
+	}
+	var v uint64
+	for i, n := range nums[:len(nums)-1] {
+		if n > 0xff {
+			return nil
+		}
+		v |= n << (8 * (3 - i))
+	}
+	last := nums[len(nums)-1]
+	if last >= 1<<(8*(5-len(nums))) {
+		return nil
+	}
+	v |= last
+	return net.IPv4(byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
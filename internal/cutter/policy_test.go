@@ -0,0 +1,65 @@
+package cutter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	p := NewPolicy([]string{"http", "https"}, 64, false, []string{"short.ly", "localhost:8080"})
+	require.NoError(t, p.LoadBlocklist(strings.NewReader(`
+# blocked hosts
+evil.com
+*.malware.org
+.phishing.net # with comment
+`)))
+	tests := []struct {
+		expected error
+		name     string
+		url      string
+	}{
+		{name: "positive", url: "https://ya.ru/path?q=1"},
+		{name: "positive - parent of blocked host", url: "http://sub.evil.com"},
+		{name: "positive - public ip", url: "http://8.8.8.8"},
+		{name: "positive - other port of self host", url: "http://short.ly:8080/abc"},
+		{name: "negative - javascript", url: "javascript:alert(1)", expected: ErrorSchemeNotAllowed},
+		{name: "negative - file", url: "file:///etc/passwd", expected: ErrorSchemeNotAllowed},
+		{name: "negative - no scheme", url: "someurl", expected: ErrorSchemeNotAllowed},
+		{name: "negative - no host", url: "http:///path", expected: ErrorInvalidURL},
+		{name: "negative - blocked host", url: "http://evil.com/x", expected: ErrorHostBlocked},
+		{name: "negative - blocked domain", url: "http://a.b.malware.org", expected: ErrorHostBlocked},
+		{name: "negative - blocked domain itself", url: "http://phishing.net", expected: ErrorHostBlocked},
+		{name: "negative - loopback", url: "http://127.0.0.1/admin", expected: ErrorPrivateAddress},
+		{name: "negative - short loopback", url: "http://127.1", expected: ErrorPrivateAddress},
+		{name: "negative - decimal loopback", url: "http://2130706433", expected: ErrorPrivateAddress},
+		{name: "negative - hex loopback", url: "http://0x7f.0.0.1", expected: ErrorPrivateAddress},
+		{name: "negative - private", url: "http://192.168.1.1", expected: ErrorPrivateAddress},
+		{name: "negative - ipv6 loopback", url: "http://[::1]", expected: ErrorPrivateAddress},
+		{name: "negative - link local", url: "http://169.254.169.254/latest", expected: ErrorPrivateAddress},
+		{name: "negative - localhost", url: "http://localhost:9000", expected: ErrorPrivateAddress},
+		{name: "negative - too long", url: "http://ya.ru/" + strings.Repeat("a", 64), expected: ErrorURLTooLong},
+		{name: "negative - self loop", url: "http://short.ly/abc", expected: ErrorSelfLoop},
+		{name: "negative - self loop with port", url: "http://localhost:8080/abc", expected: ErrorSelfLoop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(tt.url)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expected)
+			var perr *PolicyError
+			assert.ErrorAs(t, err, &perr)
+		})
+	}
+}
+
+func TestPolicyAllowPrivate(t *testing.T) {
+	p := NewPolicy([]string{"http"}, 0, true, nil)
+	assert.NoError(t, p.Check("http://127.0.0.1:8080"))
+	assert.NoError(t, p.Check("http://ya.ru/"+strings.Repeat("a", 4096)), "zero max length disables the limit")
+}
@@ -0,0 +1,57 @@
+package cutter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// maxTitleLength максимальная длина названия сокращения в символах.
+const maxTitleLength = 200
+
+// ErrorInvalidTitle слишком длинное название сокращения.
+var ErrorInvalidTitle = errors.New("title must be at most 200 characters")
+
+// checkTitle проверяет длину названия сокращения.
+func checkTitle(title string) error {
+	if n := utf8.RuneCountInString(title); n > maxTitleLength {
+		return fmt.Errorf("title of %d characters: %w", n, ErrorInvalidTitle)
+	}
+	return nil
+}
+
+// Preview выдает данные страницы предпросмотра сокращения v.Short: URL перехода, название,
+// момент создания и количество переходов.
+// В отличие от Redirect не списывает переход у сокращений с ограничением переходов.
+// Для сокращений с паролем возвращает ErrorPasswordRequired: URL перехода не раскрывается без пароля.
+// До начала работы сокращения возвращает *NotActiveError.
+func (a *App) Preview(ctx context.Context, v jsonobject.Visit) (jsonobject.Preview, error) {
+	item, err := a.storage.GetOriginalURL(ctx, v.Short)
+	if err != nil {
+		return jsonobject.Preview{}, fmt.Errorf("preview: while getting value by key:%s: %w", v.Short, err)
+	}
+	if err = a.checkActive(item); err != nil {
+		return jsonobject.Preview{}, fmt.Errorf("preview: %s: %w", v.Short, err)
+	}
+	if item.Protected() {
+		return jsonobject.Preview{}, fmt.Errorf("preview: %s: %w", v.Short, ErrorPasswordRequired)
+	}
+	item.OriginalURL, _ = a.destination(item, v)
+	target, err := passthrough(item, v)
+	if err != nil {
+		return jsonobject.Preview{}, fmt.Errorf("preview: %s: %w", v.Short, err)
+	}
+	clicks, err := a.storage.CountClicks(ctx, v.Short)
+	if err != nil {
+		return jsonobject.Preview{}, fmt.Errorf("preview: %s: %w", v.Short, err)
+	}
+	return jsonobject.Preview{
+		CreatedAt: item.CreatedAt,
+		URL:       target,
+		Title:     item.Title,
+		Clicks:    clicks,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package cutter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/mocks"
+)
+
+func TestPreview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clicks := 1
+	item := jsonobject.Item{
+		OriginalURL: "http://ya.ru/landing",
+		CreatedAt:   &created,
+		Title:       "Весенняя распродажа",
+		ClicksLeft:  &clicks,
+		PassPath:    true,
+	}
+	m.EXPECT().GetOriginalURL(gomock.Any(), "short").Return(item, nil).Times(1)
+	m.EXPECT().CountClicks(gomock.Any(), "short").Return(int64(3), nil).Times(1)
+	m.EXPECT().UseClick(gomock.Any(), gomock.Any()).Times(0)
+	res, err := app.Preview(context.Background(), jsonobject.Visit{Short: "short", Path: "extra"})
+	require.NoError(t, err)
+	assert.Equal(t, jsonobject.Preview{
+		CreatedAt: &created,
+		URL:       "http://ya.ru/landing/extra",
+		Title:     item.Title,
+		Clicks:    3,
+	}, res)
+
+	protected := jsonobject.Item{OriginalURL: "http://ya.ru", PasswordHash: "hash"}
+	m.EXPECT().GetOriginalURL(gomock.Any(), "protected").Return(protected, nil).Times(1)
+	_, err = app.Preview(context.Background(), jsonobject.Visit{Short: "protected"})
+	assert.ErrorIs(t, err, ErrorPasswordRequired)
+
+	opts := jsonobject.LinkOptions{Title: strings.Repeat("я", maxTitleLength)}
+	assert.NoError(t, resolveOptions(&opts, time.Now()))
+	opts.Title += "я"
+	assert.ErrorIs(t, resolveOptions(&opts, time.Now()), ErrorInvalidTitle)
+}
@@ -0,0 +1,67 @@
+package cutter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/logging"
+)
+
+// purgeStats статистика окончательного удаления сокращений.
+type purgeStats struct {
+	lastRunAt   time.Time
+	lastPurged  int64
+	totalPurged int64
+	mu          sync.Mutex
+}
+
+// PurgeDeleted периодически окончательно удаляет из хранилища сокращения,
+// удаленные пользователями раньше, чем retention назад.
+// Работает до отмены контекста, поэтому запускается в отдельной горутине.
+func (a *App) PurgeDeleted(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.purgeDeleted(ctx, retention)
+		}
+	}
+}
+
+// purgeDeleted выполняет один запуск окончательного удаления и обновляет статистику.
+func (a *App) purgeDeleted(ctx context.Context, retention time.Duration) {
+	now := a.now()
+	n, err := a.storage.PurgeDeleted(ctx, now.Add(-retention))
+	a.purge.mu.Lock()
+	a.purge.lastRunAt = now
+	a.purge.lastPurged = n
+	a.purge.totalPurged += n
+	a.purge.mu.Unlock()
+	if err != nil {
+		logging.Log.Errorw("PurgeDeleted", "error", err, "count", n)
+		return
+	}
+	if n > 0 {
+		logging.Log.Infow("PurgeDeleted: purged deleted urls", "count", n)
+	}
+}
+
+// PurgeStats выдает статистику окончательного удаления сокращений с момента старта.
+func (a *App) PurgeStats() jsonobject.PurgeStats {
+	a.purge.mu.Lock()
+	defer a.purge.mu.Unlock()
+	res := jsonobject.PurgeStats{
+		LastPurged:  a.purge.lastPurged,
+		TotalPurged: a.purge.totalPurged,
+	}
+	if !a.purge.lastRunAt.IsZero() {
+		lastRunAt := a.purge.lastRunAt
+		res.LastRunAt = &lastRunAt
+	}
+	return res
+}
@@ -0,0 +1,40 @@
+package cutter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/mocks"
+)
+
+func TestPurgeDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+
+	stats := app.PurgeStats()
+	assert.Nil(t, stats.LastRunAt, "no runs yet")
+
+	const retention = time.Hour
+	m.EXPECT().PurgeDeleted(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, before time.Time) (int64, error) {
+			assert.WithinDuration(t, time.Now().Add(-retention), before, time.Second)
+			return 3, nil
+		}).Times(1)
+	app.purgeDeleted(context.Background(), retention)
+
+	m.EXPECT().PurgeDeleted(gomock.Any(), gomock.Any()).Return(int64(2), errors.New("db error")).Times(1)
+	app.purgeDeleted(context.Background(), retention)
+
+	stats = app.PurgeStats()
+	require.NotNil(t, stats.LastRunAt)
+	assert.Equal(t, int64(2), stats.LastPurged, "partially purged rows are counted")
+	assert.Equal(t, int64(5), stats.TotalPurged)
+}
@@ -0,0 +1,59 @@
+package cutter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// Способы перехода по сокращению, см. jsonobject.LinkOptions.
+const (
+	RedirectMovedPermanently = "301"          // постоянный редирект, метод может смениться на GET
+	RedirectFound            = "302"          // временный редирект для клиентов без поддержки 307
+	RedirectTemporary        = "307"          // временный редирект с сохранением метода
+	RedirectPermanent        = "308"          // постоянный редирект с сохранением метода
+	RedirectMetaRefresh      = "meta-refresh" // HTML-страница с мгновенным переходом через meta refresh
+	RedirectInterstitial     = "interstitial" // HTML-страница с адресом перехода и ссылкой на него
+)
+
+// ErrorInvalidRedirectType недопустимый способ перехода.
+var ErrorInvalidRedirectType = errors.New("redirect_type must be 301, 302, 307, 308, meta-refresh or interstitial")
+
+// checkRedirectType проверяет способ перехода. Пустой способ заменяется способом по умолчанию сервиса.
+func checkRedirectType(t string) error {
+	switch t {
+	case "", RedirectMovedPermanently, RedirectFound, RedirectTemporary, RedirectPermanent,
+		RedirectMetaRefresh, RedirectInterstitial:
+		return nil
+	}
+	return fmt.Errorf("redirect_type %q: %w", t, ErrorInvalidRedirectType)
+}
+
+// follow выдает переход по найденному сокращению item: URL по правилам перехода или вариантам, см. destination,
+// с путем и параметрами перехода v, см. passthrough, и способ перехода сокращения или способ по умолчанию App.
+// Для сокращений с ограничением переходов атомарно списывает один переход.
+func (a *App) follow(ctx context.Context, item jsonobject.Item, v jsonobject.Visit) (jsonobject.Target, error) {
+	var variant string
+	item.OriginalURL, variant = a.destination(item, v)
+	target, err := passthrough(item, v)
+	if err != nil {
+		return jsonobject.Target{}, err
+	}
+	if item.ClicksLeft != nil {
+		if err = a.storage.UseClick(ctx, v.Short); err != nil {
+			return jsonobject.Target{}, fmt.Errorf("use click: %w", err)
+		}
+	}
+	res := jsonobject.Target{
+		URL:          target,
+		RedirectType: item.RedirectType,
+		Variant:      variant,
+		Sticky:       item.StickyVariant && variant != "",
+	}
+	if res.RedirectType == "" {
+		res.RedirectType = a.redirectType
+	}
+	return res, nil
+}
@@ -0,0 +1,50 @@
+package cutter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/config"
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/mocks"
+)
+
+func TestRedirectType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	tests := []struct {
+		name         string
+		defaultType  string
+		linkType     string
+		expectedType string
+	}{
+		{name: "service default", expectedType: RedirectTemporary},
+		{name: "configured default", defaultType: RedirectInterstitial, expectedType: RedirectInterstitial},
+		{name: "link type", defaultType: RedirectFound, linkType: RedirectPermanent, expectedType: RedirectPermanent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := New(m, config.Config{RedirectType: tt.defaultType})
+			require.NoError(t, err)
+			item := jsonobject.Item{OriginalURL: "http://ya.ru", RedirectType: tt.linkType}
+			m.EXPECT().GetOriginalURL(gomock.Any(), "short").Return(item, nil).Times(1)
+			res, err := app.Redirect(context.Background(), jsonobject.Visit{Short: "short"})
+			require.NoError(t, err)
+			assert.Equal(t, jsonobject.Target{URL: item.OriginalURL, RedirectType: tt.expectedType}, res)
+		})
+	}
+
+	_, err := New(m, config.Config{RedirectType: "303"})
+	assert.ErrorIs(t, err, ErrorInvalidRedirectType)
+
+	opts := jsonobject.LinkOptions{RedirectType: "refresh"}
+	assert.ErrorIs(t, resolveOptions(&opts, time.Now()), ErrorInvalidRedirectType)
+	opts = jsonobject.LinkOptions{RedirectType: RedirectMetaRefresh}
+	assert.NoError(t, resolveOptions(&opts, time.Now()))
+}
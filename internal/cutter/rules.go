@@ -0,0 +1,388 @@
+package cutter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// maxRules максимальное количество правил перехода у одного сокращения.
+const maxRules = 20
+
+// Сравнения заголовка запроса в правилах перехода, см. jsonobject.HeaderMatch.
+const (
+	HeaderEquals   = "equals"
+	HeaderPrefix   = "prefix"
+	HeaderContains = "contains"
+	HeaderExists   = "exists"
+)
+
+// Ошибки правил перехода.
+var (
+	ErrorInvalidRule  = errors.New("invalid redirect rule")                  // некорректное условие правила
+	ErrorTooManyRules = errors.New("url can have at most 20 redirect rules") // превышено количество правил
+	ErrorRuleNotFound = errors.New("redirect rule not found")                // правила с таким номером нет
+)
+
+var (
+	// headerNamePattern допустимое имя заголовка (token по RFC 9110).
+	headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+	// languagePattern допустимый языковой тег: en, en-US, zh-Hant-TW.
+	languagePattern = regexp.MustCompile(`^[a-z]{1,8}(-[a-z0-9]{1,8})*$`)
+)
+
+// weekdays дни недели правил перехода.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// locations часовые пояса правил перехода, загруженные из базы часовых поясов.
+var locations sync.Map
+
+// Rules выдает правила перехода сокращения пользователя userID в порядке проверки.
+func (a *App) Rules(ctx context.Context, userID, short string) (jsonobject.Rules, error) {
+	res, err := a.storage.GetRules(ctx, userID, short)
+	if err != nil {
+		return nil, fmt.Errorf("rules: %w", err)
+	}
+	return res, nil
+}
+
+// SetRules заменяет правила перехода сокращения пользователя userID, пустой список удаляет все правила.
+// URL правил нормализуются и проверяются политикой App, как при создании сокращения.
+// Возвращает сохраненные правила.
+func (a *App) SetRules(ctx context.Context, userID, short string, rules jsonobject.Rules) (jsonobject.Rules, error) {
+	if len(rules) > maxRules {
+		return nil, fmt.Errorf("setRules: %d rules: %w", len(rules), ErrorTooManyRules)
+	}
+	prepared := make(jsonobject.Rules, len(rules))
+	for i, r := range rules {
+		var err error
+		if prepared[i], err = a.prepareRule(r); err != nil {
+			return nil, fmt.Errorf("setRules: rule %d: %w", i, err)
+		}
+	}
+	res, err := a.storage.UpdateRules(ctx, userID, short, func(jsonobject.Rules) (jsonobject.Rules, error) {
+		return prepared, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("setRules: %w", err)
+	}
+	return res, nil
+}
+
+// AddRule добавляет правило перехода в конец списка правил сокращения пользователя userID.
+// Возвращает сохраненные правила.
+func (a *App) AddRule(ctx context.Context, userID, short string, rule jsonobject.Rule) (jsonobject.Rules, error) {
+	rule, err := a.prepareRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("addRule: %w", err)
+	}
+	res, err := a.storage.UpdateRules(ctx, userID, short, func(rules jsonobject.Rules) (jsonobject.Rules, error) {
+		if len(rules) >= maxRules {
+			return nil, ErrorTooManyRules
+		}
+		return append(rules, rule), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("addRule: %w", err)
+	}
+	return res, nil
+}
+
+// UpdateRule заменяет правило перехода с номером n (с нуля) сокращения пользователя userID.
+// Возвращает сохраненные правила.
+func (a *App) UpdateRule(ctx context.Context, userID, short string, n int, rule jsonobject.Rule) (jsonobject.Rules, error) {
+	rule, err := a.prepareRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("updateRule: %w", err)
+	}
+	res, err := a.storage.UpdateRules(ctx, userID, short, func(rules jsonobject.Rules) (jsonobject.Rules, error) {
+		if n < 0 || n >= len(rules) {
+			return nil, fmt.Errorf("rule %d: %w", n, ErrorRuleNotFound)
+		}
+		rules[n] = rule
+		return rules, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("updateRule: %w", err)
+	}
+	return res, nil
+}
+
+// DeleteRule удаляет правило перехода с номером n (с нуля) сокращения пользователя userID,
+// следующие правила сдвигаются на его место. Возвращает оставшиеся правила.
+func (a *App) DeleteRule(ctx context.Context, userID, short string, n int) (jsonobject.Rules, error) {
+	res, err := a.storage.UpdateRules(ctx, userID, short, func(rules jsonobject.Rules) (jsonobject.Rules, error) {
+		if n < 0 || n >= len(rules) {
+			return nil, fmt.Errorf("rule %d: %w", n, ErrorRuleNotFound)
+		}
+		return append(rules[:n], rules[n+1:]...), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("deleteRule: %w", err)
+	}
+	return res, nil
+}
+
+// prepareRule проверяет правило перехода и приводит его к каноническому виду:
+// URL нормализуется, имя заголовка, языки, классы устройств и дни недели приводятся к одному регистру.
+// У правила должно быть хотя бы одно условие.
+func (a *App) prepareRule(r jsonobject.Rule) (jsonobject.Rule, error) {
+	if r.Header == nil && r.Time == nil && len(r.Languages) == 0 && len(r.Devices) == 0 {
+		return r, fmt.Errorf("no conditions: %w", ErrorInvalidRule)
+	}
+	url, err := a.prepareURL(r.URL)
+	if err != nil {
+		return r, err
+	}
+	res := jsonobject.Rule{URL: url}
+	if r.Header != nil {
+		h, err := prepareHeaderMatch(*r.Header)
+		if err != nil {
+			return r, err
+		}
+		res.Header = &h
+	}
+	if r.Time != nil {
+		w, err := prepareTimeWindow(*r.Time)
+		if err != nil {
+			return r, err
+		}
+		res.Time = &w
+	}
+	for _, l := range r.Languages {
+		l = strings.ToLower(l)
+		if !languagePattern.MatchString(l) {
+			return r, fmt.Errorf("language %q: %w", l, ErrorInvalidRule)
+		}
+		res.Languages = append(res.Languages, l)
+	}
+	for _, d := range r.Devices {
+		d = strings.ToLower(d)
+		switch d {
+		case DeviceMobile, DeviceTablet, DeviceDesktop, DeviceBot:
+		default:
+			return r, fmt.Errorf("device %q must be mobile, tablet, desktop or bot: %w", d, ErrorInvalidRule)
+		}
+		res.Devices = append(res.Devices, d)
+	}
+	return res, nil
+}
+
+// prepareHeaderMatch проверяет условие на заголовок, пустое сравнение заменяется на equals.
+func prepareHeaderMatch(h jsonobject.HeaderMatch) (jsonobject.HeaderMatch, error) {
+	if !headerNamePattern.MatchString(h.Name) {
+		return h, fmt.Errorf("header name %q: %w", h.Name, ErrorInvalidRule)
+	}
+	h.Name = http.CanonicalHeaderKey(h.Name)
+	h.Op = strings.ToLower(h.Op)
+	switch h.Op {
+	case "":
+		h.Op = HeaderEquals
+	case HeaderEquals, HeaderPrefix, HeaderContains:
+	case HeaderExists:
+		h.Value = ""
+		return h, nil
+	default:
+		return h, fmt.Errorf("header op %q must be equals, prefix, contains or exists: %w", h.Op, ErrorInvalidRule)
+	}
+	if h.Value == "" {
+		return h, fmt.Errorf("header %s: empty value: %w", h.Name, ErrorInvalidRule)
+	}
+	return h, nil
+}
+
+// prepareTimeWindow проверяет условие на время перехода.
+func prepareTimeWindow(w jsonobject.TimeWindow) (jsonobject.TimeWindow, error) {
+	if w.NotBefore == nil && w.NotAfter == nil && w.From == "" && w.To == "" && len(w.Weekdays) == 0 {
+		return w, fmt.Errorf("empty time window: %w", ErrorInvalidRule)
+	}
+	if w.NotBefore != nil && w.NotAfter != nil && !w.NotBefore.Before(*w.NotAfter) {
+		return w, fmt.Errorf("not_before must be before not_after: %w", ErrorInvalidRule)
+	}
+	if w.From != "" || w.To != "" {
+		from, errFrom := dayMinute(w.From)
+		to, errTo := dayMinute(w.To)
+		if err := errors.Join(errFrom, errTo); err != nil {
+			return w, fmt.Errorf("from and to must be HH:MM: %w", ErrorInvalidRule)
+		}
+		if from == to {
+			return w, fmt.Errorf("from and to must differ: %w", ErrorInvalidRule)
+		}
+	}
+	days := make([]string, 0, len(w.Weekdays))
+	for _, d := range w.Weekdays {
+		d = strings.ToLower(d)
+		if _, isFound := weekdays[d]; !isFound {
+			return w, fmt.Errorf("weekday %q: %w", d, ErrorInvalidRule)
+		}
+		days = append(days, d)
+	}
+	w.Weekdays = days
+	if _, err := loadLocation(w.Location); err != nil {
+		return w, fmt.Errorf("location %q: %w", w.Location, ErrorInvalidRule)
+	}
+	return w, nil
+}
+
+// route выдает URL первого правила из rules, все условия которого выполнены для перехода v в момент now.
+// Если ни одно правило не подошло, возвращает false.
+func route(rules jsonobject.Rules, v jsonobject.Visit, now time.Time) (string, bool) {
+	var device, language string
+	for _, r := range rules {
+		if len(r.Devices) > 0 && device == "" {
+			device = deviceClass(v.Header.Get("User-Agent"))
+		}
+		if len(r.Languages) > 0 && language == "" {
+			language = preferredLanguage(v.Header.Get("Accept-Language"))
+		}
+		if matchRule(r, v.Header, device, language, now) {
+			return r.URL, true
+		}
+	}
+	return "", false
+}
+
+// matchRule сообщает, что выполнены все условия правила r.
+func matchRule(r jsonobject.Rule, header http.Header, device, language string, now time.Time) bool {
+	if len(r.Devices) > 0 && !slices.Contains(r.Devices, device) {
+		return false
+	}
+	if len(r.Languages) > 0 && !matchLanguage(r.Languages, language) {
+		return false
+	}
+	if r.Header != nil && !matchHeader(*r.Header, header) {
+		return false
+	}
+	return r.Time == nil || matchTime(*r.Time, now)
+}
+
+// matchHeader сообщает, что одно из значений заголовка подходит под условие h.
+func matchHeader(h jsonobject.HeaderMatch, header http.Header) bool {
+	values := header.Values(h.Name)
+	if h.Op == HeaderExists {
+		return len(values) > 0
+	}
+	want := strings.ToLower(h.Value)
+	for _, v := range values {
+		v = strings.ToLower(v)
+		switch {
+		case h.Op == HeaderPrefix && strings.HasPrefix(v, want),
+			h.Op == HeaderContains && strings.Contains(v, want),
+			h.Op == HeaderEquals && v == want:
+			return true
+		}
+	}
+	return false
+}
+
+// matchLanguage сообщает, что язык клиента language подходит под один из языков правила.
+func matchLanguage(languages []string, language string) bool {
+	for _, l := range languages {
+		if language == l || strings.HasPrefix(language, l+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTime сообщает, что момент now попадает в окно w.
+func matchTime(w jsonobject.TimeWindow, now time.Time) bool {
+	if w.NotBefore != nil && now.Before(*w.NotBefore) {
+		return false
+	}
+	if w.NotAfter != nil && !now.Before(*w.NotAfter) {
+		return false
+	}
+	loc, err := loadLocation(w.Location)
+	if err != nil {
+		return false
+	}
+	local := now.In(loc)
+	if len(w.Weekdays) > 0 {
+		isDay := false
+		for _, d := range w.Weekdays {
+			isDay = isDay || weekdays[d] == local.Weekday()
+		}
+		if !isDay {
+			return false
+		}
+	}
+	if w.From == "" {
+		return true
+	}
+	from, _ := dayMinute(w.From)
+	to, _ := dayMinute(w.To)
+	m := local.Hour()*60 + local.Minute()
+	if from < to {
+		return from <= m && m < to
+	}
+	return m >= from || m < to
+}
+
+// preferredLanguage возвращает наиболее предпочтительный язык из Accept-Language в нижнем регистре:
+// с наибольшим весом q, при равных весах - указанный раньше. "*" и языки с q=0 пропускаются.
+func preferredLanguage(header string) string {
+	var res string
+	best := 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, isFound := strings.CutPrefix(strings.TrimSpace(params), "q="); isFound {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > best {
+			res, best = tag, q
+		}
+	}
+	return res
+}
+
+// dayMinute переводит время HH:MM в минуту суток.
+func dayMinute(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// loadLocation возвращает часовой пояс по имени IANA, пустое имя - UTC.
+// Загруженные пояса кэшируются: правила проверяются при каждом переходе.
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, isFound := locations.Load(name); isFound {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
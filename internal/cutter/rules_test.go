@@ -0,0 +1,206 @@
+package cutter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+const (
+	uaIPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148 Safari/604.1"
+	uaAndroid = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+	uaTablet  = "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+	uaDesktop = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+	uaBot     = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+)
+
+func TestRoute(t *testing.T) {
+	// понедельник, 10:30 UTC
+	monday := time.Date(2024, 6, 3, 10, 30, 0, 0, time.UTC)
+	after := monday.Add(-time.Hour)
+	before := monday.Add(time.Hour)
+	rules := jsonobject.Rules{
+		{Devices: []string{DeviceMobile}, Languages: []string{"ru"}, URL: "https://app.ru"},
+		{Devices: []string{DeviceMobile, DeviceTablet}, URL: "https://app.com"},
+		{Languages: []string{"de"}, URL: "https://site.de"},
+		{Header: &jsonobject.HeaderMatch{Name: "X-Country", Op: HeaderEquals, Value: "kz"}, URL: "https://site.kz"},
+		{Header: &jsonobject.HeaderMatch{Name: "X-Beta", Op: HeaderExists}, URL: "https://beta.site.ru"},
+		{Header: &jsonobject.HeaderMatch{Name: "Referer", Op: HeaderContains, Value: "vk.com"}, URL: "https://site.ru/vk"},
+		{Time: &jsonobject.TimeWindow{From: "22:00", To: "06:00"}, URL: "https://site.ru/night"},
+		{Time: &jsonobject.TimeWindow{Weekdays: []string{"sat", "sun"}}, URL: "https://site.ru/weekend"},
+		{Time: &jsonobject.TimeWindow{NotBefore: &after, NotAfter: &before, From: "09:00", To: "18:00"}, URL: "https://site.ru/sale"},
+	}
+	tests := []struct {
+		name   string
+		header http.Header
+		now    time.Time
+		want   string
+	}{
+		{name: "all conditions of rule", header: http.Header{"User-Agent": {uaIPhone}, "Accept-Language": {"ru-RU,ru;q=0.9"}},
+			want: "https://app.ru"},
+		{name: "first matching rule", header: http.Header{"User-Agent": {uaAndroid}, "Accept-Language": {"en"}},
+			want: "https://app.com"},
+		{name: "tablet", header: http.Header{"User-Agent": {uaTablet}}, want: "https://app.com"},
+		{name: "preferred language", header: http.Header{"User-Agent": {uaDesktop}, "Accept-Language": {"en;q=0.5,de-AT"}},
+			want: "https://site.de"},
+		{name: "header equals ignores case", header: http.Header{"X-Country": {"KZ"}}, want: "https://site.kz"},
+		{name: "header equals whole value", header: http.Header{"X-Country": {"KZT"}}, now: monday.Add(24 * time.Hour)},
+		{name: "header exists", header: http.Header{"X-Beta": {""}}, want: "https://beta.site.ru"},
+		{name: "header contains", header: http.Header{"Referer": {"https://m.vk.com/feed"}}, want: "https://site.ru/vk"},
+		{name: "night across midnight", now: time.Date(2024, 6, 4, 3, 0, 0, 0, time.UTC), want: "https://site.ru/night"},
+		{name: "weekday", now: time.Date(2024, 6, 8, 12, 0, 0, 0, time.UTC), want: "https://site.ru/weekend"},
+		{name: "time window", now: monday, want: "https://site.ru/sale"},
+		{name: "no rule matched", header: http.Header{"User-Agent": {uaBot}}, now: monday.Add(2 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := tt.now
+			if now.IsZero() {
+				now = monday.Add(-2 * time.Hour)
+			}
+			res, isMatched := route(rules, jsonobject.Visit{Header: tt.header}, now)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.want != "", isMatched)
+		})
+	}
+}
+
+func TestDeviceClass(t *testing.T) {
+	for ua, want := range map[string]string{
+		uaIPhone:  DeviceMobile,
+		uaAndroid: DeviceMobile,
+		uaTablet:  DeviceTablet,
+		uaDesktop: DeviceDesktop,
+		uaBot:     DeviceBot,
+		"":        DeviceDesktop,
+		"Mozilla/5.0 (iPad; CPU OS 16_0 like Mac OS X) Mobile/15E148": DeviceTablet,
+	} {
+		assert.Equal(t, want, deviceClass(ua), ua)
+	}
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                             "",
+		"ru":                           "ru",
+		"en-US,en;q=0.9":               "en-us",
+		"fr;q=0.3, de-DE;q=0.8, en":    "en",
+		"*, it;q=0.5":                  "it",
+		"es;q=0, pt;q=bad, pl;q=0.1":   "pl",
+		"uk;q=0.7, be;q=0.7":           "uk",
+		" zh-Hant-TW ; q=1, zh;q=0.5 ": "zh-hant-tw",
+	} {
+		assert.Equal(t, want, preferredLanguage(header), header)
+	}
+}
+
+func TestPrepareRule(t *testing.T) {
+	app := newApp(EmptyStore{})
+	tests := []struct {
+		name string
+		rule jsonobject.Rule
+		want jsonobject.Rule
+		err  error
+	}{
+		{name: "canonical form",
+			rule: jsonobject.Rule{URL: "HTTPS://Site.ru/", Devices: []string{"Mobile"}, Languages: []string{"en-US"},
+				Header: &jsonobject.HeaderMatch{Name: "x-country", Value: "RU"},
+				Time:   &jsonobject.TimeWindow{From: "09:00", To: "18:00", Weekdays: []string{"Mon"}}},
+			want: jsonobject.Rule{URL: "https://site.ru", Devices: []string{"mobile"}, Languages: []string{"en-us"},
+				Header: &jsonobject.HeaderMatch{Name: "X-Country", Op: HeaderEquals, Value: "RU"},
+				Time:   &jsonobject.TimeWindow{From: "09:00", To: "18:00", Weekdays: []string{"mon"}}}},
+		{name: "exists drops value", rule: jsonobject.Rule{URL: "https://site.ru", Header: &jsonobject.HeaderMatch{Name: "X-Beta", Op: "EXISTS", Value: "1"}},
+			want: jsonobject.Rule{URL: "https://site.ru", Header: &jsonobject.HeaderMatch{Name: "X-Beta", Op: HeaderExists}}},
+		{name: "no conditions", rule: jsonobject.Rule{URL: "https://site.ru"}, err: ErrorInvalidRule},
+		{name: "unknown device", rule: jsonobject.Rule{URL: "https://site.ru", Devices: []string{"tv"}}, err: ErrorInvalidRule},
+		{name: "bad language", rule: jsonobject.Rule{URL: "https://site.ru", Languages: []string{"en_US"}}, err: ErrorInvalidRule},
+		{name: "bad header name", rule: jsonobject.Rule{URL: "https://site.ru", Header: &jsonobject.HeaderMatch{Name: "X Country", Value: "ru"}},
+			err: ErrorInvalidRule},
+		{name: "unknown header op", rule: jsonobject.Rule{URL: "https://site.ru", Header: &jsonobject.HeaderMatch{Name: "X", Op: "regexp", Value: "ru"}},
+			err: ErrorInvalidRule},
+		{name: "empty header value", rule: jsonobject.Rule{URL: "https://site.ru", Header: &jsonobject.HeaderMatch{Name: "X"}}, err: ErrorInvalidRule},
+		{name: "empty time window", rule: jsonobject.Rule{URL: "https://site.ru", Time: &jsonobject.TimeWindow{}}, err: ErrorInvalidRule},
+		{name: "only from", rule: jsonobject.Rule{URL: "https://site.ru", Time: &jsonobject.TimeWindow{From: "09:00"}}, err: ErrorInvalidRule},
+		{name: "same from and to", rule: jsonobject.Rule{URL: "https://site.ru", Time: &jsonobject.TimeWindow{From: "09:00", To: "09:00"}},
+			err: ErrorInvalidRule},
+		{name: "unknown weekday", rule: jsonobject.Rule{URL: "https://site.ru", Time: &jsonobject.TimeWindow{Weekdays: []string{"monday"}}},
+			err: ErrorInvalidRule},
+		{name: "unknown location", rule: jsonobject.Rule{URL: "https://site.ru", Time: &jsonobject.TimeWindow{Weekdays: []string{"mon"}, Location: "Mars/Olympus"}},
+			err: ErrorInvalidRule},
+		{name: "url rejected by policy", rule: jsonobject.Rule{URL: "ftp://site.ru", Devices: []string{"bot"}}, err: ErrorSchemeNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := app.prepareRule(tt.rule)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestRulesCRUD(t *testing.T) {
+	app := newApp(&rulesStore{})
+	ctx := context.Background()
+	mobile := jsonobject.Rule{Devices: []string{DeviceMobile}, URL: "https://app.ru"}
+	bot := jsonobject.Rule{Devices: []string{DeviceBot}, URL: "https://bot.ru"}
+
+	res, err := app.AddRule(ctx, "user", "short", mobile)
+	require.NoError(t, err)
+	assert.Equal(t, jsonobject.Rules{mobile}, res)
+	res, err = app.AddRule(ctx, "user", "short", bot)
+	require.NoError(t, err)
+	assert.Equal(t, jsonobject.Rules{mobile, bot}, res)
+
+	res, err = app.UpdateRule(ctx, "user", "short", 0, bot)
+	require.NoError(t, err)
+	assert.Equal(t, jsonobject.Rules{bot, bot}, res)
+	_, err = app.UpdateRule(ctx, "user", "short", 2, bot)
+	assert.ErrorIs(t, err, ErrorRuleNotFound)
+
+	res, err = app.DeleteRule(ctx, "user", "short", 1)
+	require.NoError(t, err)
+	assert.Equal(t, jsonobject.Rules{bot}, res)
+	_, err = app.DeleteRule(ctx, "user", "short", -1)
+	assert.ErrorIs(t, err, ErrorRuleNotFound)
+
+	many := make(jsonobject.Rules, maxRules)
+	for i := range many {
+		many[i] = mobile
+	}
+	res, err = app.SetRules(ctx, "user", "short", many)
+	require.NoError(t, err)
+	assert.Len(t, res, maxRules)
+	_, err = app.AddRule(ctx, "user", "short", bot)
+	assert.ErrorIs(t, err, ErrorTooManyRules)
+	_, err = app.SetRules(ctx, "user", "short", append(many, bot))
+	assert.ErrorIs(t, err, ErrorTooManyRules)
+
+	res, err = app.SetRules(ctx, "user", "short", nil)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+// rulesStore хранит правила одного сокращения для TestRulesCRUD.
+type rulesStore struct {
+	EmptyStore
+	rules jsonobject.Rules
+}
+
+func (s *rulesStore) UpdateRules(ctx context.Context, userID, short string, change func(jsonobject.Rules) (jsonobject.Rules, error)) (jsonobject.Rules, error) {
+	rules, err := change(append(jsonobject.Rules(nil), s.rules...))
+	if err != nil {
+		return nil, err
+	}
+	s.rules = rules
+	return rules, nil
+}
@@ -0,0 +1,54 @@
+package cutter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// Ошибки запланированного запуска сокращения.
+var (
+	ErrorNotActive         = errors.New("url is not active yet")             // момент начала работы не наступил
+	ErrorInvalidActiveFrom = errors.New("active_from must be before expiry") // сокращение истекло бы до начала работы
+)
+
+// NotActiveError ошибка перехода по сокращению до начала его работы.
+// Содержит момент начала работы и название сокращения для страницы "скоро".
+type NotActiveError struct {
+	ActiveFrom time.Time
+	Title      string
+}
+
+// Error реализует интерфейс error для NotActiveError.
+func (ne *NotActiveError) Error() string {
+	return fmt.Sprintf("url is active from %s", ne.ActiveFrom.Format(time.RFC3339))
+}
+
+// Unwrap реализует интерфейс error для NotActiveError.
+func (ne *NotActiveError) Unwrap() error {
+	return ErrorNotActive
+}
+
+// SetClock заменяет часы App, по умолчанию - time.Now.
+// По этим часам проверяются начало работы сокращений, правила перехода по времени и TTL.
+func (a *App) SetClock(now func() time.Time) {
+	a.now = now
+}
+
+// checkActive возвращает *NotActiveError, если момент начала работы сокращения item еще не наступил.
+func (a *App) checkActive(item jsonobject.Item) error {
+	if item.ActiveFrom != nil && a.now().Before(*item.ActiveFrom) {
+		return &NotActiveError{ActiveFrom: *item.ActiveFrom, Title: item.Title}
+	}
+	return nil
+}
+
+// checkActiveFrom проверяет, что сокращение начнет работать раньше, чем истечет срок его действия.
+func checkActiveFrom(opts *jsonobject.LinkOptions) error {
+	if opts.ActiveFrom != nil && opts.ExpiresAt != nil && !opts.ActiveFrom.Before(*opts.ExpiresAt) {
+		return fmt.Errorf("active_from %s: %w", opts.ActiveFrom.Format(time.RFC3339), ErrorInvalidActiveFrom)
+	}
+	return nil
+}
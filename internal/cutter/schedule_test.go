@@ -0,0 +1,76 @@
+package cutter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/mocks"
+)
+
+func TestScheduledLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+	launch := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	item := jsonobject.Item{OriginalURL: "http://ya.ru", Title: "Запуск", ActiveFrom: &launch}
+	m.EXPECT().GetOriginalURL(gomock.Any(), "short").Return(item, nil).AnyTimes()
+	ctx := context.Background()
+
+	app.SetClock(func() time.Time { return launch.Add(-time.Second) })
+	_, err := app.Redirect(ctx, jsonobject.Visit{Short: "short"})
+	var notActive *NotActiveError
+	require.True(t, errors.As(err, &notActive))
+	assert.Equal(t, NotActiveError{ActiveFrom: launch, Title: "Запуск"}, *notActive)
+	assert.ErrorIs(t, err, ErrorNotActive)
+	_, err = app.Preview(ctx, jsonobject.Visit{Short: "short"})
+	assert.ErrorIs(t, err, ErrorNotActive, "preview does not reveal url before launch")
+	assert.NoError(t, app.CheckLink(ctx, "short"), "qr code is available before launch")
+
+	app.SetClock(func() time.Time { return launch })
+	res, err := app.Redirect(ctx, jsonobject.Visit{Short: "short"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://ya.ru", res.URL)
+}
+
+func TestActiveFromOptions(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	launch := now.Add(time.Hour)
+	expires := now.Add(2 * time.Hour)
+
+	opts := jsonobject.LinkOptions{ActiveFrom: &launch, ExpiresAt: &expires}
+	assert.NoError(t, resolveOptions(&opts, now))
+	opts = jsonobject.LinkOptions{ActiveFrom: &launch, TTL: 3600}
+	assert.ErrorIs(t, resolveOptions(&opts, now), ErrorInvalidActiveFrom, "ttl ends at launch")
+	opts = jsonobject.LinkOptions{ActiveFrom: &expires, ExpiresAt: &launch}
+	assert.ErrorIs(t, resolveOptions(&opts, now), ErrorInvalidActiveFrom)
+}
+
+func TestGetUserURLsScheduled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mocks.NewMockStore(ctrl)
+	app := newApp(m)
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	app.SetClock(func() time.Time { return now })
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	m.EXPECT().GetUserURLs(gomock.Any()).Return(jsonobject.Batch{
+		{ShortURL: "a"},
+		{ShortURL: "b", LinkOptions: jsonobject.LinkOptions{ActiveFrom: &past}},
+		{ShortURL: "c", LinkOptions: jsonobject.LinkOptions{ActiveFrom: &future}},
+	}, nil)
+
+	res, err := app.GetUserURLs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	assert.False(t, res[0].Scheduled)
+	assert.False(t, res[1].Scheduled)
+	assert.True(t, res[2].Scheduled)
+}
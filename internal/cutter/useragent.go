@@ -0,0 +1,113 @@
+package cutter
+
+import "strings"
+
+// uaRule сопоставляет подстроку User-Agent с названием браузера или операционной системы.
+type uaRule struct {
+	token string
+	name  string
+}
+
+// unknownUA название браузера или операционной системы, которые не удалось определить.
+const unknownUA = "Other"
+
+// browserRules правила определения браузера, проверяются по порядку:
+// User-Agent Chromium-браузеров содержит Chrome и Safari, поэтому они проверяются раньше.
+var browserRules = []uaRule{
+	{token: "edg/", name: "Edge"},
+	{token: "edge/", name: "Edge"},
+	{token: "opr/", name: "Opera"},
+	{token: "opera", name: "Opera"},
+	{token: "yabrowser/", name: "Yandex Browser"},
+	{token: "samsungbrowser/", name: "Samsung Internet"},
+	{token: "firefox/", name: "Firefox"},
+	{token: "fxios/", name: "Firefox"},
+	{token: "chrome/", name: "Chrome"},
+	{token: "crios/", name: "Chrome"},
+	{token: "chromium/", name: "Chrome"},
+	{token: "safari/", name: "Safari"},
+	{token: "msie ", name: "Internet Explorer"},
+	{token: "trident/", name: "Internet Explorer"},
+	{token: "curl/", name: "curl"},
+}
+
+// botTokens подстроки User-Agent поисковых роботов и других автоматических клиентов.
+var botTokens = []string{"bot", "crawler", "spider"}
+
+// osRules правила определения операционной системы, проверяются по порядку:
+// User-Agent Android содержит Linux, а iOS - Mac OS X.
+var osRules = []uaRule{
+	{token: "windows", name: "Windows"},
+	{token: "android", name: "Android"},
+	{token: "iphone", name: "iOS"},
+	{token: "ipad", name: "iOS"},
+	{token: "ipod", name: "iOS"},
+	{token: "cros ", name: "ChromeOS"},
+	{token: "mac os x", name: "macOS"},
+	{token: "macintosh", name: "macOS"},
+	{token: "linux", name: "Linux"},
+}
+
+// parseUserAgent определяет по User-Agent браузер и операционную систему клиента.
+// Роботы выделяются в браузер "Bot", неизвестные значения - "Other".
+func parseUserAgent(ua string) (browser, os string) {
+	ua = strings.ToLower(ua)
+	os = matchUA(ua, osRules)
+	for _, token := range botTokens {
+		if strings.Contains(ua, token) {
+			return "Bot", os
+		}
+	}
+	return matchUA(ua, browserRules), os
+}
+
+// matchUA возвращает название первого подошедшего правила.
+func matchUA(ua string, rules []uaRule) string {
+	for _, r := range rules {
+		if strings.Contains(ua, r.token) {
+			return r.name
+		}
+	}
+	return unknownUA
+}
+
+// Классы устройства клиента для правил перехода, см. jsonobject.Rule.
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+)
+
+// tabletTokens подстроки User-Agent планшетов, проверяются раньше мобильных:
+// User-Agent iPad на iPadOS может совпадать с macOS, такие планшеты определяются как desktop.
+var tabletTokens = []string{"ipad", "tablet", "kindle", "silk/", "playbook"}
+
+// mobileTokens подстроки User-Agent мобильных устройств.
+var mobileTokens = []string{"mobi", "iphone", "ipod", "android", "windows phone", "opera mini"}
+
+// deviceClass определяет по User-Agent класс устройства клиента.
+// Android без признака Mobile считается планшетом, неизвестные и пустые User-Agent - desktop.
+func deviceClass(ua string) string {
+	ua = strings.ToLower(ua)
+	switch {
+	case containsAny(ua, botTokens):
+		return DeviceBot
+	case containsAny(ua, tabletTokens),
+		strings.Contains(ua, "android") && !strings.Contains(ua, "mobile"):
+		return DeviceTablet
+	case containsAny(ua, mobileTokens):
+		return DeviceMobile
+	}
+	return DeviceDesktop
+}
+
+// containsAny сообщает, что ua содержит одну из подстрок tokens.
+func containsAny(ua string, tokens []string) bool {
+	for _, token := range tokens {
+		if strings.Contains(ua, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package cutter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// Ограничения вариантов URL для A/B-теста.
+const (
+	minVariants      = 2
+	maxVariants      = 10
+	maxVariantWeight = 10000
+)
+
+// ErrorInvalidVariants некорректные варианты URL.
+var ErrorInvalidVariants = errors.New("variants must contain from 2 to 10 items with unique names and weights from 1 to 10000")
+
+// variantNamePattern допустимое название варианта.
+var variantNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// prepareVariants проверяет варианты URL сокращения и приводит их к каноническому виду:
+// URL нормализуются и проверяются политикой App, вариантам без названия даются буквы по порядку,
+// количество переходов сбрасывается. Без вариантов закрепление варианта отключается.
+func (a *App) prepareVariants(opts *jsonobject.LinkOptions) error {
+	if len(opts.Variants) == 0 {
+		opts.StickyVariant = false
+		return nil
+	}
+	if len(opts.Variants) < minVariants || len(opts.Variants) > maxVariants {
+		return fmt.Errorf("%d variants: %w", len(opts.Variants), ErrorInvalidVariants)
+	}
+	res := make(jsonobject.Variants, len(opts.Variants))
+	names := make(map[string]struct{}, len(opts.Variants))
+	for i, v := range opts.Variants {
+		if v.Name == "" {
+			v.Name = string(rune('A' + i))
+		}
+		if !variantNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("variant name %q: %w", v.Name, ErrorInvalidVariants)
+		}
+		if _, isFound := names[v.Name]; isFound {
+			return fmt.Errorf("variant name %q is repeated: %w", v.Name, ErrorInvalidVariants)
+		}
+		names[v.Name] = struct{}{}
+		if v.Weight < 1 || v.Weight > maxVariantWeight {
+			return fmt.Errorf("variant %s weight %d: %w", v.Name, v.Weight, ErrorInvalidVariants)
+		}
+		url, err := a.prepareURL(v.URL)
+		if err != nil {
+			return fmt.Errorf("variant %s: %w", v.Name, err)
+		}
+		res[i] = jsonobject.Variant{Name: v.Name, URL: url, Weight: v.Weight}
+	}
+	opts.Variants = res
+	return nil
+}
+
+// destination выдает URL перехода по сокращению item: URL первого подошедшего правила перехода, см. route,
+// иначе URL варианта, см. pickVariant, иначе оригинальный URL сокращения.
+// Вторым значением возвращает название выбранного варианта.
+func (a *App) destination(item jsonobject.Item, v jsonobject.Visit) (string, string) {
+	if url, isMatched := route(item.Rules, v, a.now()); isMatched {
+		return url, ""
+	}
+	if len(item.Variants) == 0 {
+		return item.OriginalURL, ""
+	}
+	variant := a.pickVariant(item, v.Variant)
+	return variant.URL, variant.Name
+}
+
+// pickVariant выбирает вариант URL сокращения item случайно, с вероятностью пропорционально весу.
+// Для сокращений с закреплением варианта возвращает закрепленный за посетителем вариант sticky,
+// если такой вариант у сокращения еще есть.
+func (a *App) pickVariant(item jsonobject.Item, sticky string) jsonobject.Variant {
+	total := 0
+	for _, v := range item.Variants {
+		if item.StickyVariant && v.Name == sticky {
+			return v
+		}
+		total += v.Weight
+	}
+	n := a.randIntn(total)
+	for _, v := range item.Variants {
+		if n < v.Weight {
+			return v
+		}
+		n -= v.Weight
+	}
+	return item.Variants[len(item.Variants)-1]
+}
@@ -0,0 +1,87 @@
+package cutter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+func TestPrepareVariants(t *testing.T) {
+	app := newApp(EmptyStore{})
+	tests := []struct {
+		name string
+		opts jsonobject.LinkOptions
+		want jsonobject.LinkOptions
+		err  error
+	}{
+		{name: "no variants", opts: jsonobject.LinkOptions{StickyVariant: true}, want: jsonobject.LinkOptions{}},
+		{name: "canonical form",
+			opts: jsonobject.LinkOptions{StickyVariant: true, Variants: jsonobject.Variants{
+				{URL: "HTTPS://Site.ru/", Weight: 70, Clicks: 5}, {Name: "new", URL: "https://site.ru/new?utm_source=x", Weight: 30}}},
+			want: jsonobject.LinkOptions{StickyVariant: true, Variants: jsonobject.Variants{
+				{Name: "A", URL: "https://site.ru", Weight: 70}, {Name: "new", URL: "https://site.ru/new?utm_source=x", Weight: 30}}}},
+		{name: "one variant", opts: jsonobject.LinkOptions{Variants: jsonobject.Variants{{URL: "https://site.ru", Weight: 1}}},
+			err: ErrorInvalidVariants},
+		{name: "repeated name", opts: jsonobject.LinkOptions{Variants: jsonobject.Variants{
+			{Name: "B", URL: "https://site.ru", Weight: 1}, {URL: "https://site.ru/b", Weight: 1}}}, err: ErrorInvalidVariants},
+		{name: "bad name", opts: jsonobject.LinkOptions{Variants: jsonobject.Variants{
+			{Name: "a b", URL: "https://site.ru", Weight: 1}, {URL: "https://site.ru/b", Weight: 1}}}, err: ErrorInvalidVariants},
+		{name: "zero weight", opts: jsonobject.LinkOptions{Variants: jsonobject.Variants{
+			{URL: "https://site.ru", Weight: 1}, {URL: "https://site.ru/b"}}}, err: ErrorInvalidVariants},
+		{name: "url rejected by policy", opts: jsonobject.LinkOptions{Variants: jsonobject.Variants{
+			{URL: "https://site.ru", Weight: 1}, {URL: "ftp://site.ru", Weight: 1}}}, err: ErrorSchemeNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := app.prepareVariants(&opts)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, opts)
+		})
+	}
+}
+
+func TestDestination(t *testing.T) {
+	app := newApp(EmptyStore{})
+	item := jsonobject.Item{
+		OriginalURL: "https://site.ru",
+		Variants:    jsonobject.Variants{{Name: "A", URL: "https://site.ru/a", Weight: 70}, {Name: "B", URL: "https://site.ru/b", Weight: 30}},
+		Rules:       jsonobject.Rules{{Devices: []string{DeviceBot}, URL: "https://site.ru/bot"}},
+	}
+	tests := []struct {
+		name        string
+		n           int
+		sticky      bool
+		visit       jsonobject.Visit
+		wantURL     string
+		wantVariant string
+	}{
+		{name: "first variant by weight", n: 69, wantURL: "https://site.ru/a", wantVariant: "A"},
+		{name: "second variant by weight", n: 70, wantURL: "https://site.ru/b", wantVariant: "B"},
+		{name: "cookie ignored without sticky", n: 0, visit: jsonobject.Visit{Variant: "B"}, wantURL: "https://site.ru/a", wantVariant: "A"},
+		{name: "sticky variant", n: 0, sticky: true, visit: jsonobject.Visit{Variant: "B"}, wantURL: "https://site.ru/b", wantVariant: "B"},
+		{name: "unknown sticky variant", n: 99, sticky: true, visit: jsonobject.Visit{Variant: "C"}, wantURL: "https://site.ru/b", wantVariant: "B"},
+		{name: "rule before variants", visit: jsonobject.Visit{Header: http.Header{"User-Agent": {uaBot}}}, wantURL: "https://site.ru/bot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.randIntn = func(n int) int {
+				assert.Equal(t, 100, n)
+				return tt.n
+			}
+			item := item
+			item.StickyVariant = tt.sticky
+			url, variant := app.destination(item, tt.visit)
+			assert.Equal(t, tt.wantURL, url)
+			assert.Equal(t, tt.wantVariant, variant)
+		})
+	}
+}
@@ -10,22 +10,29 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pressly/goose/v3"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/dmad1989/urlcut/internal/config"
+	"github.com/dmad1989/urlcut/internal/cutter"
 	"github.com/dmad1989/urlcut/internal/jsonobject"
-	"github.com/dmad1989/urlcut/internal/logging"
 )
 
 const timeout = time.Duration(time.Second * 10)
 
+// purgeBatchSize количество записей, удаляемых PurgeDeleted одним запросом.
+// Небольшие пачки не держат долгих блокировок на таблице urls.
+const purgeBatchSize = 100
+
+// shortUniqueConstraint ограничение уникальности сокращения.
+const shortUniqueConstraint = "urls_short_unique"
+
 var (
-	//go:embed sql/migrations/00001_create_urls_table.sql
+	//go:embed sql/migrations/*.sql
 	embedMigrations embed.FS
-	//go:embed sql/checkTableExists.sql
-	sqlCheckTableExists string
 	//go:embed sql/getShortURL.sql
 	sqlGetShortURL string
 	//go:embed sql/getOriginalURL.sql
@@ -34,21 +41,78 @@ var (
 	sqlInsert string
 	//go:embed sql/getUrlsByAuthor.sql
 	sqlGetUrlsByAuthor string
-	//go:embed sql/markDelete.sql
-	sqlMarkDelete string
+	//go:embed sql/deleteURLs.sql
+	sqlDeleteURLs string
+	//go:embed sql/nextShortID.sql
+	sqlNextShortID string
+	//go:embed sql/markExpired.sql
+	sqlMarkExpired string
+	//go:embed sql/useClick.sql
+	sqlUseClick string
+	//go:embed sql/lockURL.sql
+	sqlLockURL string
+	//go:embed sql/getURLAuthor.sql
+	sqlGetURLAuthor string
+	//go:embed sql/insertHistory.sql
+	sqlInsertHistory string
+	//go:embed sql/updateURL.sql
+	sqlUpdateURL string
+	//go:embed sql/getURLHistory.sql
+	sqlGetURLHistory string
+	//go:embed sql/popHistory.sql
+	sqlPopHistory string
+	//go:embed sql/getDeletedByAuthor.sql
+	sqlGetDeletedByAuthor string
+	//go:embed sql/restoreURL.sql
+	sqlRestoreURL string
+	//go:embed sql/purgeDeleted.sql
+	sqlPurgeDeleted string
+	//go:embed sql/insertDeleteJob.sql
+	sqlInsertDeleteJob string
+	//go:embed sql/updateDeleteJob.sql
+	sqlUpdateDeleteJob string
+	//go:embed sql/getDeleteJob.sql
+	sqlGetDeleteJob string
+	//go:embed sql/claimDeleteJob.sql
+	sqlClaimDeleteJob string
+	//go:embed sql/requeueDeleteJobs.sql
+	sqlRequeueDeleteJobs string
+	//go:embed sql/insertClicks.sql
+	sqlInsertClicks string
+	//go:embed sql/countClicks.sql
+	sqlCountClicks string
+	//go:embed sql/getClickTotals.sql
+	sqlGetClickTotals string
+	//go:embed sql/getClicksDaily.sql
+	sqlGetClicksDaily string
+	//go:embed sql/getClickReferrers.sql
+	sqlGetClickReferrers string
+	//go:embed sql/getClickUserAgents.sql
+	sqlGetClickUserAgents string
+	//go:embed sql/getURLRules.sql
+	sqlGetURLRules string
+	//go:embed sql/updateURLRules.sql
+	sqlUpdateURLRules string
+	//go:embed sql/insertVariants.sql
+	sqlInsertVariants string
+	//go:embed sql/getClickVariants.sql
+	sqlGetClickVariants string
 )
 
 type configer interface {
 	GetFileStoreName() string
 	GetDBConnName() string
+	GetURLUniqueness() string
 }
 
 type storage struct {
 	db *sql.DB
+	// perUser URL уникален в пределах пользователя, а не глобально
+	perUser bool
 }
 
 // New создает storage.
-// Инициализирует связь с БД, проверяет ее доступность, если необходимо создает таблицы.
+// Инициализирует связь с БД, проверяет ее доступность, применяет недостающие миграции.
 func New(ctx context.Context, c configer) (*storage, error) {
 	if c.GetDBConnName() == "" {
 		return nil, errors.New("init db storage: conn name is empty")
@@ -62,32 +126,22 @@ func New(ctx context.Context, c configer) (*storage, error) {
 	db.SetMaxOpenConns(50)
 
 	res := storage{
-		db: db}
+		db:      db,
+		perUser: c.GetURLUniqueness() == config.URLUniqueUser,
+	}
 
 	if err = res.Ping(ctx); err != nil {
 		return nil, fmt.Errorf("check DB after create: %w", err)
 	}
-	tctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
 
-	row := db.QueryRowContext(tctx, sqlCheckTableExists)
-	if row.Err() != nil {
-		return nil, fmt.Errorf("check table exists: %w", err)
-	}
-	var tableExists bool
-	if err = row.Scan(&tableExists); err != nil {
-		return nil, fmt.Errorf("check table exists: %w", err)
-	}
-	if !tableExists {
-		goose.SetBaseFS(embedMigrations)
+	goose.SetBaseFS(embedMigrations)
 
-		if err := goose.SetDialect("postgres"); err != nil {
-			return nil, fmt.Errorf("goose.SetDialect: %w", err)
-		}
+	if err := goose.SetDialect("postgres"); err != nil {
+		return nil, fmt.Errorf("goose.SetDialect: %w", err)
+	}
 
-		if err := goose.Up(db, "sql/migrations"); err != nil {
-			return nil, fmt.Errorf("goose: create table: %w", err)
-		}
+	if err := goose.Up(db, "sql/migrations"); err != nil {
+		return nil, fmt.Errorf("goose: migrate: %w", err)
 	}
 
 	return &res, nil
@@ -111,11 +165,12 @@ func (s *storage) CloseDB() error {
 }
 
 // GetShortURL ищет по URL его сокращение.
+// В режиме уникальности по пользователю ищутся только сокращения текущего пользователя.
 func (s *storage) GetShortURL(ctx context.Context, key string) (string, error) {
 	tctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	sURL := ""
-	err := s.db.QueryRowContext(tctx, sqlGetShortURL, key).Scan(&sURL)
+	err := s.db.QueryRowContext(tctx, sqlGetShortURL, key, s.uniqScope(ctx.Value(config.UserCtxKey))).Scan(&sURL)
 
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
@@ -127,38 +182,173 @@ func (s *storage) GetShortURL(ctx context.Context, key string) (string, error) {
 	}
 }
 
-// Add добавляет в БД новую запись: URL, сокращение, автора.
-func (s *storage) Add(ctx context.Context, original, short string) error {
+// Add добавляет в БД новую запись: URL, сокращение, автора, срок действия.
+// Варианты URL записываются в таблицу url_variants в той же транзакции.
+func (s *storage) Add(ctx context.Context, original, short string, opts jsonobject.LinkOptions) error {
 	tctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	userID := ctx.Value(config.UserCtxKey)
-	if _, err := s.db.ExecContext(tctx, sqlInsert, short, original, userID); err != nil {
-		return fmt.Errorf("dbstore.add: write items: %w", err)
+	tx, err := s.db.BeginTx(tctx, nil)
+	if err != nil {
+		return fmt.Errorf("dbstore.add, transation begin: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(tctx, sqlInsert, s.insertArgs(short, original, userID, opts)...); err != nil {
+		return fmt.Errorf("dbstore.add: write items: %w", checkShortTaken(err, short))
+	}
+	if err = insertVariants(tctx, tx, short, opts.Variants); err != nil {
+		return fmt.Errorf("dbstore.add: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("dbstore.add, commit: %w", err)
+	}
+	return nil
+}
+
+// insertVariants записывает варианты URL сокращения short одним запросом.
+func insertVariants(ctx context.Context, tx *sql.Tx, short string, variants jsonobject.Variants) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	positions := make([]int32, len(variants))
+	names := make([]string, len(variants))
+	urls := make([]string, len(variants))
+	weights := make([]int32, len(variants))
+	for i, v := range variants {
+		positions[i], names[i], urls[i], weights[i] = int32(i), v.Name, v.URL, int32(v.Weight)
+	}
+	if _, err := tx.ExecContext(ctx, sqlInsertVariants, short, positions, names, urls, weights); err != nil {
+		return fmt.Errorf("insert variants: %w", err)
 	}
 	return nil
 }
 
+// variantsFromJSON читает варианты URL из json, NULL - вариантов нет.
+func variantsFromJSON(data []byte) (jsonobject.Variants, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var res jsonobject.Variants
+	if err := res.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("unmarshal variants: %w", err)
+	}
+	return res, nil
+}
+
+// insertArgs возвращает параметры запроса sqlInsert для сокращения short пользователя userID.
+func (s *storage) insertArgs(short, original string, userID any, opts jsonobject.LinkOptions) []any {
+	return []any{short, original, userID, opts.ExpiresAt, maxClicks(opts), passwordHash(opts), s.uniqScope(userID),
+		opts.PassQuery, opts.PassPath, opts.RedirectType, opts.Title, opts.StickyVariant, opts.ActiveFrom}
+}
+
+// uniqScope возвращает область уникальности URL для записи пользователя userID:
+// пустую строку для глобальной уникальности или ID пользователя.
+func (s *storage) uniqScope(userID any) string {
+	if !s.perUser {
+		return ""
+	}
+	id, _ := userID.(string)
+	return id
+}
+
+// maxClicks возвращает начальное значение clicks_left: NULL для сокращений без ограничения переходов.
+func maxClicks(opts jsonobject.LinkOptions) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(opts.MaxClicks), Valid: opts.MaxClicks > 0}
+}
+
+// passwordHash возвращает хэш пароля: NULL для сокращений без пароля.
+func passwordHash(opts jsonobject.LinkOptions) sql.NullString {
+	return sql.NullString{String: opts.PasswordHash, Valid: opts.PasswordHash != ""}
+}
+
+// checkShortTaken заменяет ошибку уникальности сокращения на cutter.ShortURLTakenError.
+// Остальные ошибки возвращаются без изменений.
+func checkShortTaken(err error, short string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == shortUniqueConstraint {
+		return cutter.NewShortURLTakenError(short)
+	}
+	return err
+}
+
+// NextID выдает следующее значение последовательности для генерации сокращений.
+func (s *storage) NextID(ctx context.Context) (int64, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var id int64
+	if err := s.db.QueryRowContext(tctx, sqlNextShortID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("dbstore.NextID: %w", err)
+	}
+	return id, nil
+}
+
 // ErrorDeletedURL специальная ошибка для удаленных URL.
-var ErrorDeletedURL = errors.New("url was deleted")
+//
+// Deprecated: используйте cutter.ErrorDeletedURL, ошибка общая для всех хранилищ.
+var ErrorDeletedURL = cutter.ErrorDeletedURL
 
-// GetOriginalURL находит по переданному сокращению оригинальный URL.
-func (s *storage) GetOriginalURL(ctx context.Context, value string) (string, error) {
+// GetOriginalURL находит по переданному сокращению запись с оригинальным URL.
+func (s *storage) GetOriginalURL(ctx context.Context, value string) (jsonobject.Item, error) {
 	tctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	sURL := ""
+	res := jsonobject.Item{ShortURL: value}
 	isDeleted := false
-	err := s.db.QueryRowContext(tctx, sqlGetOriginalURL, value).Scan(&sURL, &isDeleted)
+	var expiresAt sql.NullTime
+	var clicksLeft sql.NullInt32
+	var pwdHash sql.NullString
+	var createdAt, activeFrom sql.NullTime
+	var rules, variants []byte
+	err := s.db.QueryRowContext(tctx, sqlGetOriginalURL, value).Scan(&res.OriginalURL, &isDeleted, &expiresAt, &clicksLeft, &pwdHash,
+		&res.PassQuery, &res.PassPath, &res.RedirectType, &res.Title, &createdAt, &rules, &res.StickyVariant, &activeFrom, &variants)
 
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
-		return "", fmt.Errorf("no data found in db for value %s", value)
+		return jsonobject.Item{}, fmt.Errorf("no data found in db for value %s", value)
 	case err != nil:
-		return "", fmt.Errorf("dbstore.GetOriginalURL select: %w", err)
+		return jsonobject.Item{}, fmt.Errorf("dbstore.GetOriginalURL select: %w", err)
 	case isDeleted:
-		return "", ErrorDeletedURL
-	default:
-		return sURL, nil
+		return jsonobject.Item{}, ErrorDeletedURL
+	case expiresAt.Valid && !expiresAt.Time.After(time.Now()):
+		return jsonobject.Item{}, cutter.ErrorExpiredURL
+	case clicksLeft.Valid && clicksLeft.Int32 <= 0:
+		return jsonobject.Item{}, cutter.ErrorClicksExhausted
+	}
+	if expiresAt.Valid {
+		res.ExpiresAt = &expiresAt.Time
+	}
+	if createdAt.Valid {
+		res.CreatedAt = &createdAt.Time
+	}
+	if activeFrom.Valid {
+		res.ActiveFrom = &activeFrom.Time
+	}
+	res.PasswordHash = pwdHash.String
+	if clicksLeft.Valid {
+		clicks := int(clicksLeft.Int32)
+		res.ClicksLeft = &clicks
+	}
+	if res.Rules, err = rulesFromJSON(rules); err != nil {
+		return jsonobject.Item{}, fmt.Errorf("dbstore.GetOriginalURL: %w", err)
+	}
+	if res.Variants, err = variantsFromJSON(variants); err != nil {
+		return jsonobject.Item{}, fmt.Errorf("dbstore.GetOriginalURL: %w", err)
+	}
+	return res, nil
+}
+
+// UseClick атомарно списывает один переход у сокращения с ограничением переходов.
+func (s *storage) UseClick(ctx context.Context, short string) error {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var left int
+	err := s.db.QueryRowContext(tctx, sqlUseClick, short).Scan(&left)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return cutter.ErrorClicksExhausted
+	case err != nil:
+		return fmt.Errorf("dbstore.UseClick: %w", err)
 	}
+	return nil
 }
 
 // UploadBatch загружает слайс BatchItem в БД.
@@ -186,10 +376,15 @@ func (s *storage) UploadBatch(ctx context.Context, batch jsonobject.Batch) (json
 	defer stmtCheck.Close()
 	for i := 0; i < len(batch); i++ {
 		var dbOriginalURL string
-		err = stmtCheck.QueryRowContext(tctx, batch[i].OriginalURL).Scan(&dbOriginalURL)
+		err = stmtCheck.QueryRowContext(tctx, batch[i].OriginalURL, s.uniqScope(userID)).Scan(&dbOriginalURL)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			if _, err = stmtInsert.ExecContext(tctx, batch[i].ShortURL, batch[i].OriginalURL, userID); err != nil {
+			if _, err = stmtInsert.ExecContext(tctx,
+				s.insertArgs(batch[i].ShortURL, batch[i].OriginalURL, userID, batch[i].LinkOptions)...); err != nil {
+				tx.Rollback()
+				return batch, fmt.Errorf("batch insert: %w", checkShortTaken(err, batch[i].ShortURL))
+			}
+			if err = insertVariants(tctx, tx, batch[i].ShortURL, batch[i].Variants); err != nil {
 				tx.Rollback()
 				return batch, fmt.Errorf("batch insert: %w", err)
 			}
@@ -213,6 +408,7 @@ func (s *storage) UploadBatch(ctx context.Context, batch jsonobject.Batch) (json
 }
 
 // GetUserURLs получить все URL загруженные текущим пользователем.
+// Для сокращений с вариантами URL выдает варианты с количеством переходов на каждый.
 func (s *storage) GetUserURLs(ctx context.Context) (jsonobject.Batch, error) {
 	tctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -238,36 +434,599 @@ func (s *storage) GetUserURLs(ctx context.Context) (jsonobject.Batch, error) {
 	for rows.Next() {
 		var original string
 		var short string
-		err = rows.Scan(&short, &original)
+		var expiresAt, activeFrom sql.NullTime
+		var variants []byte
+		item := jsonobject.BatchItem{}
+		err = rows.Scan(&short, &original, &expiresAt, &activeFrom, &item.StickyVariant, &variants)
 		if err != nil {
 			return nil, fmt.Errorf("GetUserUrls, scan db results %w", err)
 		}
-		res = append(res, jsonobject.BatchItem{OriginalURL: original, ShortURL: short})
+		item.OriginalURL, item.ShortURL = original, short
+		if expiresAt.Valid {
+			item.ExpiresAt = &expiresAt.Time
+		}
+		if activeFrom.Valid {
+			item.ActiveFrom = &activeFrom.Time
+		}
+		if item.Variants, err = variantsFromJSON(variants); err != nil {
+			return nil, fmt.Errorf("GetUserUrls: %w", err)
+		}
+		res = append(res, item)
 	}
 	return res, nil
 }
 
+// PurgeDeleted окончательно удаляет записи, удаленные пользователями раньше before, вместе с их историей.
+// Удаляет пачками по purgeBatchSize, пока не останется подходящих записей или не будет отменен контекст.
+// Возвращает количество удаленных записей.
+func (s *storage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		n, err := s.purgeBatch(ctx, before)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("dbstore.PurgeDeleted: %w", err)
+		}
+		if n < purgeBatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+func (s *storage) purgeBatch(ctx context.Context, before time.Time) (int64, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var n int64
+	if err := s.db.QueryRowContext(tctx, sqlPurgeDeleted, before, purgeBatchSize).Scan(&n); err != nil {
+		return 0, fmt.Errorf("purge batch: %w", err)
+	}
+	return n, nil
+}
+
+// MarkExpired отмечает записи с истекшим сроком действия.
+// Возвращает количество отмеченных записей.
+func (s *storage) MarkExpired(ctx context.Context) (int64, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	res, err := s.db.ExecContext(tctx, sqlMarkExpired)
+	if err != nil {
+		return 0, fmt.Errorf("dbstore.MarkExpired: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("dbstore.MarkExpired: rows affected: %w", err)
+	}
+	return n, nil
+}
+
 // DeleteURLs удалить список URL.
-// URL должен принаждлежать переданному пользователю.
-func (s *storage) DeleteURLs(ctx context.Context, userID string, ids []string) error {
-	tx, err := s.db.BeginTx(ctx, nil)
+// URL должен принаждлежать пользователю, от имени которого удаляется.
+// Весь список удаляется одним запросом UPDATE ... FROM unnest.
+// Возвращает результат удаления каждого сокращения в порядке items.
+func (s *storage) DeleteURLs(ctx context.Context, items []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	shorts := make([]string, len(items))
+	authors := make([]string, len(items))
+	for i, item := range items {
+		shorts[i], authors[i] = item.Short, item.UserID
+	}
+	rows, err := s.db.QueryContext(tctx, sqlDeleteURLs, shorts, authors)
+	if err != nil {
+		return nil, fmt.Errorf("dbstore.DeleteURLs: %w", err)
+	}
+	defer rows.Close()
+	statuses := make(map[jsonobject.DeleteItem]jsonobject.DeleteStatus, len(items))
+	for rows.Next() {
+		var item jsonobject.DeleteItem
+		var deleted bool
+		var author sql.NullString
+		if err = rows.Scan(&item.Short, &item.UserID, &deleted, &author); err != nil {
+			return nil, fmt.Errorf("dbstore.DeleteURLs, scan db results %w", err)
+		}
+		switch {
+		case deleted:
+			statuses[item] = jsonobject.DeleteDone
+		case !author.Valid:
+			statuses[item] = jsonobject.DeleteNotFound
+		case author.String != item.UserID:
+			statuses[item] = jsonobject.DeleteNotOwned
+		default:
+			statuses[item] = jsonobject.DeleteSkipped
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("dbstore.DeleteURLs, rows: %w", err)
+	}
+	res := make([]jsonobject.DeleteStatus, len(items))
+	for i, item := range items {
+		res[i] = statuses[item]
+	}
+	return res, nil
+}
+
+// CreateDeleteJob сохраняет новую задачу удаления в таблицу delete_jobs.
+func (s *storage) CreateDeleteJob(ctx context.Context, job jsonobject.DeleteJob) error {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	codes, err := job.Codes.MarshalJSON()
 	if err != nil {
-		return fmt.Errorf("DeleteURLs, transation begin: %w", err)
+		return fmt.Errorf("dbstore.CreateDeleteJob, marshal codes: %w", err)
 	}
+	if job.Codes == nil {
+		codes = []byte("[]")
+	}
+	_, err = s.db.ExecContext(tctx, sqlInsertDeleteJob, job.ID, job.UserID, job.State, string(codes), job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("dbstore.CreateDeleteJob: %w", err)
+	}
+	return nil
+}
 
-	stmt, err := tx.PrepareContext(ctx, sqlMarkDelete)
+// UpdateDeleteJob сохраняет состояние задачи удаления.
+// Список сокращений задачи не меняется.
+func (s *storage) UpdateDeleteJob(ctx context.Context, job jsonobject.DeleteJob) error {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	notFound, err := shortIdsJSON(job.NotFound)
+	if err != nil {
+		return fmt.Errorf("dbstore.UpdateDeleteJob: %w", err)
+	}
+	notOwned, err := shortIdsJSON(job.NotOwned)
+	if err != nil {
+		return fmt.Errorf("dbstore.UpdateDeleteJob: %w", err)
+	}
+	res, err := s.db.ExecContext(tctx, sqlUpdateDeleteJob, job.ID, job.State, job.Deleted, notFound, notOwned, job.Error, job.UpdatedAt, job.Processed)
 	if err != nil {
-		return fmt.Errorf("DeleteURLs, prepare stmt: %w", err)
+		return fmt.Errorf("dbstore.UpdateDeleteJob: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("dbstore.UpdateDeleteJob: %w", cutter.ErrorJobNotFound)
 	}
+	return nil
+}
+
+// GetDeleteJob выдает задачу удаления по ID.
+func (s *storage) GetDeleteJob(ctx context.Context, id string) (jsonobject.DeleteJob, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	job, err := scanDeleteJob(s.db.QueryRowContext(tctx, sqlGetDeleteJob, id))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return jsonobject.DeleteJob{}, cutter.ErrorJobNotFound
+	case err != nil:
+		return jsonobject.DeleteJob{}, fmt.Errorf("dbstore.GetDeleteJob: %w", err)
+	}
+	return job, nil
+}
+
+// ClaimDeleteJob выдает самую раннюю задачу в очереди и переводит ее в состояние cutter.JobRunning.
+// Задачи, уже захваченные другим обработчиком, пропускаются (FOR UPDATE SKIP LOCKED).
+// Если очередь пуста - возвращает cutter.ErrorNoPendingJobs.
+func (s *storage) ClaimDeleteJob(ctx context.Context) (jsonobject.DeleteJob, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	job, err := scanDeleteJob(s.db.QueryRowContext(tctx, sqlClaimDeleteJob, cutter.JobPending, cutter.JobRunning))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return jsonobject.DeleteJob{}, cutter.ErrorNoPendingJobs
+	case err != nil:
+		return jsonobject.DeleteJob{}, fmt.Errorf("dbstore.ClaimDeleteJob: %w", err)
+	}
+	return job, nil
+}
+
+// RequeueDeleteJobs возвращает в очередь задачи, выполнение которых было прервано.
+// Возвращает количество таких задач.
+func (s *storage) RequeueDeleteJobs(ctx context.Context) (int64, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	res, err := s.db.ExecContext(tctx, sqlRequeueDeleteJobs, cutter.JobRunning, cutter.JobPending)
+	if err != nil {
+		return 0, fmt.Errorf("dbstore.RequeueDeleteJobs: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("dbstore.RequeueDeleteJobs: rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// scanDeleteJob читает задачу удаления из строки результата запроса.
+func scanDeleteJob(row *sql.Row) (jsonobject.DeleteJob, error) {
+	var job jsonobject.DeleteJob
+	var notFound, notOwned, codes []byte
+	err := row.Scan(&job.ID, &job.UserID, &job.State, &job.Deleted, &notFound, &notOwned,
+		&job.Error, &job.CreatedAt, &job.UpdatedAt, &codes, &job.Processed)
+	if err != nil {
+		return jsonobject.DeleteJob{}, err
+	}
+	for _, f := range []struct {
+		dst  *jsonobject.ShortIds
+		data []byte
+	}{{&job.NotFound, notFound}, {&job.NotOwned, notOwned}, {&job.Codes, codes}} {
+		if len(f.data) == 0 {
+			continue
+		}
+		if err = f.dst.UnmarshalJSON(f.data); err != nil {
+			return jsonobject.DeleteJob{}, fmt.Errorf("unmarshal short ids: %w", err)
+		}
+	}
+	return job, nil
+}
+
+// shortIdsJSON возвращает список сокращений в json: NULL для пустого списка.
+func shortIdsJSON(ids jsonobject.ShortIds) (sql.NullString, error) {
+	if len(ids) == 0 {
+		return sql.NullString{}, nil
+	}
+	b, err := ids.MarshalJSON()
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshal short ids: %w", err)
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// GetDeletedURLs выдает удаленные сокращения пользователя userID, начиная с удаленных последними.
+func (s *storage) GetDeletedURLs(ctx context.Context, userID string) (jsonobject.Batch, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	rows, err := s.db.QueryContext(tctx, sqlGetDeletedByAuthor, userID)
+	if err != nil {
+		return nil, fmt.Errorf("dbstore.GetDeletedURLs, QueryContext: %w", err)
+	}
+	defer rows.Close()
+	var res jsonobject.Batch
+	for rows.Next() {
+		var item jsonobject.BatchItem
+		var deletedAt sql.NullTime
+		if err = rows.Scan(&item.ShortURL, &item.OriginalURL, &deletedAt); err != nil {
+			return nil, fmt.Errorf("dbstore.GetDeletedURLs, scan db results %w", err)
+		}
+		if deletedAt.Valid {
+			item.DeletedAt = &deletedAt.Time
+		}
+		res = append(res, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("dbstore.GetDeletedURLs, rows: %w", err)
+	}
+	return res, nil
+}
+
+// RestoreURLs снимает отметку удаления с сокращений пользователя userID.
+// Чужие, не удаленные и несуществующие сокращения пропускаются.
+// Возвращает восстановленные сокращения.
+func (s *storage) RestoreURLs(ctx context.Context, userID string, ids []string) (jsonobject.ShortIds, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tx, err := s.db.BeginTx(tctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dbstore.RestoreURLs, transation begin: %w", err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(tctx, sqlRestoreURL)
+	if err != nil {
+		return nil, fmt.Errorf("dbstore.RestoreURLs, prepare stmt: %w", err)
+	}
+	defer stmt.Close()
+	var res jsonobject.ShortIds
 	for _, id := range ids {
-		if _, err = stmt.ExecContext(ctx, id, userID); err != nil && !errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("on url: %w", err)
+		var short string
+		err = stmt.QueryRowContext(tctx, id, userID).Scan(&short)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return nil, fmt.Errorf("dbstore.RestoreURLs, on url %s: %w", id, err)
+		default:
+			res = append(res, short)
 		}
 	}
 	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("dbstore.RestoreURLs, on transaction commit: %w", err)
+	}
+	return res, nil
+}
+
+// UpdateURL заменяет оригинальный URL сокращения пользователя userID.
+// Прежний URL сохраняется в таблицу urls_history.
+func (s *storage) UpdateURL(ctx context.Context, userID, short, original string) error {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tx, err := s.db.BeginTx(tctx, nil)
+	if err != nil {
+		return fmt.Errorf("dbstore.UpdateURL, transation begin: %w", err)
+	}
+	defer tx.Rollback()
+	if err = lockOwnURL(tctx, tx, userID, short); err != nil {
+		return fmt.Errorf("dbstore.UpdateURL: %w", err)
+	}
+	if _, err = tx.ExecContext(tctx, sqlInsertHistory, short); err != nil {
+		return fmt.Errorf("dbstore.UpdateURL, insert history: %w", err)
+	}
+	if _, err = tx.ExecContext(tctx, sqlUpdateURL, short, original); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("dbstore.UpdateURL, update: %w", s.checkUniqueURL(tctx, userID, original, err))
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("dbstore.UpdateURL, commit: %w", err)
+	}
+	return nil
+}
+
+// GetURLHistory выдает прежние URL сокращения пользователя userID в порядке замены.
+func (s *storage) GetURLHistory(ctx context.Context, userID, short string) (jsonobject.History, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var author string
+	err := s.db.QueryRowContext(tctx, sqlGetURLAuthor, short).Scan(&author)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, cutter.ErrorURLNotFound
+	case err != nil:
+		return nil, fmt.Errorf("dbstore.GetURLHistory, select author: %w", err)
+	case author != userID:
+		return nil, cutter.ErrorNotOwner
+	}
+
+	rows, err := s.db.QueryContext(tctx, sqlGetURLHistory, short)
+	if err != nil {
+		return nil, fmt.Errorf("dbstore.GetURLHistory, QueryContext: %w", err)
+	}
+	defer rows.Close()
+	var res jsonobject.History
+	for rows.Next() {
+		var h jsonobject.HistoryItem
+		if err = rows.Scan(&h.OriginalURL, &h.ChangedAt); err != nil {
+			return nil, fmt.Errorf("dbstore.GetURLHistory, scan db results %w", err)
+		}
+		res = append(res, h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("dbstore.GetURLHistory, rows: %w", err)
+	}
+	return res, nil
+}
+
+// AddClicks сохраняет переходы по сокращениям одним запросом.
+func (s *storage) AddClicks(ctx context.Context, events []jsonobject.ClickEvent) error {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	shorts := make([]string, len(events))
+	at := make([]time.Time, len(events))
+	referrers := make([]string, len(events))
+	agents := make([]string, len(events))
+	hashes := make([]string, len(events))
+	variants := make([]string, len(events))
+	for i, e := range events {
+		shorts[i], at[i], referrers[i], agents[i], hashes[i] = e.Short, e.At, e.Referrer, e.UserAgent, e.IPHash
+		variants[i] = e.Variant
+	}
+	if _, err := s.db.ExecContext(tctx, sqlInsertClicks, shorts, at, referrers, agents, hashes, variants); err != nil {
+		return fmt.Errorf("dbstore.AddClicks: %w", err)
+	}
+	return nil
+}
+
+// GetClickStats выдает статистику переходов по сокращению пользователя userID.
+// Браузеры и операционные системы не заполняются: вместо них выдаются переходы по User-Agent.
+func (s *storage) GetClickStats(ctx context.Context, userID, short string) (jsonobject.LinkStats, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var res jsonobject.LinkStats
+	var author string
+	err := s.db.QueryRowContext(tctx, sqlGetURLAuthor, short).Scan(&author)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return res, cutter.ErrorURLNotFound
+	case err != nil:
+		return res, fmt.Errorf("dbstore.GetClickStats, select author: %w", err)
+	case author != userID:
+		return res, cutter.ErrorNotOwner
+	}
+
+	if err = s.db.QueryRowContext(tctx, sqlGetClickTotals, short).Scan(&res.Total, &res.Unique); err != nil {
+		return res, fmt.Errorf("dbstore.GetClickStats, totals: %w", err)
+	}
+	daily, err := s.clickCounts(tctx, sqlGetClicksDaily, short)
+	if err != nil {
+		return res, fmt.Errorf("dbstore.GetClickStats, daily: %w", err)
+	}
+	for _, d := range daily {
+		res.Daily = append(res.Daily, jsonobject.DailyClicks{Date: d.Name, Clicks: d.Clicks})
+	}
+	if res.Referrers, err = s.clickCounts(tctx, sqlGetClickReferrers, short); err != nil {
+		return res, fmt.Errorf("dbstore.GetClickStats, referrers: %w", err)
+	}
+	if res.UserAgents, err = s.clickCounts(tctx, sqlGetClickUserAgents, short); err != nil {
+		return res, fmt.Errorf("dbstore.GetClickStats, user agents: %w", err)
+	}
+	if res.Variants, err = s.clickCounts(tctx, sqlGetClickVariants, short); err != nil {
+		return res, fmt.Errorf("dbstore.GetClickStats, variants: %w", err)
+	}
+	return res, nil
+}
+
+// CountClicks выдает количество переходов по сокращению short.
+func (s *storage) CountClicks(ctx context.Context, short string) (int64, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var res int64
+	if err := s.db.QueryRowContext(tctx, sqlCountClicks, short).Scan(&res); err != nil {
+		return 0, fmt.Errorf("dbstore.CountClicks: %w", err)
+	}
+	return res, nil
+}
+
+// clickCounts выполняет запрос, группирующий переходы по сокращению short.
+func (s *storage) clickCounts(ctx context.Context, query, short string) ([]jsonobject.ClickCount, error) {
+	rows, err := s.db.QueryContext(ctx, query, short)
+	if err != nil {
+		return nil, fmt.Errorf("QueryContext: %w", err)
+	}
+	defer rows.Close()
+	var res []jsonobject.ClickCount
+	for rows.Next() {
+		var c jsonobject.ClickCount
+		if err = rows.Scan(&c.Name, &c.Clicks); err != nil {
+			return nil, fmt.Errorf("scan db results %w", err)
+		}
+		res = append(res, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	return res, nil
+}
+
+// RollbackURL возвращает сокращению пользователя userID последний URL из истории.
+// Восстановленный URL удаляется из истории.
+func (s *storage) RollbackURL(ctx context.Context, userID, short string) (string, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tx, err := s.db.BeginTx(tctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("dbstore.RollbackURL, transation begin: %w", err)
+	}
+	defer tx.Rollback()
+	if err = lockOwnURL(tctx, tx, userID, short); err != nil {
+		return "", fmt.Errorf("dbstore.RollbackURL: %w", err)
+	}
+	var original string
+	err = tx.QueryRowContext(tctx, sqlPopHistory, short).Scan(&original)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", cutter.ErrorNoHistory
+	case err != nil:
+		return "", fmt.Errorf("dbstore.RollbackURL, pop history: %w", err)
+	}
+	if _, err = tx.ExecContext(tctx, sqlUpdateURL, short, original); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("DeleteURLs: on transaction commit: %w", err)
+		return "", fmt.Errorf("dbstore.RollbackURL, update: %w", s.checkUniqueURL(tctx, userID, original, err))
+	}
+	if err = tx.Commit(); err != nil {
+		return "", fmt.Errorf("dbstore.RollbackURL, commit: %w", err)
+	}
+	return original, nil
+}
+
+// GetRules выдает правила перехода сокращения пользователя userID.
+func (s *storage) GetRules(ctx context.Context, userID, short string) (jsonobject.Rules, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var author string
+	var rules []byte
+	var isDeleted bool
+	err := s.db.QueryRowContext(tctx, sqlGetURLRules, short).Scan(&author, &rules, &isDeleted)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, cutter.ErrorURLNotFound
+	case err != nil:
+		return nil, fmt.Errorf("dbstore.GetRules, select: %w", err)
+	case author != userID:
+		return nil, cutter.ErrorNotOwner
+	case isDeleted:
+		return nil, cutter.ErrorDeletedURL
+	}
+	res, err := rulesFromJSON(rules)
+	if err != nil {
+		return nil, fmt.Errorf("dbstore.GetRules: %w", err)
+	}
+	return res, nil
+}
+
+// UpdateRules заменяет правила перехода сокращения пользователя userID результатом change.
+// Строка сокращения блокируется до записи, поэтому изменения не теряются при одновременных запросах.
+func (s *storage) UpdateRules(ctx context.Context, userID, short string,
+	change func(jsonobject.Rules) (jsonobject.Rules, error)) (jsonobject.Rules, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tx, err := s.db.BeginTx(tctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dbstore.UpdateRules, transation begin: %w", err)
+	}
+	defer tx.Rollback()
+	if err = lockOwnURL(tctx, tx, userID, short); err != nil {
+		return nil, fmt.Errorf("dbstore.UpdateRules: %w", err)
+	}
+	var author string
+	var data []byte
+	var isDeleted bool // проверено в lockOwnURL
+	if err = tx.QueryRowContext(tctx, sqlGetURLRules, short).Scan(&author, &data, &isDeleted); err != nil {
+		return nil, fmt.Errorf("dbstore.UpdateRules, select: %w", err)
+	}
+	rules, err := rulesFromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("dbstore.UpdateRules: %w", err)
+	}
+	if rules, err = change(rules); err != nil {
+		return nil, fmt.Errorf("dbstore.UpdateRules: %w", err)
+	}
+	arg, err := rulesJSON(rules)
+	if err != nil {
+		return nil, fmt.Errorf("dbstore.UpdateRules: %w", err)
+	}
+	if _, err = tx.ExecContext(tctx, sqlUpdateURLRules, short, arg); err != nil {
+		return nil, fmt.Errorf("dbstore.UpdateRules, update: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("dbstore.UpdateRules, commit: %w", err)
+	}
+	return rules, nil
+}
+
+// rulesFromJSON читает правила перехода из json, NULL - правил нет.
+func rulesFromJSON(data []byte) (jsonobject.Rules, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var res jsonobject.Rules
+	if err := res.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("unmarshal rules: %w", err)
+	}
+	return res, nil
+}
+
+// rulesJSON возвращает правила перехода в json: NULL для пустого списка.
+func rulesJSON(rules jsonobject.Rules) (sql.NullString, error) {
+	if len(rules) == 0 {
+		return sql.NullString{}, nil
+	}
+	b, err := rules.MarshalJSON()
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshal rules: %w", err)
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+// lockOwnURL блокирует строку сокращения до конца транзакции и проверяет, что оно принадлежит userID.
+func lockOwnURL(ctx context.Context, tx *sql.Tx, userID, short string) error {
+	var author string
+	var isDeleted bool
+	err := tx.QueryRowContext(ctx, sqlLockURL, short).Scan(&author, &isDeleted)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return cutter.ErrorURLNotFound
+	case err != nil:
+		return fmt.Errorf("lock url: %w", err)
+	case author != userID:
+		return cutter.ErrorNotOwner
+	case isDeleted:
+		return ErrorDeletedURL
 	}
-	logging.Log.Infow("sucsess")
 	return nil
 }
+
+// checkUniqueURL заменяет ошибку уникальности URL на cutter.UniqueURLError с уже существующим сокращением.
+// Вызывается после отката транзакции. Остальные ошибки возвращаются без изменений.
+func (s *storage) checkUniqueURL(ctx context.Context, userID, original string, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.UniqueViolation {
+		return err
+	}
+	var existing string
+	if errGet := s.db.QueryRowContext(ctx, sqlGetShortURL, original, s.uniqScope(userID)).Scan(&existing); errGet != nil {
+		return errors.Join(err, errGet)
+	}
+	return cutter.NewUniqueURLError(existing, err)
+}
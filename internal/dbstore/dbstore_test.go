@@ -0,0 +1,135 @@
+package dbstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/storetest"
+)
+
+// benchDeleteSize количество удаляемых сокращений в одной итерации бенчмарка.
+const benchDeleteSize = 1000
+
+// sqlDeleteURLRow прежний способ удаления: один UPDATE на каждое сокращение.
+const sqlDeleteURLRow = `UPDATE PUBLIC.URLS SET DELETEDFLAG = TRUE, DELETED_AT = NOW()
+WHERE SHORT_URL = $1 and "authorId" = $2 and not DELETEDFLAG`
+
+type benchConf struct {
+	dsn string
+}
+
+func (c benchConf) GetFileStoreName() string { return "" }
+func (c benchConf) GetDBConnName() string    { return c.dsn }
+func (c benchConf) GetURLUniqueness() string { return "" }
+
+// deleteURLsPerRow удаляет сокращения по одному в рамках транзакции.
+func (s *storage) deleteURLsPerRow(ctx context.Context, items []jsonobject.DeleteItem) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, sqlDeleteURLRow)
+	if err != nil {
+		return fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+	for _, item := range items {
+		if _, err := stmt.ExecContext(ctx, item.Short, item.UserID); err != nil {
+			return fmt.Errorf("delete %s: %w", item.Short, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// seedDeleteURLs создает n ссылок для удаления от имени нескольких пользователей.
+func seedDeleteURLs(ctx context.Context, b *testing.B, s *storage, prefix string, n int) []jsonobject.DeleteItem {
+	b.Helper()
+	items := make([]jsonobject.DeleteItem, n)
+	for i := range items {
+		items[i] = jsonobject.DeleteItem{
+			UserID: fmt.Sprintf("bench-user-%d", i%10),
+			Short:  fmt.Sprintf("%s-%d", prefix, i),
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO PUBLIC.URLS (SHORT_URL, ORIGINAL_URL, "authorId", UNIQ_SCOPE)
+SELECT s, 'http://bench.example/' || s, a, a FROM unnest($1::text[], $2::text[]) AS t(s, a)`,
+		shortsOf(items), authorsOf(items)); err != nil {
+		b.Fatalf("seed urls: %v", err)
+	}
+	return items
+}
+
+func shortsOf(items []jsonobject.DeleteItem) []string {
+	res := make([]string, len(items))
+	for i, item := range items {
+		res[i] = item.Short
+	}
+	return res
+}
+
+func authorsOf(items []jsonobject.DeleteItem) []string {
+	res := make([]string, len(items))
+	for i, item := range items {
+		res[i] = item.UserID
+	}
+	return res
+}
+
+// TestUserURLsContract прогоняет общий сценарий storetest.UserURLs.
+// Запускается только при заданной переменной окружения TEST_DATABASE_DSN.
+func TestUserURLsContract(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	ctx := context.Background()
+	s, err := New(ctx, benchConf{dsn: dsn})
+	if err != nil {
+		t.Fatalf("init storage: %v", err)
+	}
+	defer s.CloseDB()
+	defer s.db.ExecContext(ctx, `DELETE FROM PUBLIC.URLS WHERE "authorId" LIKE 'storetest-%'`)
+	storetest.UserURLs(t, s)
+}
+
+// BenchmarkDeleteURLs сравнивает построчное удаление с одним UPDATE по unnest.
+// Запускается только при заданной переменной окружения TEST_DATABASE_DSN.
+func BenchmarkDeleteURLs(b *testing.B) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		b.Skip("TEST_DATABASE_DSN is not set")
+	}
+	ctx := context.Background()
+	s, err := New(ctx, benchConf{dsn: dsn})
+	if err != nil {
+		b.Fatalf("init storage: %v", err)
+	}
+	defer s.CloseDB()
+	defer s.db.ExecContext(ctx, `DELETE FROM PUBLIC.URLS WHERE SHORT_URL LIKE 'bench-%'`)
+
+	b.Run("per row", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			items := seedDeleteURLs(ctx, b, s, fmt.Sprintf("bench-row-%d", i), benchDeleteSize)
+			b.StartTimer()
+			if err := s.deleteURLsPerRow(ctx, items); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("unnest", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			items := seedDeleteURLs(ctx, b, s, fmt.Sprintf("bench-set-%d", i), benchDeleteSize)
+			b.StartTimer()
+			if _, err := s.DeleteURLs(ctx, items); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,10 @@
+UPDATE public.delete_jobs j
+SET state = $2, updated_at = now()
+WHERE j.id = (
+	SELECT q.id FROM public.delete_jobs q
+	WHERE q.state = $1
+	ORDER BY q.created_at, q.id
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING j.id, j."authorId", j.state, j.deleted, j.not_found, j.not_owned, coalesce(j.error, ''), j.created_at, j.updated_at, j.codes, j.processed
@@ -0,0 +1,6 @@
+select
+	count(*)
+from
+	url_clicks c
+where
+	c.short_url = $1
@@ -0,0 +1,15 @@
+WITH req AS (
+	SELECT DISTINCT r.short_url, r.author
+	FROM unnest($1::text[], $2::text[]) AS r(short_url, author)
+), upd AS (
+	UPDATE public.urls u
+	SET deletedflag = TRUE, deleted_at = now()
+	FROM req
+	WHERE u.short_url = req.short_url AND u."authorId" = req.author AND NOT u.deletedflag
+	RETURNING u.short_url, u."authorId"
+)
+SELECT
+	req.short_url, req.author, upd.short_url IS NOT NULL, u."authorId"
+FROM req
+LEFT JOIN upd ON upd.short_url = req.short_url AND upd."authorId" = req.author
+LEFT JOIN public.urls u ON u.short_url = req.short_url
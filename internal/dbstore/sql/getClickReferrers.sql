@@ -0,0 +1,7 @@
+select
+	c.referrer, count(*)
+from
+	url_clicks c
+where
+	c.short_url = $1
+group by c.referrer
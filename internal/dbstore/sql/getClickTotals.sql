@@ -0,0 +1,6 @@
+select
+	count(*), count(DISTINCT NULLIF(c.ip_hash, ''))
+from
+	url_clicks c
+where
+	c.short_url = $1
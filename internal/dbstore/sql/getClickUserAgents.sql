@@ -0,0 +1,7 @@
+select
+	c.user_agent, count(*)
+from
+	url_clicks c
+where
+	c.short_url = $1
+group by c.user_agent
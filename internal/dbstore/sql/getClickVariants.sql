@@ -0,0 +1,7 @@
+select
+	c.variant, count(*)
+from
+	url_clicks c
+where
+	c.short_url = $1 and c.variant <> ''
+group by c.variant
\ No newline at end of file
@@ -0,0 +1,8 @@
+select
+	to_char(c.clicked_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, count(*)
+from
+	url_clicks c
+where
+	c.short_url = $1
+group by day
+order by day
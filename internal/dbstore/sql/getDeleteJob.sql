@@ -0,0 +1,6 @@
+select
+	j.id, j."authorId", j.state, j.deleted, j.not_found, j.not_owned, coalesce(j.error, ''), j.created_at, j.updated_at, j.codes, j.processed
+from
+	delete_jobs j
+where
+	j.id = $1
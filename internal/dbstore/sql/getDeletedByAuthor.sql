@@ -0,0 +1 @@
+select u.short_url, u.original_url, u.deleted_at from public.urls u where u."authorId" = $1 and u.deletedflag order by u.deleted_at desc
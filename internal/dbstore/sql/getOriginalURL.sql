@@ -1,6 +1,10 @@
 select
-	u.original_url, u.deletedflag
+	u.original_url, u.deletedflag, u.expires_at, u.clicks_left, u.password_hash,
+	u.pass_query, u.pass_path, u.redirect_type, u.title, u.created_at,
+	u.rules, u.sticky_variant, u.active_from,
+	(select json_agg(json_build_object('name', v.name, 'url', v.url, 'weight', v.weight) order by v."position")
+		from url_variants v where v.short_url = u.short_url)
 from
 	urls u
 where
-	u.short_url = $1
\ No newline at end of file
+	u.short_url = $1
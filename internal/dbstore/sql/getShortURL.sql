@@ -3,4 +3,5 @@ select
 from
 	urls u
 where
-	u.original_url = $1
\ No newline at end of file
+	u.original_url = $1
+	and u.uniq_scope = $2
\ No newline at end of file
@@ -0,0 +1,7 @@
+select
+	h.original_url, h.changed_at
+from
+	urls_history h
+where
+	h.short_url = $1
+order by h.id
\ No newline at end of file
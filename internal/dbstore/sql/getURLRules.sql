@@ -0,0 +1,6 @@
+select
+	u."authorId", u.rules, u.deletedflag
+from
+	urls u
+where
+	u.short_url = $1
\ No newline at end of file
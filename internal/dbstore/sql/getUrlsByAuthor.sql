@@ -1 +1,6 @@
-select u.short_url, u.original_url from public.urls u where u."authorId" = $1
+select u.short_url, u.original_url, u.expires_at, u.active_from, u.sticky_variant,
+	(select json_agg(json_build_object('name', v.name, 'url', v.url, 'weight', v.weight,
+		'clicks', (select count(*) from url_clicks c where c.short_url = v.short_url and c.variant = v.name))
+		order by v."position")
+		from url_variants v where v.short_url = u.short_url)
+from public.urls u where u."authorId" = $1 and not u.expiredflag and not u.deletedflag
\ No newline at end of file
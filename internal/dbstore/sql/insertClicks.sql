@@ -0,0 +1,2 @@
+INSERT INTO public.url_clicks (short_url, clicked_at, referrer, user_agent, ip_hash, variant)
+SELECT * FROM unnest($1::text[], $2::timestamptz[], $3::text[], $4::text[], $5::text[], $6::text[])
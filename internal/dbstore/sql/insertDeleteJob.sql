@@ -0,0 +1,2 @@
+INSERT INTO public.delete_jobs (id, "authorId", state, codes, created_at, updated_at)
+VALUES ($1, $2, $3, $4::jsonb, $5, $6)
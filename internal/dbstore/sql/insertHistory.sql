@@ -0,0 +1,2 @@
+INSERT INTO PUBLIC.URLS_HISTORY (SHORT_URL, ORIGINAL_URL)
+SELECT SHORT_URL, ORIGINAL_URL FROM PUBLIC.URLS WHERE SHORT_URL = $1
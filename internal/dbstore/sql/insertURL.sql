@@ -1,2 +1,3 @@
-INSERT INTO PUBLIC.URLS (SHORT_URL, ORIGINAL_URL,  "authorId")
-VALUES($1, $2, $3)
+INSERT INTO PUBLIC.URLS (SHORT_URL, ORIGINAL_URL,  "authorId", EXPIRES_AT, CLICKS_LEFT, PASSWORD_HASH, UNIQ_SCOPE,
+	PASS_QUERY, PASS_PATH, REDIRECT_TYPE, TITLE, STICKY_VARIANT, ACTIVE_FROM)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
@@ -0,0 +1,2 @@
+INSERT INTO public.url_variants (short_url, "position", name, url, weight)
+SELECT $1, * FROM unnest($2::int[], $3::text[], $4::text[], $5::int[])
\ No newline at end of file
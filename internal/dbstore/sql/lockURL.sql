@@ -0,0 +1,7 @@
+select
+	u."authorId", u.deletedflag
+from
+	urls u
+where
+	u.short_url = $1
+for update
\ No newline at end of file
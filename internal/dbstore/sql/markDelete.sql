@@ -1,3 +0,0 @@
-UPDATE PUBLIC.URLS
-SET DELETEDFLAG = TRUE
-WHERE SHORT_URL = $1 and "authorId" = $2
@@ -0,0 +1,3 @@
+UPDATE PUBLIC.URLS
+SET EXPIREDFLAG = TRUE
+WHERE EXPIRES_AT <= NOW() and not EXPIREDFLAG
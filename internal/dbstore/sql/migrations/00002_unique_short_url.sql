@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+DROP INDEX IF EXISTS public.short_url;
+
+ALTER TABLE IF EXISTS public.urls
+    ADD CONSTRAINT urls_short_unique UNIQUE (short_url);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    DROP CONSTRAINT IF EXISTS urls_short_unique;
+
+CREATE INDEX IF NOT EXISTS short_url
+    ON public.urls USING btree
+    (short_url COLLATE pg_catalog."default" ASC NULLS LAST)
+    TABLESPACE pg_default;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE SEQUENCE IF NOT EXISTS public.urls_short_seq
+    AS bigint
+    INCREMENT 1
+    START 1
+    MINVALUE 1
+    CACHE 1;
+
+ALTER SEQUENCE public.urls_short_seq
+    OWNER TO postgres;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP SEQUENCE IF EXISTS public.urls_short_seq;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS expires_at timestamp with time zone,
+    ADD COLUMN IF NOT EXISTS expiredflag boolean NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS urls_expires_at
+    ON public.urls USING btree
+    (expires_at ASC NULLS LAST)
+    TABLESPACE pg_default
+    WHERE expires_at IS NOT NULL AND NOT expiredflag;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS public.urls_expires_at;
+
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS expiredflag,
+    DROP COLUMN IF EXISTS expires_at;
+-- +goose StatementEnd
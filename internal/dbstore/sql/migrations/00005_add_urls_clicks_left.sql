@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS clicks_left integer,
+    ADD CONSTRAINT urls_clicks_left_check CHECK (clicks_left >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    DROP CONSTRAINT IF EXISTS urls_clicks_left_check,
+    DROP COLUMN IF EXISTS clicks_left;
+-- +goose StatementEnd
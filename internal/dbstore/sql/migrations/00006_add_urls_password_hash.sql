@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS password_hash text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS password_hash;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- uniq_scope: пустая строка - URL уникален глобально, "authorId" - в пределах пользователя
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS uniq_scope text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    DROP CONSTRAINT IF EXISTS urls_original_unique;
+
+CREATE UNIQUE INDEX IF NOT EXISTS urls_original_scope_unique
+    ON public.urls USING btree
+    (original_url COLLATE pg_catalog."default" ASC NULLS LAST, uniq_scope COLLATE pg_catalog."default" ASC NULLS LAST)
+    TABLESPACE pg_default;
+
+-- индекс покрывается ведущим столбцом urls_original_scope_unique
+DROP INDEX IF EXISTS public.original_url;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS original_url
+    ON public.urls USING btree
+    (original_url COLLATE pg_catalog."default" ASC NULLS LAST)
+    TABLESPACE pg_default;
+
+DROP INDEX IF EXISTS public.urls_original_scope_unique;
+
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS uniq_scope,
+    ADD CONSTRAINT urls_original_unique UNIQUE (original_url);
+-- +goose StatementEnd
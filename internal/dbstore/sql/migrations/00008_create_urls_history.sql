@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS public.urls_history
+(
+    id bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+    short_url text COLLATE pg_catalog."default" NOT NULL,
+    original_url text COLLATE pg_catalog."default" NOT NULL,
+    changed_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT urls_history_pkey PRIMARY KEY (id)
+)
+
+TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS urls_history_short_url
+    ON public.urls_history USING btree
+    (short_url COLLATE pg_catalog."default" ASC NULLS LAST, id ASC)
+    TABLESPACE pg_default;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.urls_history;
+-- +goose StatementEnd
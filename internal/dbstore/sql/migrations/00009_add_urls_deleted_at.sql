@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+-- момент удаления ранее удаленных записей неизвестен, считаем их удаленными при миграции
+UPDATE public.urls SET deleted_at = now() WHERE deletedflag AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
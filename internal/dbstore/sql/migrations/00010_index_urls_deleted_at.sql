@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- индекс для окончательного удаления сокращений с истекшим сроком хранения
+CREATE INDEX IF NOT EXISTS urls_deleted_at
+    ON public.urls USING btree
+    (deleted_at ASC NULLS LAST)
+    TABLESPACE pg_default
+    WHERE deletedflag;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS public.urls_deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS public.delete_jobs
+(
+    id text COLLATE pg_catalog."default" NOT NULL,
+    "authorId" text COLLATE pg_catalog."default" NOT NULL,
+    state text COLLATE pg_catalog."default" NOT NULL,
+    deleted bigint NOT NULL DEFAULT 0,
+    not_found jsonb,
+    not_owned jsonb,
+    error text COLLATE pg_catalog."default",
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT delete_jobs_pkey PRIMARY KEY (id)
+)
+
+TABLESPACE pg_default;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.delete_jobs;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- codes: сокращения задачи, processed: количество обработанных, задача продолжается с этого места
+ALTER TABLE IF EXISTS public.delete_jobs
+    ADD COLUMN IF NOT EXISTS codes jsonb NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS processed integer NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS delete_jobs_queue
+    ON public.delete_jobs USING btree
+    (created_at ASC, id COLLATE pg_catalog."default" ASC)
+    TABLESPACE pg_default
+    WHERE state IN ('pending', 'running');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS public.delete_jobs_queue;
+
+ALTER TABLE IF EXISTS public.delete_jobs
+    DROP COLUMN IF EXISTS processed,
+    DROP COLUMN IF EXISTS codes;
+-- +goose StatementEnd
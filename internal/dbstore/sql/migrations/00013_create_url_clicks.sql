@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS public.url_clicks
+(
+    id bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+    short_url text COLLATE pg_catalog."default" NOT NULL,
+    clicked_at timestamptz NOT NULL DEFAULT now(),
+    referrer text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    user_agent text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    ip_hash text COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    CONSTRAINT url_clicks_pkey PRIMARY KEY (id)
+)
+
+TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS url_clicks_short_url
+    ON public.url_clicks USING btree
+    (short_url COLLATE pg_catalog."default" ASC NULLS LAST, clicked_at ASC)
+    TABLESPACE pg_default;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS public.url_clicks;
+-- +goose StatementEnd
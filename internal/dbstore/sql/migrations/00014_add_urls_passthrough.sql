@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- pass_query: режим передачи параметров запроса перехода (keep, override, append), пусто - не передаются
+-- pass_path: дописывать к URL путь после сокращения
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS pass_query text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS pass_path boolean NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS pass_path,
+    DROP COLUMN IF EXISTS pass_query;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- redirect_type: способ перехода (301, 302, 307, 308, meta-refresh, interstitial), пусто - способ по умолчанию сервиса
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS redirect_type text NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS redirect_type;
+-- +goose StatementEnd
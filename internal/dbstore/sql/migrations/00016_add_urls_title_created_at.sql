@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- title: название сокращения, заданное автором
+-- created_at: момент создания; у существующих записей остается NULL - момент их создания неизвестен
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS title text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS created_at timestamptz;
+ALTER TABLE IF EXISTS public.urls
+    ALTER COLUMN created_at SET DEFAULT now();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS title;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- rules: правила перехода в порядке проверки (JSON-массив jsonobject.Rule), NULL - правил нет
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS rules jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS rules;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- url_variants: варианты URL сокращения для A/B-теста, удаляются вместе с сокращением
+CREATE TABLE IF NOT EXISTS public.url_variants
+(
+    short_url text COLLATE pg_catalog."default" NOT NULL,
+    "position" integer NOT NULL,
+    name text COLLATE pg_catalog."default" NOT NULL,
+    url text COLLATE pg_catalog."default" NOT NULL,
+    weight integer NOT NULL,
+    CONSTRAINT url_variants_pkey PRIMARY KEY (short_url, "position"),
+    CONSTRAINT url_variants_name_unique UNIQUE (short_url, name),
+    CONSTRAINT url_variants_short_url_fkey FOREIGN KEY (short_url)
+        REFERENCES public.urls (short_url) ON DELETE CASCADE
+)
+
+TABLESPACE pg_default;
+
+-- sticky_variant: закреплять выбранный вариант за посетителем
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS sticky_variant boolean NOT NULL DEFAULT false;
+
+-- variant: вариант URL, на который выполнен переход, пусто - сокращение без вариантов
+ALTER TABLE IF EXISTS public.url_clicks
+    ADD COLUMN IF NOT EXISTS variant text COLLATE pg_catalog."default" NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.url_clicks
+    DROP COLUMN IF EXISTS variant;
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS sticky_variant;
+DROP TABLE IF EXISTS public.url_variants;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- active_from: момент начала работы сокращения, NULL - сокращение работает сразу
+ALTER TABLE IF EXISTS public.urls
+    ADD COLUMN IF NOT EXISTS active_from timestamp with time zone;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS public.urls
+    DROP COLUMN IF EXISTS active_from;
+-- +goose StatementEnd
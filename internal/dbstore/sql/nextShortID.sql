@@ -0,0 +1 @@
+select nextval('public.urls_short_seq')
@@ -0,0 +1,3 @@
+DELETE FROM PUBLIC.URLS_HISTORY
+WHERE ID = (SELECT ID FROM PUBLIC.URLS_HISTORY WHERE SHORT_URL = $1 ORDER BY ID DESC LIMIT 1)
+RETURNING ORIGINAL_URL
@@ -0,0 +1,17 @@
+WITH purged AS (
+	DELETE FROM public.urls
+	WHERE "ID" IN (
+		SELECT u."ID" FROM public.urls u
+		WHERE u.deletedflag AND u.deleted_at < $1
+		ORDER BY u."ID"
+		LIMIT $2
+	)
+	RETURNING short_url
+), history AS (
+	DELETE FROM public.urls_history h
+	WHERE h.short_url IN (SELECT short_url FROM purged)
+), clicks AS (
+	DELETE FROM public.url_clicks c
+	WHERE c.short_url IN (SELECT short_url FROM purged)
+)
+SELECT count(*) FROM purged
@@ -0,0 +1,3 @@
+UPDATE public.delete_jobs
+SET state = $2, updated_at = now()
+WHERE state = $1
@@ -0,0 +1,4 @@
+UPDATE PUBLIC.URLS
+SET DELETEDFLAG = FALSE, DELETED_AT = NULL
+WHERE SHORT_URL = $1 and "authorId" = $2 and DELETEDFLAG
+RETURNING SHORT_URL
@@ -0,0 +1,3 @@
+UPDATE public.delete_jobs
+SET state = $2, deleted = $3, not_found = $4::jsonb, not_owned = $5::jsonb, error = NULLIF($6, ''), updated_at = $7, processed = $8
+WHERE id = $1
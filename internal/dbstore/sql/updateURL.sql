@@ -0,0 +1,3 @@
+UPDATE PUBLIC.URLS
+SET ORIGINAL_URL = $2
+WHERE SHORT_URL = $1
@@ -0,0 +1,3 @@
+UPDATE PUBLIC.URLS
+SET RULES = $2
+WHERE SHORT_URL = $1
\ No newline at end of file
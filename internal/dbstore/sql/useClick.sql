@@ -0,0 +1,4 @@
+UPDATE PUBLIC.URLS
+SET CLICKS_LEFT = CLICKS_LEFT - 1
+WHERE SHORT_URL = $1 and CLICKS_LEFT > 0
+RETURNING CLICKS_LEFT
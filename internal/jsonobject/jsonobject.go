@@ -2,13 +2,142 @@
 // Objects processed to json using easyjson.
 package jsonobject
 
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Item запись о сокращении в хранилище - файле
+//
 //easyjson:json
 type Item struct {
+	// CreatedAt момент создания, nil - сокращение создано до появления даты создания
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// ActiveFrom момент начала работы сокращения, nil - сокращение работает сразу
+	ActiveFrom *time.Time `json:"active_from,omitempty"`
+	// DeletedAt момент удаления пользователем, nil - сокращение не удалено
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// ClicksLeft оставшееся количество переходов, nil - без ограничения
+	ClicksLeft  *int   `json:"clicks_left,omitempty"`
 	ShortURL    string `json:"short_url"`
 	OriginalURL string `json:"original_url"`
-	ID          int    `json:"uuid"`
+	// PasswordHash bcrypt-хэш пароля сокращения, пустой - сокращение без пароля
+	PasswordHash string `json:"password_hash,omitempty"`
+	// AuthorID ID пользователя, создавшего сокращение
+	AuthorID string `json:"author_id,omitempty"`
+	// PassQuery режим передачи параметров запроса перехода в URL, см. LinkOptions
+	PassQuery string `json:"pass_query,omitempty"`
+	// RedirectType способ перехода, см. LinkOptions; пусто - способ по умолчанию сервиса
+	RedirectType string `json:"redirect_type,omitempty"`
+	// Title название сокращения, заданное автором
+	Title string `json:"title,omitempty"`
+	// History прежние оригинальные URL, последний элемент - самый поздний
+	History History `json:"history,omitempty"`
+	// Rules правила перехода, проверяются по порядку, см. Rule
+	Rules Rules `json:"rules,omitempty"`
+	// Variants варианты URL для A/B-теста, см. LinkOptions
+	Variants Variants `json:"variants,omitempty"`
+	ID       int      `json:"uuid"`
+	// PassPath дописывать к URL путь после сокращения
+	PassPath bool `json:"pass_path,omitempty"`
+	// StickyVariant закреплять выбранный вариант за посетителем, см. LinkOptions
+	StickyVariant bool `json:"sticky_variant,omitempty"`
+	// Expired отмечает записи с истекшим сроком действия, не сохраняется в файл
+	Expired bool `json:"-"`
+}
+
+// Protected сообщает, что переход по сокращению требует пароль.
+func (i Item) Protected() bool {
+	return i.PasswordHash != ""
+}
+
+// Deleted сообщает, что сокращение удалено пользователем.
+func (i Item) Deleted() bool {
+	return i.DeletedAt != nil
+}
+
+// HistoryItem прежний оригинальный URL сокращения.
+//
+//easyjson:json
+type HistoryItem struct {
+	// Момент, когда URL был заменен
+	ChangedAt time.Time `json:"changed_at" example:"2024-06-01T00:00:00Z"`
+	// Прежний URL
+	OriginalURL string `json:"original_url" example:"http://ya.ru"`
+}
+
+// History содержит список прежних URL сокращения
+//
+//easyjson:json
+type History []HistoryItem
+
+// Rule правило перехода по сокращению: если выполнены все заданные условия,
+// переход выполняется на URL правила вместо оригинального URL сокращения.
+//
+//easyjson:json
+type Rule struct {
+	// Условие на заголовок запроса перехода
+	Header *HeaderMatch `json:"header,omitempty"`
+	// Условие на время перехода
+	Time *TimeWindow `json:"time,omitempty"`
+	// Адрес перехода
+	URL string `json:"url" example:"https://apps.apple.com/app/id1"`
+	// Языки, подходящие под наиболее предпочтительный язык клиента из Accept-Language:
+	// язык без региона (en) подходит и для всех его регионов (en-US, en-GB)
+	Languages []string `json:"languages,omitempty" example:"ru"`
+	// Классы устройства клиента по User-Agent: mobile, tablet, desktop или bot
+	Devices []string `json:"devices,omitempty" example:"mobile"`
 }
 
+// HeaderMatch условие на значение заголовка запроса перехода
+type HeaderMatch struct {
+	// Имя заголовка
+	Name string `json:"name" example:"X-Country"`
+	// Сравнение без учета регистра: equals, prefix, contains или exists - заголовок передан; пусто - equals
+	Op string `json:"op,omitempty" example:"equals"`
+	// Значение для сравнения
+	Value string `json:"value,omitempty" example:"RU"`
+}
+
+// TimeWindow условие на время перехода: все заданные ограничения должны выполняться
+type TimeWindow struct {
+	// Момент начала действия правила
+	NotBefore *time.Time `json:"not_before,omitempty" example:"2024-06-01T00:00:00Z"`
+	// Момент окончания действия правила
+	NotAfter *time.Time `json:"not_after,omitempty" example:"2024-07-01T00:00:00Z"`
+	// Ежедневный интервал HH:MM, начало включается, конец - нет; начало позже конца - интервал через полночь
+	From string `json:"from,omitempty" example:"09:00"`
+	To   string `json:"to,omitempty" example:"18:00"`
+	// Часовой пояс IANA для From, To и Weekdays, пусто - UTC
+	Location string `json:"location,omitempty" example:"Europe/Moscow"`
+	// Дни недели: mon, tue, wed, thu, fri, sat, sun
+	Weekdays []string `json:"weekdays,omitempty" example:"mon"`
+}
+
+// Variant вариант URL сокращения для A/B-теста
+type Variant struct {
+	// Название варианта, по умолчанию - буква по порядку: A, B, C...
+	Name string `json:"name,omitempty" example:"A"`
+	// Адрес перехода
+	URL string `json:"url" example:"https://ya.ru/landing-a"`
+	// Вес варианта: доля переходов на вариант равна его весу, деленному на сумму весов
+	Weight int `json:"weight" example:"70"`
+	// Количество переходов на вариант, заполняется в списке сокращений пользователя
+	Clicks int64 `json:"clicks" example:"10"`
+}
+
+// Variants содержит варианты URL сокращения
+//
+//easyjson:json
+type Variants []Variant
+
+// Rules содержит правила перехода по сокращению в порядке проверки
+//
+//easyjson:json
+type Rules []Rule
+
 // Batch содержит список из URL
 //
 //easyjson:json
@@ -21,6 +150,13 @@ type BatchItem struct {
 	OriginalURL string `json:"original_url,omitempty" example:"http://ya.ru"`
 	// Сокращенный URL
 	ShortURL string `json:"short_url,omitempty" example:"http://localhost:8080/rjhsha"`
+	// Момент удаления сокращения, заполняется только в корзине
+	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"2024-06-01T00:00:00Z"`
+	// Адрес QR-кода сокращенного URL, заполняется по запросу
+	QR string `json:"qr,omitempty" example:"http://localhost:8080/api/qr/rjhsha"`
+	LinkOptions
+	// Сокращение еще не работает: active_from не наступил
+	Scheduled bool `json:"scheduled,omitempty" example:"true"`
 }
 
 // Request содержит запрос с URL для сокращения
@@ -28,6 +164,45 @@ type BatchItem struct {
 //easyjson:json
 type Request struct {
 	URL string `json:"url" example:"http://ya.ru"`
+	LinkOptions
+}
+
+// LinkOptions содержит необязательные параметры создаваемого сокращения.
+// Встраивается в Request и BatchItem.
+type LinkOptions struct {
+	// Момент, после которого сокращение перестает работать
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2024-06-01T00:00:00Z"`
+	// Момент, до которого сокращение не работает: переход отвечает 404 или страницей "скоро"
+	ActiveFrom *time.Time `json:"active_from,omitempty" example:"2024-05-01T09:00:00Z"`
+	// Желаемое сокращение вместо сгенерированного
+	Alias string `json:"alias,omitempty" example:"spring-sale"`
+	// Домен сокращения из доменов сокращений сервиса. Пусто - домен запроса, если он из доменов сервиса,
+	// иначе домен по умолчанию
+	Domain string `json:"domain,omitempty" example:"go.brand.com"`
+	// Пароль для перехода по сокращению
+	Password string `json:"password,omitempty" example:"secret"`
+	// Название сокращения, показывается на странице предпросмотра
+	Title string `json:"title,omitempty" example:"Весенняя распродажа"`
+	// PasswordHash хэш пароля, заполняется сервисом и не принимается от клиента
+	PasswordHash string `json:"-" swaggerignore:"true"`
+	// Передача параметров запроса перехода в URL сокращения, если параметр уже есть в URL:
+	// keep - остается значение из URL, override - заменяется значением перехода, append - добавляется к нему.
+	// Пусто - параметры перехода не передаются
+	PassQuery string `json:"pass_query,omitempty" example:"keep"`
+	// Способ перехода: HTTP-редирект 301, 302, 307 или 308, HTML-страница meta-refresh или interstitial
+	// со ссылкой для перехода. Пусто - способ по умолчанию сервиса
+	RedirectType string `json:"redirect_type,omitempty" example:"301"`
+	// Варианты URL для A/B-теста: переход выполняется на один из вариантов, выбранный по весам.
+	// Пусто - переход на URL сокращения
+	Variants Variants `json:"variants,omitempty"`
+	// Срок действия сокращения в секундах, альтернатива ExpiresAt, не больше 100 лет
+	TTL int64 `json:"ttl,omitempty" example:"86400"`
+	// Количество переходов, после которого сокращение перестает работать, 0 - без ограничения
+	MaxClicks int `json:"max_clicks,omitempty" example:"1"`
+	// Дописывать к URL сокращения путь после сокращения: /abc/extra переходит на URL/extra
+	PassPath bool `json:"pass_path,omitempty" example:"true"`
+	// Закреплять выбранный вариант за посетителем через cookie, чтобы он не переключался между вариантами
+	StickyVariant bool `json:"sticky_variant,omitempty" example:"true"`
 }
 
 // Response содержит ответ с сокращенным URL
@@ -35,9 +210,169 @@ type Request struct {
 //easyjson:json
 type Response struct {
 	Result string `json:"result" example:"http://localhost:8080/rjhsha"`
+	// Адрес QR-кода сокращенного URL, заполняется по запросу
+	QR string `json:"qr,omitempty" example:"http://localhost:8080/api/qr/rjhsha"`
 }
 
 // ShortIds содержит список из сокращений
 //
 //easyjson:json
 type ShortIds []string
+
+// PurgeStats содержит статистику окончательного удаления сокращений
+//
+//easyjson:json
+type PurgeStats struct {
+	// Момент последнего запуска, отсутствует до первого запуска
+	LastRunAt *time.Time `json:"last_run_at,omitempty" example:"2024-06-01T00:00:00Z"`
+	// Количество сокращений, удаленных последним запуском
+	LastPurged int64 `json:"last_purged" example:"10"`
+	// Количество сокращений, удаленных с момента старта сервиса
+	TotalPurged int64 `json:"total_purged" example:"100"`
+}
+
+// DeleteResult содержит результат удаления списка сокращений
+type DeleteResult struct {
+	// Сокращения, которых нет в хранилище
+	NotFound ShortIds `json:"not_found,omitempty" example:"unknown"`
+	// Сокращения, созданные другими пользователями
+	NotOwned ShortIds `json:"not_owned,omitempty" example:"foreign"`
+	// Количество удаленных сокращений
+	Deleted int64 `json:"deleted" example:"2"`
+}
+
+// DeleteJob содержит задачу удаления сокращений пользователя
+//
+//easyjson:json
+type DeleteJob struct {
+	// Момент создания задачи
+	CreatedAt time.Time `json:"created_at" example:"2024-06-01T00:00:00Z"`
+	// Момент последнего изменения состояния
+	UpdatedAt time.Time `json:"updated_at" example:"2024-06-01T00:00:00Z"`
+	// ID задачи
+	ID string `json:"id" example:"0b6f4a3e-6c1e-4a8e-9d3b-4a1c2f0e7d5a"`
+	// UserID пользователь, создавший задачу
+	UserID string `json:"-"`
+	// Состояние: pending, running, done или failed
+	State string `json:"state" example:"done"`
+	// Ошибка, прервавшая задачу в состоянии failed
+	Error string `json:"error,omitempty" example:""`
+	// Codes сокращения для удаления
+	Codes ShortIds `json:"-"`
+	DeleteResult
+	// Количество обработанных сокращений
+	Processed int `json:"processed" example:"3"`
+}
+
+// DeleteJobEntry строка журнала задач удаления файлового хранилища.
+// Codes записываются только при создании задачи.
+//
+//easyjson:json
+type DeleteJobEntry struct {
+	UserID string    `json:"user_id"`
+	Codes  ShortIds  `json:"codes,omitempty"`
+	Job    DeleteJob `json:"job"`
+}
+
+// DeleteItem сокращение, удаляемое от имени пользователя
+type DeleteItem struct {
+	UserID string
+	Short  string
+}
+
+// DeleteStatus результат удаления одного сокращения
+type DeleteStatus int
+
+// Результаты удаления сокращения.
+const (
+	DeleteSkipped  DeleteStatus = iota // сокращение уже было удалено
+	DeleteDone                         // сокращение удалено
+	DeleteNotFound                     // сокращения нет в хранилище
+	DeleteNotOwned                     // сокращение создано другим пользователем
+)
+
+// ClickEvent содержит переход по сокращению
+//
+//easyjson:json
+type ClickEvent struct {
+	At        time.Time `json:"at"`
+	Short     string    `json:"short_url"`
+	Referrer  string    `json:"referrer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	// IPHash хэш IP клиента, сам IP не хранится
+	IPHash string `json:"ip_hash,omitempty"`
+	// Variant название варианта URL, на который выполнен переход, см. LinkOptions
+	Variant string `json:"variant,omitempty"`
+}
+
+// ClickCount количество переходов с одним значением признака
+type ClickCount struct {
+	Name   string `json:"name" example:"https://ya.ru/"`
+	Clicks int64  `json:"clicks" example:"10"`
+}
+
+// DailyClicks количество переходов за день (UTC)
+type DailyClicks struct {
+	Date   string `json:"date" example:"2024-06-01"`
+	Clicks int64  `json:"clicks" example:"10"`
+}
+
+// LinkStats содержит статистику переходов по сокращению
+//
+//easyjson:json
+type LinkStats struct {
+	// Переходы по дням, начиная с самого раннего
+	Daily []DailyClicks `json:"daily"`
+	// Самые частые источники перехода, прямые переходы - "(direct)"
+	Referrers []ClickCount `json:"referrers"`
+	// Переходы по браузерам
+	Browsers []ClickCount `json:"browsers"`
+	// Переходы по операционным системам
+	OS []ClickCount `json:"os"`
+	// Переходы по вариантам URL, только для сокращений с вариантами
+	Variants []ClickCount `json:"variants,omitempty"`
+	// UserAgents переходы по User-Agent, из них строятся Browsers и OS
+	UserAgents []ClickCount `json:"-"`
+	// Количество переходов
+	Total int64 `json:"total" example:"100"`
+	// Количество уникальных посетителей (по IP)
+	Unique int64 `json:"unique" example:"40"`
+}
+
+// Visit содержит параметры перехода по сокращению
+type Visit struct {
+	// Query параметры запроса перехода
+	Query url.Values
+	// Header заголовки запроса перехода, по ним проверяются правила перехода
+	Header http.Header
+	// Short сокращение
+	Short string
+	// Path путь после сокращения: для /abc/extra/path - extra/path
+	Path string
+	// Variant вариант URL, закрепленный за посетителем, см. LinkOptions
+	Variant string
+}
+
+// Target содержит результат перехода по сокращению
+type Target struct {
+	// URL адрес перехода с переданными путем и параметрами запроса
+	URL string
+	// RedirectType способ перехода, см. LinkOptions
+	RedirectType string
+	// Variant название выбранного варианта URL, пусто - сокращение без вариантов
+	Variant string
+	// Sticky выбранный вариант нужно закрепить за посетителем
+	Sticky bool
+}
+
+// Preview содержит данные страницы предпросмотра сокращения
+type Preview struct {
+	// CreatedAt момент создания, nil - сокращение создано до появления даты создания
+	CreatedAt *time.Time
+	// URL адрес перехода с переданными путем и параметрами запроса
+	URL string
+	// Title название сокращения, заданное автором
+	Title string
+	// Clicks количество переходов по сокращению
+	Clicks int64
+}
@@ -8,6 +8,7 @@ import (
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -18,7 +19,142 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject(in *jlexer.Lexer, out *ShortIds) {
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject(in *jlexer.Lexer, out *Variants) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Variants, 0, 1)
+			} else {
+				*out = Variants{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 Variant
+			easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject1(in, &v1)
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject(out *jwriter.Writer, in Variants) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject1(out, v3)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Variants) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Variants) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Variants) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Variants) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject1(in *jlexer.Lexer, out *Variant) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "url":
+			out.URL = string(in.String())
+		case "weight":
+			out.Weight = int(in.Int())
+		case "clicks":
+			out.Clicks = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject1(out *jwriter.Writer, in Variant) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Name != "" {
+		const prefix string = ",\"name\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"url\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.URL))
+	}
+	{
+		const prefix string = ",\"weight\":"
+		out.RawString(prefix)
+		out.Int(int(in.Weight))
+	}
+	{
+		const prefix string = ",\"clicks\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Clicks))
+	}
+	out.RawByte('}')
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject2(in *jlexer.Lexer, out *ShortIds) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -29,15 +165,1581 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject(in *jlexer.
 			if !in.IsDelim(']') {
 				*out = make(ShortIds, 0, 4)
 			} else {
-				*out = ShortIds{}
+				*out = ShortIds{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v4 string
+			v4 = string(in.String())
+			*out = append(*out, v4)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject2(out *jwriter.Writer, in ShortIds) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v5, v6 := range in {
+			if v5 > 0 {
+				out.RawByte(',')
+			}
+			out.String(string(v6))
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ShortIds) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ShortIds) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ShortIds) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ShortIds) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject2(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject3(in *jlexer.Lexer, out *Rules) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Rules, 0, 0)
+			} else {
+				*out = Rules{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v7 Rule
+			(v7).UnmarshalEasyJSON(in)
+			*out = append(*out, v7)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject3(out *jwriter.Writer, in Rules) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v8, v9 := range in {
+			if v8 > 0 {
+				out.RawByte(',')
+			}
+			(v9).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Rules) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Rules) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Rules) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Rules) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject3(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject4(in *jlexer.Lexer, out *Rule) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "header":
+			if in.IsNull() {
+				in.Skip()
+				out.Header = nil
+			} else {
+				if out.Header == nil {
+					out.Header = new(HeaderMatch)
+				}
+				easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject5(in, out.Header)
+			}
+		case "time":
+			if in.IsNull() {
+				in.Skip()
+				out.Time = nil
+			} else {
+				if out.Time == nil {
+					out.Time = new(TimeWindow)
+				}
+				easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject6(in, out.Time)
+			}
+		case "url":
+			out.URL = string(in.String())
+		case "languages":
+			if in.IsNull() {
+				in.Skip()
+				out.Languages = nil
+			} else {
+				in.Delim('[')
+				if out.Languages == nil {
+					if !in.IsDelim(']') {
+						out.Languages = make([]string, 0, 4)
+					} else {
+						out.Languages = []string{}
+					}
+				} else {
+					out.Languages = (out.Languages)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v10 string
+					v10 = string(in.String())
+					out.Languages = append(out.Languages, v10)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "devices":
+			if in.IsNull() {
+				in.Skip()
+				out.Devices = nil
+			} else {
+				in.Delim('[')
+				if out.Devices == nil {
+					if !in.IsDelim(']') {
+						out.Devices = make([]string, 0, 4)
+					} else {
+						out.Devices = []string{}
+					}
+				} else {
+					out.Devices = (out.Devices)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v11 string
+					v11 = string(in.String())
+					out.Devices = append(out.Devices, v11)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject4(out *jwriter.Writer, in Rule) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Header != nil {
+		const prefix string = ",\"header\":"
+		first = false
+		out.RawString(prefix[1:])
+		easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject5(out, *in.Header)
+	}
+	if in.Time != nil {
+		const prefix string = ",\"time\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject6(out, *in.Time)
+	}
+	{
+		const prefix string = ",\"url\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.URL))
+	}
+	if len(in.Languages) != 0 {
+		const prefix string = ",\"languages\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v12, v13 := range in.Languages {
+				if v12 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v13))
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Devices) != 0 {
+		const prefix string = ",\"devices\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v14, v15 := range in.Devices {
+				if v14 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v15))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Rule) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Rule) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Rule) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Rule) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject4(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject6(in *jlexer.Lexer, out *TimeWindow) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "not_before":
+			if in.IsNull() {
+				in.Skip()
+				out.NotBefore = nil
+			} else {
+				if out.NotBefore == nil {
+					out.NotBefore = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.NotBefore).UnmarshalJSON(data))
+				}
+			}
+		case "not_after":
+			if in.IsNull() {
+				in.Skip()
+				out.NotAfter = nil
+			} else {
+				if out.NotAfter == nil {
+					out.NotAfter = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.NotAfter).UnmarshalJSON(data))
+				}
+			}
+		case "from":
+			out.From = string(in.String())
+		case "to":
+			out.To = string(in.String())
+		case "location":
+			out.Location = string(in.String())
+		case "weekdays":
+			if in.IsNull() {
+				in.Skip()
+				out.Weekdays = nil
+			} else {
+				in.Delim('[')
+				if out.Weekdays == nil {
+					if !in.IsDelim(']') {
+						out.Weekdays = make([]string, 0, 4)
+					} else {
+						out.Weekdays = []string{}
+					}
+				} else {
+					out.Weekdays = (out.Weekdays)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v16 string
+					v16 = string(in.String())
+					out.Weekdays = append(out.Weekdays, v16)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject6(out *jwriter.Writer, in TimeWindow) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.NotBefore != nil {
+		const prefix string = ",\"not_before\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Raw((*in.NotBefore).MarshalJSON())
+	}
+	if in.NotAfter != nil {
+		const prefix string = ",\"not_after\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.NotAfter).MarshalJSON())
+	}
+	if in.From != "" {
+		const prefix string = ",\"from\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.From))
+	}
+	if in.To != "" {
+		const prefix string = ",\"to\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.To))
+	}
+	if in.Location != "" {
+		const prefix string = ",\"location\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Location))
+	}
+	if len(in.Weekdays) != 0 {
+		const prefix string = ",\"weekdays\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v17, v18 := range in.Weekdays {
+				if v17 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v18))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject5(in *jlexer.Lexer, out *HeaderMatch) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "op":
+			out.Op = string(in.String())
+		case "value":
+			out.Value = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject5(out *jwriter.Writer, in HeaderMatch) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	if in.Op != "" {
+		const prefix string = ",\"op\":"
+		out.RawString(prefix)
+		out.String(string(in.Op))
+	}
+	if in.Value != "" {
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.String(string(in.Value))
+	}
+	out.RawByte('}')
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject7(in *jlexer.Lexer, out *Response) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "result":
+			out.Result = string(in.String())
+		case "qr":
+			out.QR = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject7(out *jwriter.Writer, in Response) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"result\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Result))
+	}
+	if in.QR != "" {
+		const prefix string = ",\"qr\":"
+		out.RawString(prefix)
+		out.String(string(in.QR))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Response) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Response) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Response) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Response) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject7(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject8(in *jlexer.Lexer, out *Request) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "url":
+			out.URL = string(in.String())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "active_from":
+			if in.IsNull() {
+				in.Skip()
+				out.ActiveFrom = nil
+			} else {
+				if out.ActiveFrom == nil {
+					out.ActiveFrom = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ActiveFrom).UnmarshalJSON(data))
+				}
+			}
+		case "alias":
+			out.Alias = string(in.String())
+		case "domain":
+			out.Domain = string(in.String())
+		case "password":
+			out.Password = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "pass_query":
+			out.PassQuery = string(in.String())
+		case "redirect_type":
+			out.RedirectType = string(in.String())
+		case "variants":
+			(out.Variants).UnmarshalEasyJSON(in)
+		case "ttl":
+			out.TTL = int64(in.Int64())
+		case "max_clicks":
+			out.MaxClicks = int(in.Int())
+		case "pass_path":
+			out.PassPath = bool(in.Bool())
+		case "sticky_variant":
+			out.StickyVariant = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject8(out *jwriter.Writer, in Request) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"url\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.URL))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.ActiveFrom != nil {
+		const prefix string = ",\"active_from\":"
+		out.RawString(prefix)
+		out.Raw((*in.ActiveFrom).MarshalJSON())
+	}
+	if in.Alias != "" {
+		const prefix string = ",\"alias\":"
+		out.RawString(prefix)
+		out.String(string(in.Alias))
+	}
+	if in.Domain != "" {
+		const prefix string = ",\"domain\":"
+		out.RawString(prefix)
+		out.String(string(in.Domain))
+	}
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		out.RawString(prefix)
+		out.String(string(in.Password))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if in.PassQuery != "" {
+		const prefix string = ",\"pass_query\":"
+		out.RawString(prefix)
+		out.String(string(in.PassQuery))
+	}
+	if in.RedirectType != "" {
+		const prefix string = ",\"redirect_type\":"
+		out.RawString(prefix)
+		out.String(string(in.RedirectType))
+	}
+	if len(in.Variants) != 0 {
+		const prefix string = ",\"variants\":"
+		out.RawString(prefix)
+		(in.Variants).MarshalEasyJSON(out)
+	}
+	if in.TTL != 0 {
+		const prefix string = ",\"ttl\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.TTL))
+	}
+	if in.MaxClicks != 0 {
+		const prefix string = ",\"max_clicks\":"
+		out.RawString(prefix)
+		out.Int(int(in.MaxClicks))
+	}
+	if in.PassPath {
+		const prefix string = ",\"pass_path\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.PassPath))
+	}
+	if in.StickyVariant {
+		const prefix string = ",\"sticky_variant\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.StickyVariant))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Request) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject8(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Request) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject8(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Request) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject8(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Request) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject8(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject9(in *jlexer.Lexer, out *PurgeStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "last_run_at":
+			if in.IsNull() {
+				in.Skip()
+				out.LastRunAt = nil
+			} else {
+				if out.LastRunAt == nil {
+					out.LastRunAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.LastRunAt).UnmarshalJSON(data))
+				}
+			}
+		case "last_purged":
+			out.LastPurged = int64(in.Int64())
+		case "total_purged":
+			out.TotalPurged = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject9(out *jwriter.Writer, in PurgeStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.LastRunAt != nil {
+		const prefix string = ",\"last_run_at\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Raw((*in.LastRunAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"last_purged\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.LastPurged))
+	}
+	{
+		const prefix string = ",\"total_purged\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.TotalPurged))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PurgeStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject9(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PurgeStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject9(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PurgeStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject9(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PurgeStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject9(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject10(in *jlexer.Lexer, out *LinkStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "daily":
+			if in.IsNull() {
+				in.Skip()
+				out.Daily = nil
+			} else {
+				in.Delim('[')
+				if out.Daily == nil {
+					if !in.IsDelim(']') {
+						out.Daily = make([]DailyClicks, 0, 2)
+					} else {
+						out.Daily = []DailyClicks{}
+					}
+				} else {
+					out.Daily = (out.Daily)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v19 DailyClicks
+					easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject11(in, &v19)
+					out.Daily = append(out.Daily, v19)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "referrers":
+			if in.IsNull() {
+				in.Skip()
+				out.Referrers = nil
+			} else {
+				in.Delim('[')
+				if out.Referrers == nil {
+					if !in.IsDelim(']') {
+						out.Referrers = make([]ClickCount, 0, 2)
+					} else {
+						out.Referrers = []ClickCount{}
+					}
+				} else {
+					out.Referrers = (out.Referrers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v20 ClickCount
+					easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject12(in, &v20)
+					out.Referrers = append(out.Referrers, v20)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "browsers":
+			if in.IsNull() {
+				in.Skip()
+				out.Browsers = nil
+			} else {
+				in.Delim('[')
+				if out.Browsers == nil {
+					if !in.IsDelim(']') {
+						out.Browsers = make([]ClickCount, 0, 2)
+					} else {
+						out.Browsers = []ClickCount{}
+					}
+				} else {
+					out.Browsers = (out.Browsers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v21 ClickCount
+					easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject12(in, &v21)
+					out.Browsers = append(out.Browsers, v21)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "os":
+			if in.IsNull() {
+				in.Skip()
+				out.OS = nil
+			} else {
+				in.Delim('[')
+				if out.OS == nil {
+					if !in.IsDelim(']') {
+						out.OS = make([]ClickCount, 0, 2)
+					} else {
+						out.OS = []ClickCount{}
+					}
+				} else {
+					out.OS = (out.OS)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v22 ClickCount
+					easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject12(in, &v22)
+					out.OS = append(out.OS, v22)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "variants":
+			if in.IsNull() {
+				in.Skip()
+				out.Variants = nil
+			} else {
+				in.Delim('[')
+				if out.Variants == nil {
+					if !in.IsDelim(']') {
+						out.Variants = make([]ClickCount, 0, 2)
+					} else {
+						out.Variants = []ClickCount{}
+					}
+				} else {
+					out.Variants = (out.Variants)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v23 ClickCount
+					easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject12(in, &v23)
+					out.Variants = append(out.Variants, v23)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "total":
+			out.Total = int64(in.Int64())
+		case "unique":
+			out.Unique = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject10(out *jwriter.Writer, in LinkStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"daily\":"
+		out.RawString(prefix[1:])
+		if in.Daily == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v24, v25 := range in.Daily {
+				if v24 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject11(out, v25)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"referrers\":"
+		out.RawString(prefix)
+		if in.Referrers == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v26, v27 := range in.Referrers {
+				if v26 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject12(out, v27)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"browsers\":"
+		out.RawString(prefix)
+		if in.Browsers == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v28, v29 := range in.Browsers {
+				if v28 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject12(out, v29)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"os\":"
+		out.RawString(prefix)
+		if in.OS == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v30, v31 := range in.OS {
+				if v30 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject12(out, v31)
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Variants) != 0 {
+		const prefix string = ",\"variants\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v32, v33 := range in.Variants {
+				if v32 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject12(out, v33)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"total\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Total))
+	}
+	{
+		const prefix string = ",\"unique\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Unique))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v LinkStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject10(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v LinkStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject10(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *LinkStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject10(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *LinkStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject10(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject12(in *jlexer.Lexer, out *ClickCount) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "clicks":
+			out.Clicks = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject12(out *jwriter.Writer, in ClickCount) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"clicks\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Clicks))
+	}
+	out.RawByte('}')
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject11(in *jlexer.Lexer, out *DailyClicks) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "date":
+			out.Date = string(in.String())
+		case "clicks":
+			out.Clicks = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject11(out *jwriter.Writer, in DailyClicks) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"date\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Date))
+	}
+	{
+		const prefix string = ",\"clicks\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Clicks))
+	}
+	out.RawByte('}')
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject13(in *jlexer.Lexer, out *Item) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "created_at":
+			if in.IsNull() {
+				in.Skip()
+				out.CreatedAt = nil
+			} else {
+				if out.CreatedAt == nil {
+					out.CreatedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.CreatedAt).UnmarshalJSON(data))
+				}
+			}
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "active_from":
+			if in.IsNull() {
+				in.Skip()
+				out.ActiveFrom = nil
+			} else {
+				if out.ActiveFrom == nil {
+					out.ActiveFrom = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ActiveFrom).UnmarshalJSON(data))
+				}
+			}
+		case "deleted_at":
+			if in.IsNull() {
+				in.Skip()
+				out.DeletedAt = nil
+			} else {
+				if out.DeletedAt == nil {
+					out.DeletedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DeletedAt).UnmarshalJSON(data))
+				}
+			}
+		case "clicks_left":
+			if in.IsNull() {
+				in.Skip()
+				out.ClicksLeft = nil
+			} else {
+				if out.ClicksLeft == nil {
+					out.ClicksLeft = new(int)
+				}
+				*out.ClicksLeft = int(in.Int())
+			}
+		case "short_url":
+			out.ShortURL = string(in.String())
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		case "password_hash":
+			out.PasswordHash = string(in.String())
+		case "author_id":
+			out.AuthorID = string(in.String())
+		case "pass_query":
+			out.PassQuery = string(in.String())
+		case "redirect_type":
+			out.RedirectType = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "history":
+			(out.History).UnmarshalEasyJSON(in)
+		case "rules":
+			(out.Rules).UnmarshalEasyJSON(in)
+		case "variants":
+			(out.Variants).UnmarshalEasyJSON(in)
+		case "uuid":
+			out.ID = int(in.Int())
+		case "pass_path":
+			out.PassPath = bool(in.Bool())
+		case "sticky_variant":
+			out.StickyVariant = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject13(out *jwriter.Writer, in Item) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.CreatedAt != nil {
+		const prefix string = ",\"created_at\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Raw((*in.CreatedAt).MarshalJSON())
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.ActiveFrom != nil {
+		const prefix string = ",\"active_from\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.ActiveFrom).MarshalJSON())
+	}
+	if in.DeletedAt != nil {
+		const prefix string = ",\"deleted_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.DeletedAt).MarshalJSON())
+	}
+	if in.ClicksLeft != nil {
+		const prefix string = ",\"clicks_left\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(*in.ClicksLeft))
+	}
+	{
+		const prefix string = ",\"short_url\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.ShortURL))
+	}
+	{
+		const prefix string = ",\"original_url\":"
+		out.RawString(prefix)
+		out.String(string(in.OriginalURL))
+	}
+	if in.PasswordHash != "" {
+		const prefix string = ",\"password_hash\":"
+		out.RawString(prefix)
+		out.String(string(in.PasswordHash))
+	}
+	if in.AuthorID != "" {
+		const prefix string = ",\"author_id\":"
+		out.RawString(prefix)
+		out.String(string(in.AuthorID))
+	}
+	if in.PassQuery != "" {
+		const prefix string = ",\"pass_query\":"
+		out.RawString(prefix)
+		out.String(string(in.PassQuery))
+	}
+	if in.RedirectType != "" {
+		const prefix string = ",\"redirect_type\":"
+		out.RawString(prefix)
+		out.String(string(in.RedirectType))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	if len(in.History) != 0 {
+		const prefix string = ",\"history\":"
+		out.RawString(prefix)
+		(in.History).MarshalEasyJSON(out)
+	}
+	if len(in.Rules) != 0 {
+		const prefix string = ",\"rules\":"
+		out.RawString(prefix)
+		(in.Rules).MarshalEasyJSON(out)
+	}
+	if len(in.Variants) != 0 {
+		const prefix string = ",\"variants\":"
+		out.RawString(prefix)
+		(in.Variants).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"uuid\":"
+		out.RawString(prefix)
+		out.Int(int(in.ID))
+	}
+	if in.PassPath {
+		const prefix string = ",\"pass_path\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.PassPath))
+	}
+	if in.StickyVariant {
+		const prefix string = ",\"sticky_variant\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.StickyVariant))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Item) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject13(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Item) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject13(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Item) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject13(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Item) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject13(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject14(in *jlexer.Lexer, out *HistoryItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "changed_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ChangedAt).UnmarshalJSON(data))
+			}
+		case "original_url":
+			out.OriginalURL = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject14(out *jwriter.Writer, in HistoryItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"changed_at\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.ChangedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"original_url\":"
+		out.RawString(prefix)
+		out.String(string(in.OriginalURL))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HistoryItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject14(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HistoryItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject14(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HistoryItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject14(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HistoryItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject14(l, v)
+}
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject15(in *jlexer.Lexer, out *History) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(History, 0, 1)
+			} else {
+				*out = History{}
 			}
 		} else {
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v1 string
-			v1 = string(in.String())
-			*out = append(*out, v1)
+			var v34 HistoryItem
+			(v34).UnmarshalEasyJSON(in)
+			*out = append(*out, v34)
 			in.WantComma()
 		}
 		in.Delim(']')
@@ -46,45 +1748,45 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject(out *jwriter.Writer, in ShortIds) {
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject15(out *jwriter.Writer, in History) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
 		out.RawByte('[')
-		for v2, v3 := range in {
-			if v2 > 0 {
+		for v35, v36 := range in {
+			if v35 > 0 {
 				out.RawByte(',')
 			}
-			out.String(string(v3))
+			(v36).MarshalEasyJSON(out)
 		}
 		out.RawByte(']')
 	}
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v ShortIds) MarshalJSON() ([]byte, error) {
+func (v History) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject(&w, v)
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject15(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ShortIds) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject(w, v)
+func (v History) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject15(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *ShortIds) UnmarshalJSON(data []byte) error {
+func (v *History) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject(&r, v)
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject15(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ShortIds) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject(l, v)
+func (v *History) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject15(l, v)
 }
-func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject1(in *jlexer.Lexer, out *Response) {
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject16(in *jlexer.Lexer, out *DeleteJobEntry) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -103,8 +1805,12 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject1(in *jlexer
 			continue
 		}
 		switch key {
-		case "result":
-			out.Result = string(in.String())
+		case "user_id":
+			out.UserID = string(in.String())
+		case "codes":
+			(out.Codes).UnmarshalEasyJSON(in)
+		case "job":
+			(out.Job).UnmarshalEasyJSON(in)
 		default:
 			in.SkipRecursive()
 		}
@@ -115,42 +1821,52 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject1(in *jlexer
 		in.Consumed()
 	}
 }
-func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject1(out *jwriter.Writer, in Response) {
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject16(out *jwriter.Writer, in DeleteJobEntry) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"result\":"
+		const prefix string = ",\"user_id\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.Result))
+		out.String(string(in.UserID))
+	}
+	if len(in.Codes) != 0 {
+		const prefix string = ",\"codes\":"
+		out.RawString(prefix)
+		(in.Codes).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"job\":"
+		out.RawString(prefix)
+		(in.Job).MarshalEasyJSON(out)
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Response) MarshalJSON() ([]byte, error) {
+func (v DeleteJobEntry) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject1(&w, v)
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject16(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Response) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject1(w, v)
+func (v DeleteJobEntry) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject16(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Response) UnmarshalJSON(data []byte) error {
+func (v *DeleteJobEntry) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject1(&r, v)
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject16(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Response) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject1(l, v)
+func (v *DeleteJobEntry) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject16(l, v)
 }
-func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject2(in *jlexer.Lexer, out *Request) {
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject17(in *jlexer.Lexer, out *DeleteJob) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -169,8 +1885,28 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject2(in *jlexer
 			continue
 		}
 		switch key {
-		case "url":
-			out.URL = string(in.String())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		case "updated_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
+			}
+		case "id":
+			out.ID = string(in.String())
+		case "state":
+			out.State = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		case "processed":
+			out.Processed = int(in.Int())
+		case "not_found":
+			(out.NotFound).UnmarshalEasyJSON(in)
+		case "not_owned":
+			(out.NotOwned).UnmarshalEasyJSON(in)
+		case "deleted":
+			out.Deleted = int64(in.Int64())
 		default:
 			in.SkipRecursive()
 		}
@@ -181,42 +1917,82 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject2(in *jlexer
 		in.Consumed()
 	}
 }
-func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject2(out *jwriter.Writer, in Request) {
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject17(out *jwriter.Writer, in DeleteJob) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"url\":"
+		const prefix string = ",\"created_at\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.URL))
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"updated_at\":"
+		out.RawString(prefix)
+		out.Raw((in.UpdatedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix)
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	{
+		const prefix string = ",\"processed\":"
+		out.RawString(prefix)
+		out.Int(int(in.Processed))
+	}
+	if len(in.NotFound) != 0 {
+		const prefix string = ",\"not_found\":"
+		out.RawString(prefix)
+		(in.NotFound).MarshalEasyJSON(out)
+	}
+	if len(in.NotOwned) != 0 {
+		const prefix string = ",\"not_owned\":"
+		out.RawString(prefix)
+		(in.NotOwned).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"deleted\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Deleted))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Request) MarshalJSON() ([]byte, error) {
+func (v DeleteJob) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject2(&w, v)
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject17(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Request) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject2(w, v)
+func (v DeleteJob) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject17(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Request) UnmarshalJSON(data []byte) error {
+func (v *DeleteJob) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject2(&r, v)
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject17(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Request) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject2(l, v)
+func (v *DeleteJob) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject17(l, v)
 }
-func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject3(in *jlexer.Lexer, out *Item) {
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject18(in *jlexer.Lexer, out *ClickEvent) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -235,12 +2011,20 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject3(in *jlexer
 			continue
 		}
 		switch key {
-		case "uuid":
-			out.ID = int(in.Int())
+		case "at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.At).UnmarshalJSON(data))
+			}
 		case "short_url":
-			out.ShortURL = string(in.String())
-		case "original_url":
-			out.OriginalURL = string(in.String())
+			out.Short = string(in.String())
+		case "referrer":
+			out.Referrer = string(in.String())
+		case "user_agent":
+			out.UserAgent = string(in.String())
+		case "ip_hash":
+			out.IPHash = string(in.String())
+		case "variant":
+			out.Variant = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -251,52 +2035,67 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject3(in *jlexer
 		in.Consumed()
 	}
 }
-func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject3(out *jwriter.Writer, in Item) {
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject18(out *jwriter.Writer, in ClickEvent) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"uuid\":"
+		const prefix string = ",\"at\":"
 		out.RawString(prefix[1:])
-		out.Int(int(in.ID))
+		out.Raw((in.At).MarshalJSON())
 	}
 	{
 		const prefix string = ",\"short_url\":"
 		out.RawString(prefix)
-		out.String(string(in.ShortURL))
+		out.String(string(in.Short))
 	}
-	{
-		const prefix string = ",\"original_url\":"
+	if in.Referrer != "" {
+		const prefix string = ",\"referrer\":"
 		out.RawString(prefix)
-		out.String(string(in.OriginalURL))
+		out.String(string(in.Referrer))
+	}
+	if in.UserAgent != "" {
+		const prefix string = ",\"user_agent\":"
+		out.RawString(prefix)
+		out.String(string(in.UserAgent))
+	}
+	if in.IPHash != "" {
+		const prefix string = ",\"ip_hash\":"
+		out.RawString(prefix)
+		out.String(string(in.IPHash))
+	}
+	if in.Variant != "" {
+		const prefix string = ",\"variant\":"
+		out.RawString(prefix)
+		out.String(string(in.Variant))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Item) MarshalJSON() ([]byte, error) {
+func (v ClickEvent) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject3(&w, v)
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject18(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Item) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject3(w, v)
+func (v ClickEvent) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject18(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Item) UnmarshalJSON(data []byte) error {
+func (v *ClickEvent) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject3(&r, v)
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject18(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Item) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject3(l, v)
+func (v *ClickEvent) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject18(l, v)
 }
-func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject4(in *jlexer.Lexer, out *BatchItem) {
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject19(in *jlexer.Lexer, out *BatchItem) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -321,6 +2120,68 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject4(in *jlexer
 			out.OriginalURL = string(in.String())
 		case "short_url":
 			out.ShortURL = string(in.String())
+		case "deleted_at":
+			if in.IsNull() {
+				in.Skip()
+				out.DeletedAt = nil
+			} else {
+				if out.DeletedAt == nil {
+					out.DeletedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.DeletedAt).UnmarshalJSON(data))
+				}
+			}
+		case "qr":
+			out.QR = string(in.String())
+		case "scheduled":
+			out.Scheduled = bool(in.Bool())
+		case "expires_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ExpiresAt = nil
+			} else {
+				if out.ExpiresAt == nil {
+					out.ExpiresAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ExpiresAt).UnmarshalJSON(data))
+				}
+			}
+		case "active_from":
+			if in.IsNull() {
+				in.Skip()
+				out.ActiveFrom = nil
+			} else {
+				if out.ActiveFrom == nil {
+					out.ActiveFrom = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ActiveFrom).UnmarshalJSON(data))
+				}
+			}
+		case "alias":
+			out.Alias = string(in.String())
+		case "domain":
+			out.Domain = string(in.String())
+		case "password":
+			out.Password = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "pass_query":
+			out.PassQuery = string(in.String())
+		case "redirect_type":
+			out.RedirectType = string(in.String())
+		case "variants":
+			(out.Variants).UnmarshalEasyJSON(in)
+		case "ttl":
+			out.TTL = int64(in.Int64())
+		case "max_clicks":
+			out.MaxClicks = int(in.Int())
+		case "pass_path":
+			out.PassPath = bool(in.Bool())
+		case "sticky_variant":
+			out.StickyVariant = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -331,7 +2192,7 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject4(in *jlexer
 		in.Consumed()
 	}
 }
-func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject4(out *jwriter.Writer, in BatchItem) {
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject19(out *jwriter.Writer, in BatchItem) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -361,33 +2222,193 @@ func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject4(out *jwrit
 		}
 		out.String(string(in.ShortURL))
 	}
+	if in.DeletedAt != nil {
+		const prefix string = ",\"deleted_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.DeletedAt).MarshalJSON())
+	}
+	if in.QR != "" {
+		const prefix string = ",\"qr\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.QR))
+	}
+	if in.Scheduled {
+		const prefix string = ",\"scheduled\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.Scheduled))
+	}
+	if in.ExpiresAt != nil {
+		const prefix string = ",\"expires_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.ExpiresAt).MarshalJSON())
+	}
+	if in.ActiveFrom != nil {
+		const prefix string = ",\"active_from\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((*in.ActiveFrom).MarshalJSON())
+	}
+	if in.Alias != "" {
+		const prefix string = ",\"alias\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Alias))
+	}
+	if in.Domain != "" {
+		const prefix string = ",\"domain\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Domain))
+	}
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Password))
+	}
+	if in.Title != "" {
+		const prefix string = ",\"title\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Title))
+	}
+	if in.PassQuery != "" {
+		const prefix string = ",\"pass_query\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.PassQuery))
+	}
+	if in.RedirectType != "" {
+		const prefix string = ",\"redirect_type\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.RedirectType))
+	}
+	if len(in.Variants) != 0 {
+		const prefix string = ",\"variants\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Variants).MarshalEasyJSON(out)
+	}
+	if in.TTL != 0 {
+		const prefix string = ",\"ttl\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.TTL))
+	}
+	if in.MaxClicks != 0 {
+		const prefix string = ",\"max_clicks\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.MaxClicks))
+	}
+	if in.PassPath {
+		const prefix string = ",\"pass_path\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.PassPath))
+	}
+	if in.StickyVariant {
+		const prefix string = ",\"sticky_variant\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.StickyVariant))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v BatchItem) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject4(&w, v)
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject19(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v BatchItem) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject4(w, v)
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject19(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *BatchItem) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject4(&r, v)
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject19(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *BatchItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject4(l, v)
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject19(l, v)
 }
-func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject5(in *jlexer.Lexer, out *Batch) {
+func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject20(in *jlexer.Lexer, out *Batch) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -396,7 +2417,7 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject5(in *jlexer
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(Batch, 0, 1)
+				*out = make(Batch, 0, 0)
 			} else {
 				*out = Batch{}
 			}
@@ -404,9 +2425,9 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject5(in *jlexer
 			*out = (*out)[:0]
 		}
 		for !in.IsDelim(']') {
-			var v4 BatchItem
-			(v4).UnmarshalEasyJSON(in)
-			*out = append(*out, v4)
+			var v37 BatchItem
+			(v37).UnmarshalEasyJSON(in)
+			*out = append(*out, v37)
 			in.WantComma()
 		}
 		in.Delim(']')
@@ -415,16 +2436,16 @@ func easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject5(in *jlexer
 		in.Consumed()
 	}
 }
-func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject5(out *jwriter.Writer, in Batch) {
+func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject20(out *jwriter.Writer, in Batch) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
 		out.RawByte('[')
-		for v5, v6 := range in {
-			if v5 > 0 {
+		for v38, v39 := range in {
+			if v38 > 0 {
 				out.RawByte(',')
 			}
-			(v6).MarshalEasyJSON(out)
+			(v39).MarshalEasyJSON(out)
 		}
 		out.RawByte(']')
 	}
@@ -433,23 +2454,23 @@ func easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject5(out *jwrit
 // MarshalJSON supports json.Marshaler interface
 func (v Batch) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject5(&w, v)
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject20(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Batch) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject5(w, v)
+	easyjsonDfc1bcb3EncodeGithubComDmad1989UrlcutInternalJsonobject20(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Batch) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject5(&r, v)
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject20(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Batch) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject5(l, v)
+	easyjsonDfc1bcb3DecodeGithubComDmad1989UrlcutInternalJsonobject20(l, v)
 }
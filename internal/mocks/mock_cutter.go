@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 
@@ -37,17 +38,46 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 }
 
 // Add mocks base method.
-func (m *MockStore) Add(arg0 context.Context, arg1, arg2 string) error {
+func (m *MockStore) Add(arg0 context.Context, arg1, arg2 string, arg3 jsonobject.LinkOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Add", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Add", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Add indicates an expected call of Add.
-func (mr *MockStoreMockRecorder) Add(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) Add(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockStore)(nil).Add), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockStore)(nil).Add), arg0, arg1, arg2, arg3)
+}
+
+// AddClicks mocks base method.
+func (m *MockStore) AddClicks(arg0 context.Context, arg1 []jsonobject.ClickEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddClicks", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddClicks indicates an expected call of AddClicks.
+func (mr *MockStoreMockRecorder) AddClicks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddClicks", reflect.TypeOf((*MockStore)(nil).AddClicks), arg0, arg1)
+}
+
+// ClaimDeleteJob mocks base method.
+func (m *MockStore) ClaimDeleteJob(arg0 context.Context) (jsonobject.DeleteJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDeleteJob", arg0)
+	ret0, _ := ret[0].(jsonobject.DeleteJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDeleteJob indicates an expected call of ClaimDeleteJob.
+func (mr *MockStoreMockRecorder) ClaimDeleteJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDeleteJob", reflect.TypeOf((*MockStore)(nil).ClaimDeleteJob), arg0)
 }
 
 // CloseDB mocks base method.
@@ -64,25 +94,100 @@ func (mr *MockStoreMockRecorder) CloseDB() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseDB", reflect.TypeOf((*MockStore)(nil).CloseDB))
 }
 
-// DeleteURLs mocks base method.
-func (m *MockStore) DeleteURLs(arg0 context.Context, arg1 string, arg2 []string) error {
+// CountClicks mocks base method.
+func (m *MockStore) CountClicks(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountClicks", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountClicks indicates an expected call of CountClicks.
+func (mr *MockStoreMockRecorder) CountClicks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountClicks", reflect.TypeOf((*MockStore)(nil).CountClicks), arg0, arg1)
+}
+
+// CreateDeleteJob mocks base method.
+func (m *MockStore) CreateDeleteJob(arg0 context.Context, arg1 jsonobject.DeleteJob) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteURLs", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "CreateDeleteJob", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// CreateDeleteJob indicates an expected call of CreateDeleteJob.
+func (mr *MockStoreMockRecorder) CreateDeleteJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeleteJob", reflect.TypeOf((*MockStore)(nil).CreateDeleteJob), arg0, arg1)
+}
+
+// DeleteURLs mocks base method.
+func (m *MockStore) DeleteURLs(arg0 context.Context, arg1 []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteURLs", arg0, arg1)
+	ret0, _ := ret[0].([]jsonobject.DeleteStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // DeleteURLs indicates an expected call of DeleteURLs.
-func (mr *MockStoreMockRecorder) DeleteURLs(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) DeleteURLs(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteURLs", reflect.TypeOf((*MockStore)(nil).DeleteURLs), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteURLs", reflect.TypeOf((*MockStore)(nil).DeleteURLs), arg0, arg1)
+}
+
+// GetClickStats mocks base method.
+func (m *MockStore) GetClickStats(arg0 context.Context, arg1, arg2 string) (jsonobject.LinkStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClickStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.LinkStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClickStats indicates an expected call of GetClickStats.
+func (mr *MockStoreMockRecorder) GetClickStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClickStats", reflect.TypeOf((*MockStore)(nil).GetClickStats), arg0, arg1, arg2)
+}
+
+// GetDeleteJob mocks base method.
+func (m *MockStore) GetDeleteJob(arg0 context.Context, arg1 string) (jsonobject.DeleteJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeleteJob", arg0, arg1)
+	ret0, _ := ret[0].(jsonobject.DeleteJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeleteJob indicates an expected call of GetDeleteJob.
+func (mr *MockStoreMockRecorder) GetDeleteJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeleteJob", reflect.TypeOf((*MockStore)(nil).GetDeleteJob), arg0, arg1)
+}
+
+// GetDeletedURLs mocks base method.
+func (m *MockStore) GetDeletedURLs(arg0 context.Context, arg1 string) (jsonobject.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedURLs", arg0, arg1)
+	ret0, _ := ret[0].(jsonobject.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedURLs indicates an expected call of GetDeletedURLs.
+func (mr *MockStoreMockRecorder) GetDeletedURLs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedURLs", reflect.TypeOf((*MockStore)(nil).GetDeletedURLs), arg0, arg1)
 }
 
 // GetOriginalURL mocks base method.
-func (m *MockStore) GetOriginalURL(arg0 context.Context, arg1 string) (string, error) {
+func (m *MockStore) GetOriginalURL(arg0 context.Context, arg1 string) (jsonobject.Item, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetOriginalURL", arg0, arg1)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(jsonobject.Item)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -93,6 +198,21 @@ func (mr *MockStoreMockRecorder) GetOriginalURL(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalURL", reflect.TypeOf((*MockStore)(nil).GetOriginalURL), arg0, arg1)
 }
 
+// GetRules mocks base method.
+func (m *MockStore) GetRules(arg0 context.Context, arg1, arg2 string) (jsonobject.Rules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRules", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.Rules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRules indicates an expected call of GetRules.
+func (mr *MockStoreMockRecorder) GetRules(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRules", reflect.TypeOf((*MockStore)(nil).GetRules), arg0, arg1, arg2)
+}
+
 // GetShortURL mocks base method.
 func (m *MockStore) GetShortURL(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +228,21 @@ func (mr *MockStoreMockRecorder) GetShortURL(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShortURL", reflect.TypeOf((*MockStore)(nil).GetShortURL), arg0, arg1)
 }
 
+// GetURLHistory mocks base method.
+func (m *MockStore) GetURLHistory(arg0 context.Context, arg1, arg2 string) (jsonobject.History, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.History)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLHistory indicates an expected call of GetURLHistory.
+func (mr *MockStoreMockRecorder) GetURLHistory(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLHistory", reflect.TypeOf((*MockStore)(nil).GetURLHistory), arg0, arg1, arg2)
+}
+
 // GetUserURLs mocks base method.
 func (m *MockStore) GetUserURLs(arg0 context.Context) (jsonobject.Batch, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +258,36 @@ func (mr *MockStoreMockRecorder) GetUserURLs(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLs", reflect.TypeOf((*MockStore)(nil).GetUserURLs), arg0)
 }
 
+// MarkExpired mocks base method.
+func (m *MockStore) MarkExpired(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkExpired", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkExpired indicates an expected call of MarkExpired.
+func (mr *MockStoreMockRecorder) MarkExpired(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkExpired", reflect.TypeOf((*MockStore)(nil).MarkExpired), arg0)
+}
+
+// NextID mocks base method.
+func (m *MockStore) NextID(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextID", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextID indicates an expected call of NextID.
+func (mr *MockStoreMockRecorder) NextID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextID", reflect.TypeOf((*MockStore)(nil).NextID), arg0)
+}
+
 // Ping mocks base method.
 func (m *MockStore) Ping(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -137,6 +302,109 @@ func (mr *MockStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
 }
 
+// PurgeDeleted mocks base method.
+func (m *MockStore) PurgeDeleted(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockStoreMockRecorder) PurgeDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockStore)(nil).PurgeDeleted), arg0, arg1)
+}
+
+// RequeueDeleteJobs mocks base method.
+func (m *MockStore) RequeueDeleteJobs(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDeleteJobs", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueDeleteJobs indicates an expected call of RequeueDeleteJobs.
+func (mr *MockStoreMockRecorder) RequeueDeleteJobs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDeleteJobs", reflect.TypeOf((*MockStore)(nil).RequeueDeleteJobs), arg0)
+}
+
+// RestoreURLs mocks base method.
+func (m *MockStore) RestoreURLs(arg0 context.Context, arg1 string, arg2 []string) (jsonobject.ShortIds, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreURLs", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.ShortIds)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreURLs indicates an expected call of RestoreURLs.
+func (mr *MockStoreMockRecorder) RestoreURLs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreURLs", reflect.TypeOf((*MockStore)(nil).RestoreURLs), arg0, arg1, arg2)
+}
+
+// RollbackURL mocks base method.
+func (m *MockStore) RollbackURL(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackURL", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RollbackURL indicates an expected call of RollbackURL.
+func (mr *MockStoreMockRecorder) RollbackURL(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackURL", reflect.TypeOf((*MockStore)(nil).RollbackURL), arg0, arg1, arg2)
+}
+
+// UpdateDeleteJob mocks base method.
+func (m *MockStore) UpdateDeleteJob(arg0 context.Context, arg1 jsonobject.DeleteJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeleteJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDeleteJob indicates an expected call of UpdateDeleteJob.
+func (mr *MockStoreMockRecorder) UpdateDeleteJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeleteJob", reflect.TypeOf((*MockStore)(nil).UpdateDeleteJob), arg0, arg1)
+}
+
+// UpdateRules mocks base method.
+func (m *MockStore) UpdateRules(arg0 context.Context, arg1, arg2 string, arg3 func(jsonobject.Rules) (jsonobject.Rules, error)) (jsonobject.Rules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRules", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(jsonobject.Rules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRules indicates an expected call of UpdateRules.
+func (mr *MockStoreMockRecorder) UpdateRules(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRules", reflect.TypeOf((*MockStore)(nil).UpdateRules), arg0, arg1, arg2, arg3)
+}
+
+// UpdateURL mocks base method.
+func (m *MockStore) UpdateURL(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateURL", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateURL indicates an expected call of UpdateURL.
+func (mr *MockStoreMockRecorder) UpdateURL(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateURL", reflect.TypeOf((*MockStore)(nil).UpdateURL), arg0, arg1, arg2, arg3)
+}
+
 // UploadBatch mocks base method.
 func (m *MockStore) UploadBatch(arg0 context.Context, arg1 jsonobject.Batch) (jsonobject.Batch, error) {
 	m.ctrl.T.Helper()
@@ -151,3 +419,17 @@ func (mr *MockStoreMockRecorder) UploadBatch(arg0, arg1 interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadBatch", reflect.TypeOf((*MockStore)(nil).UploadBatch), arg0, arg1)
 }
+
+// UseClick mocks base method.
+func (m *MockStore) UseClick(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseClick", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseClick indicates an expected call of UseClick.
+func (mr *MockStoreMockRecorder) UseClick(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseClick", reflect.TypeOf((*MockStore)(nil).UseClick), arg0, arg1)
+}
@@ -35,6 +35,20 @@ func (m *MockConfiger) EXPECT() *MockConfigerMockRecorder {
 	return m.recorder
 }
 
+// GetComingSoonPage mocks base method.
+func (m *MockConfiger) GetComingSoonPage() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComingSoonPage")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetComingSoonPage indicates an expected call of GetComingSoonPage.
+func (mr *MockConfigerMockRecorder) GetComingSoonPage() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComingSoonPage", reflect.TypeOf((*MockConfiger)(nil).GetComingSoonPage))
+}
+
 // GetEnableHTTPS mocks base method.
 func (m *MockConfiger) GetEnableHTTPS() bool {
 	m.ctrl.T.Helper()
@@ -63,6 +77,48 @@ func (mr *MockConfigerMockRecorder) GetShortAddress() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShortAddress", reflect.TypeOf((*MockConfiger)(nil).GetShortAddress))
 }
 
+// GetShortDomains mocks base method.
+func (m *MockConfiger) GetShortDomains() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShortDomains")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetShortDomains indicates an expected call of GetShortDomains.
+func (mr *MockConfigerMockRecorder) GetShortDomains() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShortDomains", reflect.TypeOf((*MockConfiger)(nil).GetShortDomains))
+}
+
+// GetTrustedProxies mocks base method.
+func (m *MockConfiger) GetTrustedProxies() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrustedProxies")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetTrustedProxies indicates an expected call of GetTrustedProxies.
+func (mr *MockConfigerMockRecorder) GetTrustedProxies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrustedProxies", reflect.TypeOf((*MockConfiger)(nil).GetTrustedProxies))
+}
+
+// GetTrustedSubnet mocks base method.
+func (m *MockConfiger) GetTrustedSubnet() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrustedSubnet")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetTrustedSubnet indicates an expected call of GetTrustedSubnet.
+func (mr *MockConfigerMockRecorder) GetTrustedSubnet() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrustedSubnet", reflect.TypeOf((*MockConfiger)(nil).GetTrustedSubnet))
+}
+
 // GetURL mocks base method.
 func (m *MockConfiger) GetURL() string {
 	m.ctrl.T.Helper()
@@ -100,46 +156,93 @@ func (m *MockICutter) EXPECT() *MockICutterMockRecorder {
 	return m.recorder
 }
 
+// AddRule mocks base method.
+func (m *MockICutter) AddRule(arg0 context.Context, arg1, arg2 string, arg3 jsonobject.Rule) (jsonobject.Rules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRule", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(jsonobject.Rules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddRule indicates an expected call of AddRule.
+func (mr *MockICutterMockRecorder) AddRule(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRule", reflect.TypeOf((*MockICutter)(nil).AddRule), arg0, arg1, arg2, arg3)
+}
+
+// CheckLink mocks base method.
+func (m *MockICutter) CheckLink(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckLink", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckLink indicates an expected call of CheckLink.
+func (mr *MockICutterMockRecorder) CheckLink(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLink", reflect.TypeOf((*MockICutter)(nil).CheckLink), arg0, arg1)
+}
+
 // Cut mocks base method.
-func (m *MockICutter) Cut(arg0 context.Context, arg1 string) (string, error) {
+func (m *MockICutter) Cut(arg0 context.Context, arg1 string, arg2 jsonobject.LinkOptions) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Cut", arg0, arg1)
+	ret := m.ctrl.Call(m, "Cut", arg0, arg1, arg2)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Cut indicates an expected call of Cut.
-func (mr *MockICutterMockRecorder) Cut(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockICutterMockRecorder) Cut(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cut", reflect.TypeOf((*MockICutter)(nil).Cut), arg0, arg1, arg2)
+}
+
+// DeleteRule mocks base method.
+func (m *MockICutter) DeleteRule(arg0 context.Context, arg1, arg2 string, arg3 int) (jsonobject.Rules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRule", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(jsonobject.Rules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRule indicates an expected call of DeleteRule.
+func (mr *MockICutterMockRecorder) DeleteRule(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cut", reflect.TypeOf((*MockICutter)(nil).Cut), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRule", reflect.TypeOf((*MockICutter)(nil).DeleteRule), arg0, arg1, arg2, arg3)
 }
 
 // DeleteUrls mocks base method.
-func (m *MockICutter) DeleteUrls(arg0 string, arg1 jsonobject.ShortIds) {
+func (m *MockICutter) DeleteUrls(arg0 context.Context, arg1 string, arg2 jsonobject.ShortIds) (jsonobject.DeleteJob, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "DeleteUrls", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteUrls", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.DeleteJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteUrls indicates an expected call of DeleteUrls.
-func (mr *MockICutterMockRecorder) DeleteUrls(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockICutterMockRecorder) DeleteUrls(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUrls", reflect.TypeOf((*MockICutter)(nil).DeleteUrls), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUrls", reflect.TypeOf((*MockICutter)(nil).DeleteUrls), arg0, arg1, arg2)
 }
 
-// GetKeyByValue mocks base method.
-func (m *MockICutter) GetKeyByValue(arg0 context.Context, arg1 string) (string, error) {
+// GetDeleteJob mocks base method.
+func (m *MockICutter) GetDeleteJob(arg0 context.Context, arg1, arg2 string) (jsonobject.DeleteJob, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetKeyByValue", arg0, arg1)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetDeleteJob", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.DeleteJob)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetKeyByValue indicates an expected call of GetKeyByValue.
-func (mr *MockICutterMockRecorder) GetKeyByValue(arg0, arg1 interface{}) *gomock.Call {
+// GetDeleteJob indicates an expected call of GetDeleteJob.
+func (mr *MockICutterMockRecorder) GetDeleteJob(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyByValue", reflect.TypeOf((*MockICutter)(nil).GetKeyByValue), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeleteJob", reflect.TypeOf((*MockICutter)(nil).GetDeleteJob), arg0, arg1, arg2)
 }
 
 // GetUserURLs mocks base method.
@@ -157,6 +260,36 @@ func (mr *MockICutterMockRecorder) GetUserURLs(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLs", reflect.TypeOf((*MockICutter)(nil).GetUserURLs), arg0)
 }
 
+// History mocks base method.
+func (m *MockICutter) History(arg0 context.Context, arg1, arg2 string) (jsonobject.History, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.History)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockICutterMockRecorder) History(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockICutter)(nil).History), arg0, arg1, arg2)
+}
+
+// LinkStats mocks base method.
+func (m *MockICutter) LinkStats(arg0 context.Context, arg1, arg2 string) (jsonobject.LinkStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.LinkStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LinkStats indicates an expected call of LinkStats.
+func (mr *MockICutterMockRecorder) LinkStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkStats", reflect.TypeOf((*MockICutter)(nil).LinkStats), arg0, arg1, arg2)
+}
+
 // PingDB mocks base method.
 func (m *MockICutter) PingDB(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -171,6 +304,182 @@ func (mr *MockICutterMockRecorder) PingDB(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PingDB", reflect.TypeOf((*MockICutter)(nil).PingDB), arg0)
 }
 
+// Preview mocks base method.
+func (m *MockICutter) Preview(arg0 context.Context, arg1 jsonobject.Visit) (jsonobject.Preview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preview", arg0, arg1)
+	ret0, _ := ret[0].(jsonobject.Preview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preview indicates an expected call of Preview.
+func (mr *MockICutterMockRecorder) Preview(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preview", reflect.TypeOf((*MockICutter)(nil).Preview), arg0, arg1)
+}
+
+// PurgeStats mocks base method.
+func (m *MockICutter) PurgeStats() jsonobject.PurgeStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeStats")
+	ret0, _ := ret[0].(jsonobject.PurgeStats)
+	return ret0
+}
+
+// PurgeStats indicates an expected call of PurgeStats.
+func (mr *MockICutterMockRecorder) PurgeStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeStats", reflect.TypeOf((*MockICutter)(nil).PurgeStats))
+}
+
+// RecordClick mocks base method.
+func (m *MockICutter) RecordClick(arg0, arg1, arg2, arg3, arg4 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordClick", arg0, arg1, arg2, arg3, arg4)
+}
+
+// RecordClick indicates an expected call of RecordClick.
+func (mr *MockICutterMockRecorder) RecordClick(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordClick", reflect.TypeOf((*MockICutter)(nil).RecordClick), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Redirect mocks base method.
+func (m *MockICutter) Redirect(arg0 context.Context, arg1 jsonobject.Visit) (jsonobject.Target, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redirect", arg0, arg1)
+	ret0, _ := ret[0].(jsonobject.Target)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redirect indicates an expected call of Redirect.
+func (mr *MockICutterMockRecorder) Redirect(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redirect", reflect.TypeOf((*MockICutter)(nil).Redirect), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockICutter) Restore(arg0 context.Context, arg1 string, arg2 jsonobject.ShortIds) (jsonobject.ShortIds, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.ShortIds)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockICutterMockRecorder) Restore(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockICutter)(nil).Restore), arg0, arg1, arg2)
+}
+
+// Retarget mocks base method.
+func (m *MockICutter) Retarget(arg0 context.Context, arg1, arg2, arg3 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Retarget", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Retarget indicates an expected call of Retarget.
+func (mr *MockICutterMockRecorder) Retarget(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Retarget", reflect.TypeOf((*MockICutter)(nil).Retarget), arg0, arg1, arg2, arg3)
+}
+
+// Rollback mocks base method.
+func (m *MockICutter) Rollback(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockICutterMockRecorder) Rollback(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockICutter)(nil).Rollback), arg0, arg1, arg2)
+}
+
+// Rules mocks base method.
+func (m *MockICutter) Rules(arg0 context.Context, arg1, arg2 string) (jsonobject.Rules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rules", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.Rules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rules indicates an expected call of Rules.
+func (mr *MockICutterMockRecorder) Rules(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rules", reflect.TypeOf((*MockICutter)(nil).Rules), arg0, arg1, arg2)
+}
+
+// SetRules mocks base method.
+func (m *MockICutter) SetRules(arg0 context.Context, arg1, arg2 string, arg3 jsonobject.Rules) (jsonobject.Rules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRules", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(jsonobject.Rules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRules indicates an expected call of SetRules.
+func (mr *MockICutterMockRecorder) SetRules(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRules", reflect.TypeOf((*MockICutter)(nil).SetRules), arg0, arg1, arg2, arg3)
+}
+
+// Trash mocks base method.
+func (m *MockICutter) Trash(arg0 context.Context, arg1 string) (jsonobject.Batch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trash", arg0, arg1)
+	ret0, _ := ret[0].(jsonobject.Batch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trash indicates an expected call of Trash.
+func (mr *MockICutterMockRecorder) Trash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trash", reflect.TypeOf((*MockICutter)(nil).Trash), arg0, arg1)
+}
+
+// Unlock mocks base method.
+func (m *MockICutter) Unlock(arg0 context.Context, arg1 jsonobject.Visit, arg2 string) (jsonobject.Target, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", arg0, arg1, arg2)
+	ret0, _ := ret[0].(jsonobject.Target)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockICutterMockRecorder) Unlock(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockICutter)(nil).Unlock), arg0, arg1, arg2)
+}
+
+// UpdateRule mocks base method.
+func (m *MockICutter) UpdateRule(arg0 context.Context, arg1, arg2 string, arg3 int, arg4 jsonobject.Rule) (jsonobject.Rules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRule", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(jsonobject.Rules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRule indicates an expected call of UpdateRule.
+func (mr *MockICutterMockRecorder) UpdateRule(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRule", reflect.TypeOf((*MockICutter)(nil).UpdateRule), arg0, arg1, arg2, arg3, arg4)
+}
+
 // UploadBatch mocks base method.
 func (m *MockICutter) UploadBatch(arg0 context.Context, arg1 jsonobject.Batch) (jsonobject.Batch, error) {
 	m.ctrl.T.Helper()
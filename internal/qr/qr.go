@@ -0,0 +1,516 @@
+// Package qr кодирует данные в QR-код по ISO/IEC 18004 и выводит его в PNG или SVG.
+// Данные кодируются в байтовом режиме, версия символа (1-40) выбирается минимальная,
+// в которую помещаются данные при выбранном уровне коррекции ошибок.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Level уровень коррекции ошибок QR-кода.
+type Level int
+
+// Уровни коррекции ошибок: доля кодовых слов, которую можно восстановить при повреждении символа.
+const (
+	LevelL Level = iota // около 7%
+	LevelM              // около 15%
+	LevelQ              // около 25%
+	LevelH              // около 30%
+)
+
+// Ошибки кодирования.
+var (
+	ErrorTooLong      = errors.New("data is too long for qr code")       // данные не помещаются в версию 40
+	ErrorInvalidLevel = errors.New("qr level must be one of L, M, Q, H") // неизвестный уровень коррекции
+)
+
+// ParseLevel возвращает уровень коррекции ошибок по его имени: L, M, Q или H.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return LevelL, nil
+	case "M":
+		return LevelM, nil
+	case "Q":
+		return LevelQ, nil
+	case "H":
+		return LevelH, nil
+	}
+	return 0, fmt.Errorf("qr level %q: %w", s, ErrorInvalidLevel)
+}
+
+// String возвращает имя уровня коррекции ошибок.
+func (l Level) String() string {
+	if l < LevelL || l > LevelH {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return "LMQH"[l : l+1]
+}
+
+// formatBits код уровня коррекции ошибок в информации о формате.
+func (l Level) formatBits() int {
+	return [...]int{1, 0, 3, 2}[l]
+}
+
+const (
+	minVersion = 1
+	maxVersion = 40
+)
+
+// eccPerBlock количество кодовых слов коррекции ошибок в одном блоке по уровню и версии.
+var eccPerBlock = [4][maxVersion + 1]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// eccBlocks количество блоков коррекции ошибок по уровню и версии.
+var eccBlocks = [4][maxVersion + 1]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// Штрафы маски по ISO/IEC 18004, раздел 7.8.3.
+const (
+	penaltyRun     = 3  // серия из 5 модулей одного цвета, плюс 1 за каждый следующий
+	penaltyBlock   = 3  // блок 2x2 одного цвета
+	penaltyFinder  = 40 // последовательность, похожая на поисковый узор
+	penaltyBalance = 10 // каждые 5% отклонения доли темных модулей от 50%
+)
+
+// Code QR-код: квадрат модулей без свободной зоны вокруг.
+type Code struct {
+	modules []bool
+	// isFunction модули служебных узоров, не занятые данными
+	isFunction []bool
+	Version    int
+	Size       int
+	Level      Level
+	Mask       int
+}
+
+// Encode кодирует данные в QR-код с уровнем коррекции ошибок level.
+// Если данные не помещаются в символ версии 40, возвращает ErrorTooLong.
+func Encode(data []byte, level Level) (*Code, error) {
+	if level < LevelL || level > LevelH {
+		return nil, fmt.Errorf("encode: %w", ErrorInvalidLevel)
+	}
+	version := 0
+	for v := minVersion; v <= maxVersion; v++ {
+		if 4+countBits(v)+8*len(data) <= dataCodewords(v, level)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("encode %d bytes with level %s: %w", len(data), level, ErrorTooLong)
+	}
+
+	c := &Code{Version: version, Size: version*4 + 17, Level: level}
+	c.modules = make([]bool, c.Size*c.Size)
+	c.isFunction = make([]bool, c.Size*c.Size)
+	c.drawFunctionPatterns()
+	c.drawCodewords(c.addECC(encodeData(data, version, level)))
+
+	bestPenalty := -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			bestPenalty = p
+			c.Mask = mask
+		}
+		// маска - XOR, повторное наложение ее снимает
+		c.applyMask(mask)
+	}
+	c.applyMask(c.Mask)
+	c.drawFormatBits(c.Mask)
+	c.isFunction = nil
+	return c, nil
+}
+
+// Black сообщает, что модуль в столбце x и строке y темный.
+// Координаты вне символа относятся к светлой свободной зоне.
+func (c *Code) Black(x, y int) bool {
+	return x >= 0 && x < c.Size && y >= 0 && y < c.Size && c.modules[y*c.Size+x]
+}
+
+func (c *Code) set(x, y int, black bool) {
+	c.modules[y*c.Size+x] = black
+	c.isFunction[y*c.Size+x] = true
+}
+
+// countBits длина поля количества байт в байтовом режиме.
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// rawDataModules количество модулей символа, доступных для кодовых слов данных и коррекции ошибок.
+func rawDataModules(version int) int {
+	res := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		res -= (25*align-10)*align - 55
+		if version >= 7 {
+			res -= 36
+		}
+	}
+	return res
+}
+
+// dataCodewords количество кодовых слов данных символа.
+func dataCodewords(version int, level Level) int {
+	return rawDataModules(version)/8 - eccPerBlock[level][version]*eccBlocks[level][version]
+}
+
+// encodeData формирует кодовые слова данных: режим, длина, данные, терминатор и байты заполнения.
+func encodeData(data []byte, version int, level Level) []byte {
+	var bb bitBuffer
+	bb.append(0b0100, 4)
+	bb.append(len(data), countBits(version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+	capacity := dataCodewords(version, level) * 8
+	bb.append(0, min(4, capacity-bb.len()))
+	bb.append(0, (8-bb.len()%8)%8)
+	res := bb.bytes()
+	for pad := byte(0xEC); len(res) < capacity/8; pad ^= 0xEC ^ 0x11 {
+		res = append(res, pad)
+	}
+	return res
+}
+
+// addECC делит данные на блоки, дополняет каждый блок кодом Рида-Соломона и чередует кодовые слова блоков.
+func (c *Code) addECC(data []byte) []byte {
+	numBlocks := eccBlocks[c.Level][c.Version]
+	eccLen := eccPerBlock[c.Level][c.Version]
+	raw := rawDataModules(c.Version) / 8
+	numShort := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+
+	divisor := rsDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		n := shortLen - eccLen
+		if i >= numShort {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(block, divisor)
+		if i < numShort {
+			// короткие блоки выравниваются по длинным пустым байтом, который не попадает в результат
+			block = append(block, 0)
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	res := make([]byte, 0, raw)
+	for i := 0; i <= shortLen; i++ {
+		for j, block := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				res = append(res, block[i])
+			}
+		}
+	}
+	return res
+}
+
+// drawFunctionPatterns рисует служебные узоры и резервирует модули информации о формате и версии.
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	align := alignmentPositions(c.Version)
+	last := len(align) - 1
+	for i := range align {
+		for j := range align {
+			// узоры выравнивания не рисуются поверх поисковых узоров
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignment(align[i], align[j])
+		}
+	}
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// drawFinder рисует поисковый узор с центром в (x, y) вместе с разделителем.
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.Size || yy < 0 || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.set(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignment рисует узор выравнивания с центром в (x, y).
+func (c *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions координаты центров узоров выравнивания по каждой оси.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	num := version/7 + 2
+	step := 26
+	if version != 32 {
+		step = (version*4 + num*2 + 1) / (num*2 - 2) * 2
+	}
+	res := make([]int, num)
+	res[0] = 6
+	for i, pos := num-1, version*4+10; i >= 1; i, pos = i-1, pos-step {
+		res[i] = pos
+	}
+	return res
+}
+
+// formatInfo 15 бит информации о формате: уровень, маска и код БЧХ, наложенные на маску 0x5412.
+func formatInfo(level Level, mask int) int {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawFormatBits рисует обе копии информации о формате и темный модуль.
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatInfo(c.Level, mask)
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(bits, i))
+	}
+	c.set(8, 7, bit(bits, 6))
+	c.set(8, 8, bit(bits, 7))
+	c.set(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(bits, i))
+	}
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(bits, i))
+	}
+	c.set(8, c.Size-8, true)
+}
+
+// versionInfo 18 бит информации о версии: версия и код Голея.
+func versionInfo(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return version<<12 | rem
+}
+
+// drawVersion рисует обе копии информации о версии, она есть только у версий от 7.
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	bits := versionInfo(c.Version)
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.set(a, b, bit(bits, i))
+		c.set(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords размещает кодовые слова зигзагом по парам столбцов снизу вверх и сверху вниз,
+// пропуская служебные модули и вертикальный узор синхронизации.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if c.isFunction[y*c.Size+x] || i >= len(data)*8 {
+					continue
+				}
+				c.modules[y*c.Size+x] = bit(int(data[i>>3]), 7-(i&7))
+				i++
+			}
+		}
+	}
+}
+
+// applyMask инвертирует модули данных по условию маски mask.
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.isFunction[y*c.Size+x] && maskBit(mask, x, y) {
+				c.modules[y*c.Size+x] = !c.modules[y*c.Size+x]
+			}
+		}
+	}
+}
+
+// maskBit условие маски mask для модуля в столбце x и строке y.
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// finderLike последовательность 1:1:3:1:1 со светлыми модулями с одной из сторон.
+var finderLike = [2][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty штраф символа с наложенной маской, по нему выбирается маска.
+func (c *Code) penalty() int {
+	res := 0
+	dark := 0
+	line := make([]bool, c.Size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < c.Size; i++ {
+			for j := 0; j < c.Size; j++ {
+				if vertical {
+					line[j] = c.Black(i, j)
+				} else {
+					line[j] = c.Black(j, i)
+				}
+			}
+			res += linePenalty(line)
+		}
+	}
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			black := c.Black(x, y)
+			if black {
+				dark++
+			}
+			if x < c.Size-1 && y < c.Size-1 &&
+				black == c.Black(x+1, y) && black == c.Black(x, y+1) && black == c.Black(x+1, y+1) {
+				res += penaltyBlock
+			}
+		}
+	}
+	total := c.Size * c.Size
+	// k - количество шагов по 5% отклонения доли темных модулей от половины
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return res + k*penaltyBalance
+}
+
+// linePenalty штраф строки или столбца за серии одного цвета и последовательности, похожие на поисковый узор.
+func linePenalty(line []bool) int {
+	res := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			res += penaltyRun + run - 5
+		}
+		run = 1
+	}
+	for i := 0; i+len(finderLike[0]) <= len(line); i++ {
+		for _, pattern := range finderLike {
+			if matches(line[i:], pattern) {
+				res += penaltyFinder
+			}
+		}
+	}
+	return res
+}
+
+func matches(line, pattern []bool) bool {
+	for i, v := range pattern {
+		if line[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func bit(v, i int) bool {
+	return v>>i&1 != 0
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// bitBuffer последовательность бит, старший бит байта первый.
+type bitBuffer struct {
+	data []byte
+	n    int
+}
+
+// append дописывает младшие length бит значения v, начиная со старшего.
+func (b *bitBuffer) append(v, length int) {
+	for i := length - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.data = append(b.data, 0)
+		}
+		if bit(v, i) {
+			b.data[b.n/8] |= 0x80 >> (b.n % 8)
+		}
+		b.n++
+	}
+}
+
+func (b *bitBuffer) len() int {
+	return b.n
+}
+
+func (b *bitBuffer) bytes() []byte {
+	return b.data
+}
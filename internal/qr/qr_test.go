@@ -0,0 +1,166 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]Level{"L": LevelL, "m": LevelM, "Q": LevelQ, "h": LevelH} {
+		l, err := ParseLevel(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, l)
+		assert.Equal(t, strings.ToUpper(name), l.String())
+	}
+	_, err := ParseLevel("X")
+	assert.ErrorIs(t, err, ErrorInvalidLevel)
+}
+
+func TestRSRemainder(t *testing.T) {
+	// кодовые слова "HELLO WORLD" версии 1-M из примера кодирования ISO/IEC 18004
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	assert.Equal(t, want, rsRemainder(data, rsDivisor(len(want))))
+}
+
+func TestFormatAndVersionInfo(t *testing.T) {
+	assert.Equal(t, 0b111011111000100, formatInfo(LevelL, 0))
+	assert.Equal(t, 0b101010000010010, formatInfo(LevelM, 0))
+	assert.Equal(t, 0b011010101011111, formatInfo(LevelQ, 0))
+	assert.Equal(t, 0b001011010001001, formatInfo(LevelH, 0))
+	assert.Equal(t, 0x07C94, versionInfo(7))
+	assert.Equal(t, 0x28C69, versionInfo(40))
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	tests := map[int][]int{
+		1:  nil,
+		2:  {6, 18},
+		7:  {6, 22, 38},
+		14: {6, 26, 46, 66},
+		32: {6, 34, 60, 86, 112, 138},
+		36: {6, 24, 50, 76, 102, 128, 154},
+		40: {6, 30, 58, 86, 114, 142, 170},
+	}
+	for version, want := range tests {
+		assert.Equal(t, want, alignmentPositions(version), "version %d", version)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		level   Level
+		version int
+	}{
+		{name: "empty", data: "", level: LevelH, version: 1},
+		{name: "full version 1", data: strings.Repeat("a", 17), level: LevelL, version: 1},
+		{name: "next version", data: strings.Repeat("a", 18), level: LevelL, version: 2},
+		{name: "short url L", data: "http://localhost:8080/abcdefgh", level: LevelL, version: 2},
+		{name: "short url H", data: "http://localhost:8080/abcdefgh", level: LevelH, version: 4},
+		{name: "version info", data: strings.Repeat("a", 200), level: LevelM, version: 10},
+		{name: "max", data: strings.Repeat("a", 2953), level: LevelL, version: 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Encode([]byte(tt.data), tt.level)
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, c.Version)
+			assert.Equal(t, tt.version*4+17, c.Size)
+			assert.Equal(t, formatInfo(tt.level, c.Mask)>>14&1 == 1, c.Black(0, 8), "format info")
+			for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+				x, y := corner[0], corner[1]
+				assert.True(t, c.Black(x, y) && c.Black(x+6, y+6) && c.Black(x+3, y+3), "finder pattern")
+				assert.False(t, c.Black(x+1, y+1) || c.Black(x+5, y+5), "finder pattern")
+			}
+			assert.False(t, c.Black(-1, 0) || c.Black(c.Size, 0), "quiet zone")
+		})
+	}
+
+	_, err := Encode(make([]byte, 2954), LevelL)
+	assert.ErrorIs(t, err, ErrorTooLong)
+	_, err = Encode([]byte("a"), Level(4))
+	assert.ErrorIs(t, err, ErrorInvalidLevel)
+}
+
+// TestEncodeGolden сверяет все модули символа с эталоном.
+// Эталоны в testdata построены независимым кодировщиком rsc.io/qr/coding (байтовый режим)
+// для той же версии, уровня и маски, которые выбирает Encode.
+func TestEncodeGolden(t *testing.T) {
+	tests := []struct {
+		golden  string
+		data    string
+		level   Level
+		version int
+		mask    int
+	}{
+		{golden: "v3-M", data: "http://localhost:8080/abcdefgh", level: LevelM, version: 3, mask: 2},
+		{golden: "v3-Q", data: "https://go.brand.com/spring-sale", level: LevelQ, version: 3, mask: 4},
+		{golden: "v10-M", data: strings.Repeat("urlcut ", 30), level: LevelM, version: 10, mask: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			want, err := os.ReadFile("testdata/" + tt.golden + ".golden")
+			require.NoError(t, err)
+			c, err := Encode([]byte(tt.data), tt.level)
+			require.NoError(t, err)
+			require.Equal(t, tt.version, c.Version)
+			require.Equal(t, tt.mask, c.Mask)
+			var got strings.Builder
+			for y := 0; y < c.Size; y++ {
+				for x := 0; x < c.Size; x++ {
+					if c.Black(x, y) {
+						got.WriteByte('#')
+					} else {
+						got.WriteByte('.')
+					}
+				}
+				got.WriteByte('\n')
+			}
+			assert.Equal(t, string(want), got.String())
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	c, err := Encode([]byte("http://localhost:8080/abcdefgh"), LevelM)
+	require.NoError(t, err)
+	side := c.Size + 2*QuietZone
+
+	data, err := c.PNG(300)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	scale := 300 / side
+	assert.Equal(t, side*scale, img.Bounds().Dx())
+	r, _, _, _ := img.At(0, 0).RGBA()
+	assert.NotZero(t, r, "quiet zone is white")
+	r, _, _, _ = img.At(QuietZone*scale, QuietZone*scale).RGBA()
+	assert.Zero(t, r, "finder pattern corner is black")
+
+	data, err = c.PNG(10)
+	require.NoError(t, err)
+	img, err = png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, side, img.Bounds().Dx(), "module is at least one pixel")
+
+	svg := string(c.SVG(300))
+	assert.Contains(t, svg, `width="300" height="300"`)
+	assert.Contains(t, svg, `viewBox="0 0 37 37"`)
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Black(x, y) {
+				dark++
+			}
+		}
+	}
+	assert.Equal(t, dark, strings.Count(svg, "h1v1h-1z"))
+}
@@ -0,0 +1,45 @@
+package qr
+
+// gfMul умножает элементы поля Галуа GF(2^8) с порождающим многочленом x^8+x^4+x^3+x^2+1.
+func gfMul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// rsDivisor возвращает порождающий многочлен кода Рида-Соломона степени degree:
+// произведение (x - a^i) для i от 0 до degree-1, где a = 2.
+// Коэффициенты от старшего к младшему, старший коэффициент 1 опущен.
+func rsDivisor(degree int) []byte {
+	res := make([]byte, degree)
+	res[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range res {
+			res[j] = gfMul(res[j], root)
+			if j+1 < len(res) {
+				res[j] ^= res[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	return res
+}
+
+// rsRemainder возвращает кодовые слова коррекции ошибок блока data:
+// остаток от деления многочлена данных на порождающий многочлен divisor.
+func rsRemainder(data, divisor []byte) []byte {
+	res := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ res[0]
+		copy(res, res[1:])
+		res[len(res)-1] = 0
+		for i, d := range divisor {
+			res[i] ^= gfMul(d, factor)
+		}
+	}
+	return res
+}
@@ -0,0 +1,58 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// QuietZone ширина свободной зоны вокруг символа в модулях.
+const QuietZone = 4
+
+// scale размер модуля в пикселях, при котором символ со свободной зоной помещается в size пикселей.
+// Модуль не бывает меньше одного пикселя, поэтому для малого size изображение получается больше size.
+func (c *Code) scale(size int) int {
+	return max(1, size/(c.Size+2*QuietZone))
+}
+
+// PNG возвращает QR-код в формате PNG со стороной не больше size пикселей.
+// Размер модуля - целое число пикселей, чтобы изображение оставалось четким, см. scale.
+func (c *Code) PNG(size int) ([]byte, error) {
+	scale := c.scale(size)
+	side := (c.Size + 2*QuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			if c.Black(x/scale-QuietZone, y/scale-QuietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG возвращает QR-код в формате SVG шириной size пикселей.
+// Темные модули рисуются одним контуром в координатах модулей, поэтому изображение масштабируется без потерь.
+func (c *Code) SVG(size int) []byte {
+	side := c.Size + 2*QuietZone
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		size, size, side, side)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#FFFFFF"/>`+"\n"+`<path fill="#000000" d="`, side, side)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Black(x, y) {
+				fmt.Fprintf(&buf, "M%d,%dh1v1h-1z", x+QuietZone, y+QuietZone)
+			}
+		}
+	}
+	buf.WriteString("\"/>\n</svg>\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,77 @@
+package serverapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/dmad1989/urlcut/internal/cutter"
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// hostDomain возвращает host в нижнем регистре, если это хост дополнительного домена сокращений,
+// для домена по умолчанию и остальных хостов - пусто.
+func (s Server) hostDomain(host string) string {
+	host = strings.ToLower(host)
+	for _, base := range s.config.GetShortDomains() {
+		if cutter.DomainHost(base) == host {
+			return host
+		}
+	}
+	return ""
+}
+
+// requestDomain возвращает домен сокращений запроса: из параметра domain, иначе - хост запроса, см. hostDomain.
+func (s Server) requestDomain(req *http.Request) string {
+	if domain := req.URL.Query().Get("domain"); domain != "" {
+		return s.hostDomain(domain)
+	}
+	return s.hostDomain(req.Host)
+}
+
+// linkKey возвращает ключ сокращения code в хранилище с учетом домена запроса, см. cutter.LinkKey.
+func (s Server) linkKey(req *http.Request, code string) string {
+	return cutter.LinkKey(s.requestDomain(req), code)
+}
+
+// shortParam возвращает ключ сокращения из пути запроса /.../{short}/... с учетом домена запроса.
+func (s Server) shortParam(req *http.Request) string {
+	return s.linkKey(req, chi.URLParam(req, "short"))
+}
+
+// linkKeys возвращает ключи сокращений ids: коды дополняются доменом запроса,
+// ключи вида "домен/код" остаются как есть.
+func (s Server) linkKeys(req *http.Request, ids jsonobject.ShortIds) jsonobject.ShortIds {
+	domain := s.requestDomain(req)
+	res := make(jsonobject.ShortIds, len(ids))
+	for i, id := range ids {
+		if strings.Contains(id, "/") {
+			res[i] = id
+			continue
+		}
+		res[i] = cutter.LinkKey(domain, id)
+	}
+	return res
+}
+
+// domainAddress возвращает базовый адрес домена сокращений domain, для пустого домена - адрес по умолчанию.
+func (s Server) domainAddress(domain string) string {
+	if domain == "" {
+		return s.config.GetShortAddress()
+	}
+	for _, base := range s.config.GetShortDomains() {
+		if cutter.DomainHost(base) == domain {
+			return base
+		}
+	}
+	// домен убран из конфигурации после создания сокращения
+	return "https://" + domain
+}
+
+// shortURL возвращает сокращенный URL по ключу сокращения в хранилище: адрес домена сокращения и код.
+func (s Server) shortURL(key string) string {
+	domain, code := cutter.SplitLinkKey(key)
+	return fmt.Sprintf("%s/%s", s.domainAddress(domain), code)
+}
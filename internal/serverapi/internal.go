@@ -0,0 +1,87 @@
+package serverapi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Ошибки доступа к служебным эндпоинтам.
+var (
+	errorNoTrustedSubnet = errors.New("trusted subnet is not configured") // подсеть не задана, доступ закрыт
+	errorUntrustedIP     = errors.New("ip is not in trusted subnet")      // адрес клиента вне подсети
+)
+
+// TrustedSubnet это middleware для служебных эндпоинтов /api/internal.
+// Пропускает запрос, только если адрес соединения входит в доверенную подсеть из конфигурации.
+// Заголовки X-Real-IP и X-Forwarded-For не учитываются: их может задать любой клиент.
+func (s Server) TrustedSubnet(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.checkTrustedIP(remoteIP(r)); err != nil {
+			responseStatusError(w, http.StatusForbidden, fmt.Errorf("trusted subnet: %w", err))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// remoteIP возвращает IP адреса соединения запроса.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// inSubnets сообщает, что ip входит в одну из подсетей subnets в CIDR-нотации.
+// Подсети с ошибкой пропускаются: они проверяются при чтении конфигурации.
+func inSubnets(ip string, subnets []string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, s := range subnets {
+		if _, subnet, err := net.ParseCIDR(s); err == nil && subnet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTrustedIP проверяет, что ip входит в доверенную подсеть.
+func (s Server) checkTrustedIP(ip string) error {
+	if s.config.GetTrustedSubnet() == "" {
+		return errorNoTrustedSubnet
+	}
+	_, subnet, err := net.ParseCIDR(s.config.GetTrustedSubnet())
+	if err != nil {
+		return fmt.Errorf("parse subnet: %w", err)
+	}
+	if addr := net.ParseIP(ip); addr == nil || !subnet.Contains(addr) {
+		return fmt.Errorf("%w: %q", errorUntrustedIP, ip)
+	}
+	return nil
+}
+
+// purgeStatsHandler godoc
+// @Tags Info
+// @Summary Статистика окончательного удаления сокращений
+// @ID purgeStats
+// @Produce json
+// @Success 200 {object} jsonobject.PurgeStats
+// @Failure 403 {string} string "IP не входит в доверенную подсеть"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/internal/purge [get]
+func (s Server) purgeStatsHandler(res http.ResponseWriter, req *http.Request) {
+	stats := s.cutter.PurgeStats()
+	respb, err := stats.MarshalJSON()
+	if err != nil {
+		responseError(res, fmt.Errorf("purgeStatsHandler: encoding response: %w", err))
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	res.Write(respb)
+}
@@ -0,0 +1,88 @@
+package serverapi
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/dmad1989/urlcut/internal/cutter"
+	"github.com/dmad1989/urlcut/internal/logging"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+// passwordForm страница ввода пароля для сокращений с паролем.
+var passwordForm = template.Must(template.ParseFS(templatesFS, "templates/password.html"))
+
+type passwordFormData struct {
+	// Action адрес отправки формы: адрес перехода вместе с путем и параметрами запроса
+	Action string
+	Error  string
+}
+
+// unlockHandler godoc
+// @Tags Operate
+// @Summary Переход по сокращенному URL с паролем
+// @ID unlock
+// @Accept  x-www-form-urlencoded
+// @Produce html
+// @Param path path string true "Сокращенный url"
+// @Param password formData string true "Пароль сокращения"
+// @Success 303 "Переход по сокращенному URL"
+// @Success 200 {string} string "Страница перехода для redirect_type meta-refresh и interstitial"
+// @Failure 403 {string} string "Форма ввода пароля с сообщением о неверном пароле"
+// @Failure 404 {string} string "Сокращение еще не работает (active_from)"
+// @Failure 410 {string} string "url was deleted, url has expired или url click limit is exhausted"
+// @Failure 429 {string} string "too many wrong password attempts"
+// @Failure 400 {string} string "Ошибка"
+// @Router /{path} [post]
+func (s Server) unlockHandler(res http.ResponseWriter, req *http.Request) {
+	path := chi.URLParam(req, "path")
+	if path == "" {
+		responseError(res, fmt.Errorf("unlockHandler: url path is empty"))
+		return
+	}
+	if err := req.ParseForm(); err != nil {
+		responseError(res, fmt.Errorf("unlockHandler: parsing form: %w", err))
+		return
+	}
+
+	v := s.visitFromRequest(req, path)
+	target, err := s.cutter.Unlock(req.Context(), v, req.PostFormValue("password"))
+	if s.responseNotActive(res, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, cutter.ErrorWrongPassword):
+		renderPasswordForm(res, http.StatusForbidden, passwordFormData{Action: req.URL.RequestURI(), Error: "Неверный пароль"})
+	case errors.Is(err, cutter.ErrorTooManyAttempts):
+		responseStatusError(res, http.StatusTooManyRequests, fmt.Errorf("unlockHandler: %w", err))
+	case isGone(err):
+		responseStatusError(res, http.StatusGone, err)
+	case err != nil:
+		responseError(res, fmt.Errorf("unlockHandler: fetching url fo redirect: %w", err))
+	default:
+		s.recordClick(req, v.Short, target)
+		setVariantCookie(res, path, target)
+		if renderTarget(res, target) {
+			return
+		}
+		// 303 вместо кода redirect_type, чтобы браузер перешел по URL методом GET и не отправил пароль повторно
+		http.Redirect(res, req, target.URL, http.StatusSeeOther)
+	}
+}
+
+// renderPasswordForm отдает страницу ввода пароля.
+func renderPasswordForm(res http.ResponseWriter, status int, data passwordFormData) {
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.Header().Set("Cache-Control", "no-store")
+	res.WriteHeader(status)
+	if err := passwordForm.Execute(res, data); err != nil {
+		logging.Log.Errorw("renderPasswordForm", "error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package serverapi
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/dmad1989/urlcut/internal/cutter"
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/logging"
+)
+
+// Признаки запроса предпросмотра: /{path}+ или /{path}?preview=1.
+const (
+	previewSuffix = "+"
+	previewParam  = "preview"
+)
+
+// previewPage страница предпросмотра сокращения.
+var previewPage = template.Must(template.ParseFS(templatesFS, "templates/preview.html"))
+
+type previewPageData struct {
+	URL   string
+	Host  string
+	Title string
+	// Created и CreatedISO момент создания для показа и для атрибута datetime, пустые - момент неизвестен
+	Created    string
+	CreatedISO string
+	// Continue адрес перехода по сокращению без признака предпросмотра
+	Continue string
+	Clicks   int64
+}
+
+// previewShort сообщает, что запрос - предпросмотр сокращения, и возвращает сокращение без суффикса "+".
+func previewShort(req *http.Request, path string) (string, bool) {
+	if short, ok := strings.CutSuffix(path, previewSuffix); ok && chi.URLParam(req, "*") == "" {
+		return short, true
+	}
+	isPreview, _ := strconv.ParseBool(req.URL.Query().Get(previewParam))
+	return path, isPreview
+}
+
+// previewHandler отдает страницу предпросмотра сокращения short.
+// Страница не считается переходом: переход не записывается в статистику и не списывается.
+func (s Server) previewHandler(res http.ResponseWriter, req *http.Request, short string) {
+	continueURL := *req.URL
+	continueURL.Path = strings.TrimSuffix(continueURL.Path, previewSuffix)
+	continueURL.RawPath = ""
+	query := continueURL.Query()
+	query.Del(previewParam)
+	continueURL.RawQuery = query.Encode()
+
+	v := jsonobject.Visit{Short: cutter.LinkKey(s.hostDomain(req.Host), short), Path: chi.URLParam(req, "*"), Query: query}
+	p, err := s.cutter.Preview(req.Context(), v)
+	if s.responseNotActive(res, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, cutter.ErrorPasswordRequired):
+		renderPasswordForm(res, http.StatusOK, passwordFormData{Action: continueURL.RequestURI()})
+		return
+	case isGone(err):
+		responseStatusError(res, http.StatusGone, err)
+		return
+	case err != nil:
+		responseError(res, fmt.Errorf("previewHandler: %w", err))
+		return
+	}
+
+	data := previewPageData{URL: p.URL, Title: p.Title, Clicks: p.Clicks, Continue: continueURL.RequestURI()}
+	if u, err := url.Parse(p.URL); err == nil {
+		data.Host = u.Host
+	}
+	if p.CreatedAt != nil {
+		data.Created = p.CreatedAt.UTC().Format("02.01.2006 15:04 UTC")
+		data.CreatedISO = p.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.Header().Set("Cache-Control", "no-store")
+	res.WriteHeader(http.StatusOK)
+	if err := previewPage.Execute(res, data); err != nil {
+		logging.Log.Errorw("previewHandler", "error", err)
+	}
+}
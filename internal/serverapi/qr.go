@@ -0,0 +1,106 @@
+package serverapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dmad1989/urlcut/internal/cutter"
+	"github.com/dmad1989/urlcut/internal/qr"
+)
+
+// Параметры изображения QR-кода.
+const (
+	qrDefaultSize  = 256
+	qrMinSize      = 64
+	qrMaxSize      = 2048
+	qrDefaultLevel = "M"
+)
+
+// qrHandler godoc
+// @Tags Operate
+// @Summary QR-код сокращенного URL
+// @Description Кодирует в QR-код полный сокращенный URL. Сторона PNG кратна размеру символа и не превышает size.
+// @ID qr
+// @Produce png
+// @Produce image/svg+xml
+// @Param short path string true "Сокращение"
+// @Param size query int false "Сторона изображения в пикселях, от 64 до 2048, по умолчанию 256"
+// @Param format query string false "Формат изображения: png или svg, по умолчанию png"
+// @Param level query string false "Уровень коррекции ошибок: L, M, Q или H, по умолчанию M"
+// @Success 200 {file} file "Изображение QR-кода"
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 410 {string} string "url was deleted, url has expired или url click limit is exhausted"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/qr/{short} [get]
+func (s Server) qrHandler(res http.ResponseWriter, req *http.Request) {
+	short := s.shortParam(req)
+	q := req.URL.Query()
+	size := qrDefaultSize
+	if v := q.Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < qrMinSize || n > qrMaxSize {
+			responseError(res, fmt.Errorf("qrHandler: size must be from %d to %d", qrMinSize, qrMaxSize))
+			return
+		}
+		size = n
+	}
+	level, err := qr.ParseLevel(notEmpty(q.Get("level"), qrDefaultLevel))
+	if err != nil {
+		responseError(res, fmt.Errorf("qrHandler: %w", err))
+		return
+	}
+	format := notEmpty(q.Get("format"), "png")
+	if format != "png" && format != "svg" {
+		responseError(res, fmt.Errorf("qrHandler: format must be png or svg"))
+		return
+	}
+
+	if err = s.cutter.CheckLink(req.Context(), short); err != nil {
+		if isGone(err) {
+			responseStatusError(res, http.StatusGone, err)
+			return
+		}
+		responseError(res, fmt.Errorf("qrHandler: %w", err))
+		return
+	}
+	code, err := qr.Encode([]byte(s.shortURL(short)), level)
+	if err != nil {
+		responseError(res, fmt.Errorf("qrHandler: %w", err))
+		return
+	}
+
+	var img []byte
+	if format == "svg" {
+		img = code.SVG(size)
+		res.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		if img, err = code.PNG(size); err != nil {
+			responseStatusError(res, http.StatusInternalServerError, fmt.Errorf("qrHandler: %w", err))
+			return
+		}
+		res.Header().Set("Content-Type", "image/png")
+	}
+	res.WriteHeader(http.StatusOK)
+	res.Write(img)
+}
+
+// wantQR сообщает, что клиент запросил адрес QR-кода в ответе: параметр запроса qr=true.
+func wantQR(req *http.Request) bool {
+	v, _ := strconv.ParseBool(req.URL.Query().Get("qr"))
+	return v
+}
+
+// qrURL возвращает адрес QR-кода сокращения по ключу short на домене сокращения.
+func (s Server) qrURL(short string) string {
+	domain, code := cutter.SplitLinkKey(short)
+	return fmt.Sprintf("%s/api/qr/%s", s.domainAddress(domain), code)
+}
+
+// notEmpty возвращает v или def, если v пустое.
+func notEmpty(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
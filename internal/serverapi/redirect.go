@@ -0,0 +1,57 @@
+package serverapi
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/dmad1989/urlcut/internal/cutter"
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/logging"
+)
+
+// redirectPage страница перехода для способов meta-refresh и interstitial.
+var redirectPage = template.Must(template.ParseFS(templatesFS, "templates/redirect.html"))
+
+type redirectPageData struct {
+	URL string
+	// Host хост URL перехода, показывается на странице interstitial
+	Host string
+	// Refresh переходить сразу через meta refresh
+	Refresh bool
+}
+
+// redirectStatuses коды HTTP-редиректа способов перехода.
+var redirectStatuses = map[string]int{
+	cutter.RedirectMovedPermanently: http.StatusMovedPermanently,
+	cutter.RedirectFound:            http.StatusFound,
+	cutter.RedirectTemporary:        http.StatusTemporaryRedirect,
+	cutter.RedirectPermanent:        http.StatusPermanentRedirect,
+}
+
+// redirectStatus возвращает код HTTP-редиректа способа перехода, для неизвестного способа - 307.
+func redirectStatus(redirectType string) int {
+	if status, ok := redirectStatuses[redirectType]; ok {
+		return status
+	}
+	return http.StatusTemporaryRedirect
+}
+
+// renderTarget отдает страницу перехода, если способ перехода t - HTML-страница, и сообщает об этом.
+// Для способов HTTP-редиректа ничего не делает.
+func renderTarget(res http.ResponseWriter, t jsonobject.Target) bool {
+	if t.RedirectType != cutter.RedirectMetaRefresh && t.RedirectType != cutter.RedirectInterstitial {
+		return false
+	}
+	data := redirectPageData{URL: t.URL, Refresh: t.RedirectType == cutter.RedirectMetaRefresh}
+	if u, err := url.Parse(t.URL); err == nil {
+		data.Host = u.Host
+	}
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.Header().Set("Cache-Control", "no-store")
+	res.WriteHeader(http.StatusOK)
+	if err := redirectPage.Execute(res, data); err != nil {
+		logging.Log.Errorw("renderTarget", "error", err)
+	}
+	return true
+}
@@ -0,0 +1,232 @@
+package serverapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// rulesHandler godoc
+// @Tags UserURLs
+// @Summary Правила перехода сокращения пользователя в порядке проверки
+// @Description При переходе выполняется первое правило, все условия которого выполнены;
+// @Description если ни одно правило не подошло - переход на оригинальный URL сокращения.
+// @ID rules
+// @Produce json
+// @Param short path string true "Сокращение"
+// @Success 200 {object} jsonobject.Rules
+// @Success 204 {string} string "Правил перехода нет"
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 403 {string} string "Сокращение создано другим пользователем"
+// @Failure 404 {string} string "Сокращение не найдено"
+// @Failure 410 {string} string "url was deleted"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/{short}/rules [get]
+func (s Server) rulesHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	rules, err := s.cutter.Rules(req.Context(), userID, s.shortParam(req))
+	if err != nil {
+		responseStatusError(res, userURLErrorStatus(err), fmt.Errorf("rulesHandler: %w", err))
+		return
+	}
+	responseRules(res, http.StatusOK, rules)
+}
+
+// setRulesHandler godoc
+// @Tags UserURLs
+// @Summary Замена всех правил перехода сокращения пользователя
+// @Description Пустой список удаляет все правила. URL правил проверяются политикой сервиса, как при сокращении.
+// @ID setRules
+// @Accept  json
+// @Produce json
+// @Param short path string true "Сокращение"
+// @Param request body jsonobject.Rules true "Правила перехода в порядке проверки"
+// @Success 200 {object} jsonobject.Rules
+// @Success 204 {string} string "Правила удалены"
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 403 {string} string "Сокращение создано другим пользователем"
+// @Failure 404 {string} string "Сокращение не найдено"
+// @Failure 410 {string} string "url was deleted"
+// @Failure 422 {string} string "URL правила отклонен политикой сервиса"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/{short}/rules [put]
+func (s Server) setRulesHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var rules jsonobject.Rules
+	if err = readJSON(req, &rules); err != nil {
+		responseError(res, fmt.Errorf("setRulesHandler: %w", err))
+		return
+	}
+	rules, err = s.cutter.SetRules(req.Context(), userID, s.shortParam(req), rules)
+	if err != nil {
+		responseStatusError(res, userURLErrorStatus(err), fmt.Errorf("setRulesHandler: %w", err))
+		return
+	}
+	responseRules(res, http.StatusOK, rules)
+}
+
+// addRuleHandler godoc
+// @Tags UserURLs
+// @Summary Добавление правила перехода в конец списка правил сокращения пользователя
+// @ID addRule
+// @Accept  json
+// @Produce json
+// @Param short path string true "Сокращение"
+// @Param request body jsonobject.Rule true "Правило перехода"
+// @Success 201 {object} jsonobject.Rules
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 403 {string} string "Сокращение создано другим пользователем"
+// @Failure 404 {string} string "Сокращение не найдено"
+// @Failure 410 {string} string "url was deleted"
+// @Failure 422 {string} string "URL правила отклонен политикой сервиса"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/{short}/rules [post]
+func (s Server) addRuleHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var rule jsonobject.Rule
+	if err = readJSON(req, &rule); err != nil {
+		responseError(res, fmt.Errorf("addRuleHandler: %w", err))
+		return
+	}
+	rules, err := s.cutter.AddRule(req.Context(), userID, s.shortParam(req), rule)
+	if err != nil {
+		responseStatusError(res, userURLErrorStatus(err), fmt.Errorf("addRuleHandler: %w", err))
+		return
+	}
+	responseRules(res, http.StatusCreated, rules)
+}
+
+// updateRuleHandler godoc
+// @Tags UserURLs
+// @Summary Замена правила перехода сокращения пользователя
+// @ID updateRule
+// @Accept  json
+// @Produce json
+// @Param short path string true "Сокращение"
+// @Param index path int true "Номер правила в списке, с нуля"
+// @Param request body jsonobject.Rule true "Правило перехода"
+// @Success 200 {object} jsonobject.Rules
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 403 {string} string "Сокращение создано другим пользователем"
+// @Failure 404 {string} string "Сокращение или правило не найдено"
+// @Failure 410 {string} string "url was deleted"
+// @Failure 422 {string} string "URL правила отклонен политикой сервиса"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/{short}/rules/{index} [put]
+func (s Server) updateRuleHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	n, err := ruleIndex(req)
+	if err != nil {
+		responseError(res, fmt.Errorf("updateRuleHandler: %w", err))
+		return
+	}
+	var rule jsonobject.Rule
+	if err = readJSON(req, &rule); err != nil {
+		responseError(res, fmt.Errorf("updateRuleHandler: %w", err))
+		return
+	}
+	rules, err := s.cutter.UpdateRule(req.Context(), userID, s.shortParam(req), n, rule)
+	if err != nil {
+		responseStatusError(res, userURLErrorStatus(err), fmt.Errorf("updateRuleHandler: %w", err))
+		return
+	}
+	responseRules(res, http.StatusOK, rules)
+}
+
+// deleteRuleHandler godoc
+// @Tags UserURLs
+// @Summary Удаление правила перехода сокращения пользователя
+// @Description Следующие правила сдвигаются на место удаленного.
+// @ID deleteRule
+// @Produce json
+// @Param short path string true "Сокращение"
+// @Param index path int true "Номер правила в списке, с нуля"
+// @Success 200 {object} jsonobject.Rules "Оставшиеся правила"
+// @Success 204 {string} string "Правил больше нет"
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 403 {string} string "Сокращение создано другим пользователем"
+// @Failure 404 {string} string "Сокращение или правило не найдено"
+// @Failure 410 {string} string "url was deleted"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/{short}/rules/{index} [delete]
+func (s Server) deleteRuleHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	n, err := ruleIndex(req)
+	if err != nil {
+		responseError(res, fmt.Errorf("deleteRuleHandler: %w", err))
+		return
+	}
+	rules, err := s.cutter.DeleteRule(req.Context(), userID, s.shortParam(req), n)
+	if err != nil {
+		responseStatusError(res, userURLErrorStatus(err), fmt.Errorf("deleteRuleHandler: %w", err))
+		return
+	}
+	responseRules(res, http.StatusOK, rules)
+}
+
+// ruleIndex читает номер правила из пути запроса.
+func ruleIndex(req *http.Request) (int, error) {
+	n, err := strconv.Atoi(chi.URLParam(req, "index"))
+	if err != nil {
+		return 0, fmt.Errorf("parsing rule index: %w", err)
+	}
+	return n, nil
+}
+
+// readJSON читает тело запроса с Content-Type application/json в v.
+func readJSON(req *http.Request, v json.Unmarshaler) error {
+	if req.Header.Get("Content-Type") != "application/json" {
+		return errors.New("content-type have to be application/json")
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("reading request body: %w", err)
+	}
+	if err = v.UnmarshalJSON(body); err != nil {
+		return fmt.Errorf("decoding request: %w", err)
+	}
+	return nil
+}
+
+// responseRules отвечает списком правил перехода со статусом status, пустой список - 204.
+func responseRules(res http.ResponseWriter, status int, rules jsonobject.Rules) {
+	if len(rules) == 0 {
+		res.WriteHeader(http.StatusNoContent)
+		return
+	}
+	respb, err := rules.MarshalJSON()
+	if err != nil {
+		responseError(res, fmt.Errorf("encoding response: %w", err))
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	res.Write(respb)
+}
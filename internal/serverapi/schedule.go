@@ -0,0 +1,46 @@
+package serverapi
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/dmad1989/urlcut/internal/cutter"
+	"github.com/dmad1989/urlcut/internal/logging"
+)
+
+// soonPage страница "скоро" для сокращений, которые еще не начали работать.
+var soonPage = template.Must(template.ParseFS(templatesFS, "templates/soon.html"))
+
+type soonPageData struct {
+	Title         string
+	ActiveFrom    string
+	ActiveFromISO string
+}
+
+// responseNotActive отвечает 404 на переход по сокращению до начала его работы и сообщает об этом.
+// Если в конфигурации включена страница "скоро", вместе с 404 отдается она.
+// Для остальных ошибок ничего не делает.
+func (s Server) responseNotActive(res http.ResponseWriter, err error) bool {
+	var notActive *cutter.NotActiveError
+	if !errors.As(err, &notActive) {
+		return false
+	}
+	res.Header().Set("Cache-Control", "no-store")
+	if !s.config.GetComingSoonPage() {
+		http.Error(res, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return true
+	}
+	data := soonPageData{
+		Title:         notActive.Title,
+		ActiveFrom:    notActive.ActiveFrom.UTC().Format("02.01.2006 15:04 UTC"),
+		ActiveFromISO: notActive.ActiveFrom.UTC().Format(time.RFC3339),
+	}
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
+	res.WriteHeader(http.StatusNotFound)
+	if err = soonPage.Execute(res, data); err != nil {
+		logging.Log.Errorw("responseNotActive", "error", err)
+	}
+	return true
+}
@@ -9,6 +9,8 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	_ "net/http/pprof"
@@ -18,7 +20,6 @@ import (
 
 	"github.com/dmad1989/urlcut/internal/config"
 	"github.com/dmad1989/urlcut/internal/cutter"
-	"github.com/dmad1989/urlcut/internal/dbstore"
 	"github.com/dmad1989/urlcut/internal/jsonobject"
 	"github.com/dmad1989/urlcut/internal/logging"
 )
@@ -47,19 +48,40 @@ import (
 
 // ICutter интерфейс слоя с бизнес логикой
 type ICutter interface {
-	Cut(cxt context.Context, url string) (generated string, err error)
-	GetKeyByValue(cxt context.Context, value string) (res string, err error)
+	Cut(cxt context.Context, url string, opts jsonobject.LinkOptions) (generated string, err error)
+	Redirect(ctx context.Context, v jsonobject.Visit) (jsonobject.Target, error)
+	Unlock(ctx context.Context, v jsonobject.Visit, password string) (jsonobject.Target, error)
+	Preview(ctx context.Context, v jsonobject.Visit) (jsonobject.Preview, error)
+	CheckLink(ctx context.Context, short string) error
 	PingDB(context.Context) error
 	UploadBatch(ctx context.Context, batch jsonobject.Batch) (jsonobject.Batch, error)
 	GetUserURLs(ctx context.Context) (jsonobject.Batch, error)
-	DeleteUrls(userID string, ids jsonobject.ShortIds)
+	DeleteUrls(ctx context.Context, userID string, ids jsonobject.ShortIds) (jsonobject.DeleteJob, error)
+	GetDeleteJob(ctx context.Context, userID, id string) (jsonobject.DeleteJob, error)
+	Retarget(ctx context.Context, userID, short, url string) (string, error)
+	History(ctx context.Context, userID, short string) (jsonobject.History, error)
+	Rollback(ctx context.Context, userID, short string) (string, error)
+	Trash(ctx context.Context, userID string) (jsonobject.Batch, error)
+	Restore(ctx context.Context, userID string, ids jsonobject.ShortIds) (jsonobject.ShortIds, error)
+	PurgeStats() jsonobject.PurgeStats
+	RecordClick(short, variant, referrer, userAgent, ip string)
+	LinkStats(ctx context.Context, userID, short string) (jsonobject.LinkStats, error)
+	Rules(ctx context.Context, userID, short string) (jsonobject.Rules, error)
+	SetRules(ctx context.Context, userID, short string, rules jsonobject.Rules) (jsonobject.Rules, error)
+	AddRule(ctx context.Context, userID, short string, rule jsonobject.Rule) (jsonobject.Rules, error)
+	UpdateRule(ctx context.Context, userID, short string, n int, rule jsonobject.Rule) (jsonobject.Rules, error)
+	DeleteRule(ctx context.Context, userID, short string, n int) (jsonobject.Rules, error)
 }
 
 // Configer интерйфейс конфигураци
 type Configer interface {
 	GetURL() string
 	GetShortAddress() string
+	GetShortDomains() []string
 	GetEnableHTTPS() bool
+	GetTrustedSubnet() string
+	GetTrustedProxies() []string
+	GetComingSoonPage() bool
 }
 
 // Server содержит интерфейсы для обращения к другим слоям и роутинг.
@@ -137,11 +159,28 @@ func (s Server) initHandlers() {
 	s.mux.Mount("/debug", middleware.Profiler())
 	s.mux.Post("/", s.cutterHandler)
 	s.mux.Get("/{path}", s.redirectHandler)
+	s.mux.Post("/{path}", s.unlockHandler)
+	s.mux.Get("/{path}/*", s.redirectHandler)
+	s.mux.Post("/{path}/*", s.unlockHandler)
 	s.mux.Get("/ping", s.pingHandler)
 	s.mux.Post("/api/shorten", s.cutterJSONHandler)
 	s.mux.Post("/api/shorten/batch", s.cutterJSONBatchHandler)
 	s.mux.Get("/api/user/urls", s.userUrlsHandler)
 	s.mux.Delete("/api/user/urls", s.deleteUserUrlsHandler)
+	s.mux.Get("/api/user/urls/delete-jobs/{id}", s.deleteJobHandler)
+	s.mux.Get("/api/user/urls/trash", s.trashHandler)
+	s.mux.Post("/api/user/urls/restore", s.restoreHandler)
+	s.mux.Patch("/api/user/urls/{short}", s.retargetHandler)
+	s.mux.Get("/api/user/urls/{short}/history", s.historyHandler)
+	s.mux.Post("/api/user/urls/{short}/rollback", s.rollbackHandler)
+	s.mux.Get("/api/user/urls/{short}/stats", s.statsHandler)
+	s.mux.Get("/api/user/urls/{short}/rules", s.rulesHandler)
+	s.mux.Put("/api/user/urls/{short}/rules", s.setRulesHandler)
+	s.mux.Post("/api/user/urls/{short}/rules", s.addRuleHandler)
+	s.mux.Put("/api/user/urls/{short}/rules/{index}", s.updateRuleHandler)
+	s.mux.Delete("/api/user/urls/{short}/rules/{index}", s.deleteRuleHandler)
+	s.mux.Get("/api/qr/{short}", s.qrHandler)
+	s.mux.With(s.TrustedSubnet).Get("/api/internal/purge", s.purgeStatsHandler)
 }
 
 // cutterJSONHandler godoc
@@ -150,7 +189,12 @@ func (s Server) initHandlers() {
 // @ID cutterJSON
 // @Accept  json
 // @Produce json
+// @Param request body jsonobject.Request true "URL и параметры сокращения"
+// @Param qr query bool false "Добавить в ответ адрес QR-кода"
 // @Success 201 {object} jsonobject.Response
+// @Success 409 {object} jsonobject.Response "URL уже сокращен"
+// @Failure 409 {string} string "Сокращение (alias) уже занято"
+// @Failure 422 {string} string "URL отклонен политикой сервиса"
 // @Failure 401 {string} string "Ошибка авторизации"
 // @Failure 400 {string} string "Ошибка"
 // @Router /api/shorten [post]
@@ -170,11 +214,21 @@ func (s Server) cutterJSONHandler(res http.ResponseWriter, req *http.Request) {
 		responseError(res, fmt.Errorf("cutterJsonHandler: decoding request: %w", err))
 		return
 	}
-	code, err := s.cutter.Cut(req.Context(), reqJSON.URL)
+	if reqJSON.Domain == "" {
+		reqJSON.Domain = s.hostDomain(req.Host)
+	}
+	code, err := s.cutter.Cut(req.Context(), reqJSON.URL, reqJSON.LinkOptions)
 	status := http.StatusCreated
 	if err != nil {
 		var uerr *cutter.UniqueURLError
-		if !errors.As(err, &uerr) {
+		switch {
+		case errors.Is(err, cutter.ErrorShortURLTaken):
+			responseStatusError(res, http.StatusConflict, fmt.Errorf("cutterJsonHandler: %w", err))
+			return
+		case isPolicyError(err):
+			responseStatusError(res, http.StatusUnprocessableEntity, fmt.Errorf("cutterJsonHandler: %w", err))
+			return
+		case !errors.As(err, &uerr):
 			responseError(res, fmt.Errorf("cutterJsonHandler: getting code for url: %w", err))
 			return
 		}
@@ -185,7 +239,10 @@ func (s Server) cutterJSONHandler(res http.ResponseWriter, req *http.Request) {
 	res.Header().Set("Content-Type", "application/json")
 	res.WriteHeader(status)
 	respJSON := jsonobject.Response{
-		Result: fmt.Sprintf("%s/%s", s.config.GetShortAddress(), code),
+		Result: s.shortURL(code),
+	}
+	if wantQR(req) {
+		respJSON.QR = s.qrURL(code)
 	}
 	respb, err := respJSON.MarshalJSON()
 	if err != nil {
@@ -201,7 +258,19 @@ func (s Server) cutterJSONHandler(res http.ResponseWriter, req *http.Request) {
 // @ID cutterText
 // @Accept  plain/text
 // @Produce plain/text
+// @Param alias query string false "Желаемое сокращение"
+// @Param expires_at query string false "Момент окончания действия сокращения, RFC3339"
+// @Param active_from query string false "Момент начала работы сокращения, RFC3339"
+// @Param ttl query int false "Срок действия сокращения в секундах"
+// @Param max_clicks query int false "Количество переходов, после которого сокращение перестает работать"
+// @Param pass_query query string false "Передача параметров запроса перехода: keep, override или append"
+// @Param pass_path query bool false "Дописывать к URL путь после сокращения"
+// @Param redirect_type query string false "Способ перехода: 301, 302, 307, 308, meta-refresh или interstitial"
+// @Param title query string false "Название сокращения для страницы предпросмотра"
+// @Param domain query string false "Домен сокращения из доменов сервиса, по умолчанию - домен запроса"
 // @Success 201 {string} string "Сокращенный URL"
+// @Failure 409 {string} string "URL уже сокращен или сокращение (alias) занято"
+// @Failure 422 {string} string "URL отклонен политикой сервиса"
 // @Failure 401 {string} string "Ошибка авторизации"
 // @Failure 400 {string} string "Ошибка"
 // @Router / [post]
@@ -223,11 +292,26 @@ func (s Server) cutterHandler(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	code, err := s.cutter.Cut(req.Context(), string(body))
+	opts, err := linkOptionsFromQuery(req.URL.Query())
+	if err != nil {
+		responseError(res, fmt.Errorf("cutterHandler: %w", err))
+		return
+	}
+	if opts.Domain == "" {
+		opts.Domain = s.hostDomain(req.Host)
+	}
+	code, err := s.cutter.Cut(req.Context(), string(body), opts)
 	status := http.StatusCreated
 	if err != nil {
 		var uerr *cutter.UniqueURLError
-		if !errors.As(err, &uerr) {
+		switch {
+		case errors.Is(err, cutter.ErrorShortURLTaken):
+			responseStatusError(res, http.StatusConflict, fmt.Errorf("cutterHandler: %w", err))
+			return
+		case isPolicyError(err):
+			responseStatusError(res, http.StatusUnprocessableEntity, fmt.Errorf("cutterHandler: %w", err))
+			return
+		case !errors.As(err, &uerr):
 			responseError(res, fmt.Errorf("cutterHandler: getting code for url: %w", err))
 			return
 		}
@@ -236,18 +320,32 @@ func (s Server) cutterHandler(res http.ResponseWriter, req *http.Request) {
 	}
 	res.Header().Set("Content-Type", "text/plain")
 	res.WriteHeader(status)
-	res.Write([]byte(fmt.Sprintf("%s/%s", s.config.GetShortAddress(), code)))
+	res.Write([]byte(s.shortURL(code)))
 }
 
 // redirectHandler godoc
 // @Tags Operate
 // @Summary Переход по сокращеному URL
+// @Description Путь после сокращения (/{path}/...) и параметры запроса передаются в URL по настройкам сокращения.
+// @Description Сокращение ищется на домене сокращений из заголовка Host: один код может существовать на разных доменах.
+// @Description URL перехода выбирается по правилам перехода сокращения, см. /api/user/urls/{short}/rules.
+// @Description Если правила не подошли, для сокращения с вариантами URL вариант выбирается случайно по весам;
+// @Description при sticky_variant выбранный вариант закрепляется за посетителем cookie urlcut_variant_{path}.
+// @Description Код редиректа или страница перехода выбираются по redirect_type сокращения, по умолчанию - 307.
+// @Description Запрос /{path}+ или с параметром preview=1 вместо перехода отдает страницу предпросмотра.
 // @ID redirect
 // @Accept  plain/text
+// @Produce html
 // @Param path path string true "Сокращенный url"
+// @Param preview query bool false "Показать страницу предпросмотра вместо перехода"
+// @Success 301 "Переход по сокращенному URL, redirect_type 301"
+// @Success 302 "Переход по сокращенному URL, redirect_type 302"
 // @Success 307 "Переход по сокращенному URL"
+// @Success 308 "Переход по сокращенному URL, redirect_type 308"
+// @Success 200 {string} string "Форма ввода пароля для сокращения с паролем, страница перехода meta-refresh, interstitial или предпросмотра"
 // @Failure 401 {string} string "Ошибка авторизации"
-// @Failure 410 {string} string "url was deleted"
+// @Failure 404 {string} string "Сокращение еще не работает (active_from) - пустой ответ или страница \"скоро\""
+// @Failure 410 {string} string "url was deleted, url has expired или url click limit is exhausted"
 // @Failure 400 {string} string "Ошибка"
 // @Router /{path} [get]
 func (s Server) redirectHandler(res http.ResponseWriter, req *http.Request) {
@@ -256,10 +354,22 @@ func (s Server) redirectHandler(res http.ResponseWriter, req *http.Request) {
 		responseError(res, fmt.Errorf("redirectHandler: url path is empty"))
 		return
 	}
+	if short, isPreview := previewShort(req, path); isPreview {
+		s.previewHandler(res, req, short)
+		return
+	}
 
-	redirectURL, err := s.cutter.GetKeyByValue(req.Context(), path)
+	v := s.visitFromRequest(req, path)
+	target, err := s.cutter.Redirect(req.Context(), v)
 	if err != nil {
-		if errors.Is(err, dbstore.ErrorDeletedURL) {
+		if s.responseNotActive(res, err) {
+			return
+		}
+		if errors.Is(err, cutter.ErrorPasswordRequired) {
+			renderPasswordForm(res, http.StatusOK, passwordFormData{Action: req.URL.RequestURI()})
+			return
+		}
+		if isGone(err) {
 			res.WriteHeader(http.StatusGone)
 			res.Write([]byte(err.Error()))
 			return
@@ -267,7 +377,79 @@ func (s Server) redirectHandler(res http.ResponseWriter, req *http.Request) {
 		responseError(res, fmt.Errorf("redirectHandler: fetching url fo redirect: %w", err))
 		return
 	}
-	http.Redirect(res, req, redirectURL, http.StatusTemporaryRedirect)
+	s.recordClick(req, v.Short, target)
+	setVariantCookie(res, path, target)
+	if !renderTarget(res, target) {
+		http.Redirect(res, req, target.URL, redirectStatus(target.RedirectType))
+	}
+}
+
+// Cookie закрепленного за посетителем варианта URL сокращения: имя - префикс и сокращение.
+const (
+	variantCookiePrefix = "urlcut_variant_"
+	variantCookieAge    = 30 * 24 * time.Hour
+)
+
+// visitFromRequest возвращает параметры перехода по сокращению code домена запроса, см. hostDomain.
+// Закрепленный вариант URL читается из cookie сокращения, см. setVariantCookie.
+func (s Server) visitFromRequest(req *http.Request, code string) jsonobject.Visit {
+	v := jsonobject.Visit{
+		Short:  cutter.LinkKey(s.hostDomain(req.Host), code),
+		Path:   chi.URLParam(req, "*"),
+		Query:  req.URL.Query(),
+		Header: req.Header,
+	}
+	if cookie, err := req.Cookie(variantCookiePrefix + code); err == nil {
+		v.Variant = cookie.Value
+	}
+	return v
+}
+
+// recordClick передает переход по сокращению short в статистику переходов.
+func (s Server) recordClick(req *http.Request, short string, target jsonobject.Target) {
+	s.cutter.RecordClick(short, target.Variant, req.Referer(), req.UserAgent(), s.clientIP(req))
+}
+
+// setVariantCookie закрепляет за посетителем выбранный вариант URL сокращения code,
+// если у сокращения включено закрепление варианта. Cookie действует только на домене запроса.
+func setVariantCookie(res http.ResponseWriter, code string, target jsonobject.Target) {
+	if !target.Sticky {
+		return
+	}
+	http.SetCookie(res, &http.Cookie{
+		Name:     variantCookiePrefix + code,
+		Value:    target.Variant,
+		Path:     "/" + code,
+		MaxAge:   int(variantCookieAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clientIP возвращает IP клиента - адрес соединения.
+// Заголовки X-Real-IP и X-Forwarded-For учитываются, только если соединение установлено доверенным прокси:
+// из X-Forwarded-For берется последний адрес, не принадлежащий доверенным прокси.
+func (s Server) clientIP(req *http.Request) string {
+	ip := remoteIP(req)
+	proxies := s.config.GetTrustedProxies()
+	if !inSubnets(ip, proxies) {
+		return ip
+	}
+	if realIP := strings.TrimSpace(req.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	hops := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !inSubnets(hop, proxies) {
+			break
+		}
+	}
+	return ip
 }
 
 // pingHandler godoc
@@ -296,7 +478,11 @@ func (s Server) pingHandler(res http.ResponseWriter, req *http.Request) {
 // @ID cutterBatch
 // @Accept  json
 // @Produce json
+// @Param request body jsonobject.Batch true "Список URL и параметров сокращения"
+// @Param qr query bool false "Добавить в ответ адреса QR-кодов"
 // @Success 201 {object} jsonobject.Batch
+// @Failure 409 {string} string "Сокращение (alias) уже занято"
+// @Failure 422 {string} string "URL отклонен политикой сервиса"
 // @Failure 401 {string} string "Ошибка авторизации"
 // @Failure 400 {string} string "Ошибка"
 // @Router /api/shorten/batch [post]
@@ -318,14 +504,31 @@ func (s Server) cutterJSONBatchHandler(res http.ResponseWriter, req *http.Reques
 		return
 	}
 	logging.Log.Info(batchRequest)
+	for i := range batchRequest {
+		if batchRequest[i].Domain == "" {
+			batchRequest[i].Domain = s.hostDomain(req.Host)
+		}
+	}
 	batchResponse, err := s.cutter.UploadBatch(req.Context(), batchRequest)
+	if errors.Is(err, cutter.ErrorShortURLTaken) {
+		responseStatusError(res, http.StatusConflict, fmt.Errorf("JSONBatchHandler: %w", err))
+		return
+	}
+	if isPolicyError(err) {
+		responseStatusError(res, http.StatusUnprocessableEntity, fmt.Errorf("JSONBatchHandler: %w", err))
+		return
+	}
 	if err != nil {
 		responseError(res, fmt.Errorf("JSONBatchHandler: getting code for url: %w", err))
 		return
 	}
 
+	withQR := wantQR(req)
 	for i := 0; i < len(batchResponse); i++ {
-		batchResponse[i].ShortURL = fmt.Sprintf("%s/%s", s.config.GetShortAddress(), batchResponse[i].ShortURL)
+		if withQR {
+			batchResponse[i].QR = s.qrURL(batchResponse[i].ShortURL)
+		}
+		batchResponse[i].ShortURL = s.shortURL(batchResponse[i].ShortURL)
 	}
 
 	res.Header().Set("Content-Type", "application/json")
@@ -370,7 +573,7 @@ func (s Server) userUrlsHandler(res http.ResponseWriter, req *http.Request) {
 	}
 
 	for i := 0; i < len(urls); i++ {
-		urls[i].ShortURL = fmt.Sprintf("%s/%s", s.config.GetShortAddress(), urls[i].ShortURL)
+		urls[i].ShortURL = s.shortURL(urls[i].ShortURL)
 	}
 
 	res.Header().Set("Content-Type", "application/json")
@@ -386,12 +589,15 @@ func (s Server) userUrlsHandler(res http.ResponseWriter, req *http.Request) {
 // deleteUserUrlsHandler godoc
 // @Tags UserURLs
 // @Summary Запрос на удаление сокращеных URL
+// @Description Удаление выполняется асинхронно, состояние задачи доступно по ее ID, см. deleteJob
 // @ID deleteUserUrls
 // @Accept  json
-// @Produce plain/text
-// @Success 202 {object} jsonobject.ShortIds
+// @Produce json
+// @Param request body jsonobject.ShortIds true "Сокращения для удаления: коды на домене запроса или домен/код"
+// @Success 202 {object} jsonobject.DeleteJob "Созданная задача удаления"
+// @Failure 401 {string} string "Ошибка авторизации"
 // @Failure 400 {string} string "Ошибка"
-// @Router / [post]
+// @Router /api/user/urls [delete]
 func (s Server) deleteUserUrlsHandler(res http.ResponseWriter, req *http.Request) {
 	err, _ := req.Context().Value(config.ErrorCtxKey).(error)
 	if err != nil {
@@ -424,11 +630,400 @@ func (s Server) deleteUserUrlsHandler(res http.ResponseWriter, req *http.Request
 		responseError(res, errors.New("CheckIsUserURL, wrong user type in context"))
 		return
 	}
-	go s.cutter.DeleteUrls(userID, ids)
+	job, err := s.cutter.DeleteUrls(req.Context(), userID, s.linkKeys(req, ids))
+	if err != nil {
+		responseError(res, fmt.Errorf("deleteUserUrlsHandler: %w", err))
+		return
+	}
+	respb, err := job.MarshalJSON()
+	if err != nil {
+		responseError(res, fmt.Errorf("deleteUserUrlsHandler: encoding response: %w", err))
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
 	res.WriteHeader(http.StatusAccepted)
+	res.Write(respb)
+}
+
+// deleteJobHandler godoc
+// @Tags UserURLs
+// @Summary Состояние задачи удаления сокращений пользователя
+// @ID deleteJob
+// @Produce json
+// @Param id path string true "ID задачи"
+// @Success 200 {object} jsonobject.DeleteJob
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 404 {string} string "Задача не найдена"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/delete-jobs/{id} [get]
+func (s Server) deleteJobHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	job, err := s.cutter.GetDeleteJob(req.Context(), userID, chi.URLParam(req, "id"))
+	switch {
+	case errors.Is(err, cutter.ErrorJobNotFound):
+		responseStatusError(res, http.StatusNotFound, fmt.Errorf("deleteJobHandler: %w", err))
+		return
+	case err != nil:
+		responseError(res, fmt.Errorf("deleteJobHandler: %w", err))
+		return
+	}
+	respb, err := job.MarshalJSON()
+	if err != nil {
+		responseError(res, fmt.Errorf("deleteJobHandler: encoding response: %w", err))
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	res.Write(respb)
+}
+
+// trashHandler godoc
+// @Tags UserURLs
+// @Summary Удаленные сокращения пользователя, начиная с удаленных последними
+// @ID trash
+// @Produce json
+// @Success 200 {object} jsonobject.Batch
+// @Success 204 {string} string "Удаленных сокращений нет"
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/trash [get]
+func (s Server) trashHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	urls, err := s.cutter.Trash(req.Context(), userID)
+	if err != nil {
+		responseError(res, fmt.Errorf("trashHandler: %w", err))
+		return
+	}
+	if len(urls) == 0 {
+		res.WriteHeader(http.StatusNoContent)
+		return
+	}
+	for i := 0; i < len(urls); i++ {
+		urls[i].ShortURL = s.shortURL(urls[i].ShortURL)
+	}
+	respb, err := urls.MarshalJSON()
+	if err != nil {
+		responseError(res, fmt.Errorf("trashHandler: encoding response: %w", err))
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	res.Write(respb)
+}
+
+// restoreHandler godoc
+// @Tags UserURLs
+// @Summary Восстановление удаленных сокращений пользователя
+// @ID restore
+// @Accept  json
+// @Produce json
+// @Param request body jsonobject.ShortIds true "Сокращения для восстановления: коды на домене запроса или домен/код"
+// @Success 200 {object} jsonobject.ShortIds "Восстановленные сокращения"
+// @Success 204 {string} string "Ни одно сокращение не восстановлено"
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/restore [post]
+func (s Server) restoreHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		responseError(res, fmt.Errorf("restoreHandler: content-type have to be application/json"))
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		responseError(res, fmt.Errorf("restoreHandler: reading request body: %w", err))
+		return
+	}
+	var ids jsonobject.ShortIds
+	if err = ids.UnmarshalJSON(body); err != nil {
+		responseError(res, fmt.Errorf("restoreHandler: decoding request: %w", err))
+		return
+	}
+	restored, err := s.cutter.Restore(req.Context(), userID, s.linkKeys(req, ids))
+	if err != nil {
+		responseError(res, fmt.Errorf("restoreHandler: %w", err))
+		return
+	}
+	if len(restored) == 0 {
+		res.WriteHeader(http.StatusNoContent)
+		return
+	}
+	respb, err := restored.MarshalJSON()
+	if err != nil {
+		responseError(res, fmt.Errorf("restoreHandler: encoding response: %w", err))
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	res.Write(respb)
+}
+
+// retargetHandler godoc
+// @Tags UserURLs
+// @Summary Замена оригинального URL сокращения пользователя
+// @ID retarget
+// @Accept  json
+// @Produce json
+// @Param short path string true "Сокращение"
+// @Param request body jsonobject.Request true "Новый URL"
+// @Success 200 {object} jsonobject.BatchItem
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 403 {string} string "Сокращение создано другим пользователем"
+// @Failure 404 {string} string "Сокращение не найдено"
+// @Failure 409 {string} string "URL уже сокращен"
+// @Failure 410 {string} string "url was deleted"
+// @Failure 422 {string} string "URL отклонен политикой сервиса"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/{short} [patch]
+func (s Server) retargetHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		responseError(res, fmt.Errorf("retargetHandler: content-type have to be application/json"))
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		responseError(res, fmt.Errorf("retargetHandler: reading request body: %w", err))
+		return
+	}
+	var reqJSON jsonobject.Request
+	if err = reqJSON.UnmarshalJSON(body); err != nil {
+		responseError(res, fmt.Errorf("retargetHandler: decoding request: %w", err))
+		return
+	}
+	short := s.shortParam(req)
+	original, err := s.cutter.Retarget(req.Context(), userID, short, reqJSON.URL)
+	if err != nil {
+		responseStatusError(res, userURLErrorStatus(err), fmt.Errorf("retargetHandler: %w", err))
+		return
+	}
+	s.responseUserURL(res, short, original)
+}
+
+// historyHandler godoc
+// @Tags UserURLs
+// @Summary Прежние оригинальные URL сокращения пользователя, начиная с самого позднего
+// @ID history
+// @Produce json
+// @Param short path string true "Сокращение"
+// @Success 200 {object} jsonobject.History
+// @Success 204 {string} string "URL сокращения не менялся"
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 403 {string} string "Сокращение создано другим пользователем"
+// @Failure 404 {string} string "Сокращение не найдено"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/{short}/history [get]
+func (s Server) historyHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	history, err := s.cutter.History(req.Context(), userID, s.shortParam(req))
+	if err != nil {
+		responseStatusError(res, userURLErrorStatus(err), fmt.Errorf("historyHandler: %w", err))
+		return
+	}
+	if len(history) == 0 {
+		res.WriteHeader(http.StatusNoContent)
+		return
+	}
+	respb, err := history.MarshalJSON()
+	if err != nil {
+		responseError(res, fmt.Errorf("historyHandler: encoding response: %w", err))
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	res.Write(respb)
+}
+
+// statsHandler godoc
+// @Tags UserURLs
+// @Summary Статистика переходов по сокращению пользователя
+// @ID stats
+// @Produce json
+// @Param short path string true "Сокращение"
+// @Success 200 {object} jsonobject.LinkStats
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 403 {string} string "Сокращение создано другим пользователем"
+// @Failure 404 {string} string "Сокращение не найдено"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/{short}/stats [get]
+func (s Server) statsHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	stats, err := s.cutter.LinkStats(req.Context(), userID, s.shortParam(req))
+	if err != nil {
+		responseStatusError(res, userURLErrorStatus(err), fmt.Errorf("statsHandler: %w", err))
+		return
+	}
+	respb, err := stats.MarshalJSON()
+	if err != nil {
+		responseError(res, fmt.Errorf("statsHandler: encoding response: %w", err))
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	res.Write(respb)
+}
+
+// rollbackHandler godoc
+// @Tags UserURLs
+// @Summary Возврат сокращению пользователя предыдущего оригинального URL
+// @ID rollback
+// @Produce json
+// @Param short path string true "Сокращение"
+// @Success 200 {object} jsonobject.BatchItem
+// @Failure 401 {string} string "Ошибка авторизации"
+// @Failure 403 {string} string "Сокращение создано другим пользователем"
+// @Failure 404 {string} string "Сокращение не найдено"
+// @Failure 409 {string} string "URL сокращения не менялся или прежний URL уже сокращен"
+// @Failure 410 {string} string "url was deleted"
+// @Failure 400 {string} string "Ошибка"
+// @Router /api/user/urls/{short}/rollback [post]
+func (s Server) rollbackHandler(res http.ResponseWriter, req *http.Request) {
+	userID, err := userFromRequest(req)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	short := s.shortParam(req)
+	original, err := s.cutter.Rollback(req.Context(), userID, short)
+	if err != nil {
+		responseStatusError(res, userURLErrorStatus(err), fmt.Errorf("rollbackHandler: %w", err))
+		return
+	}
+	s.responseUserURL(res, short, original)
+}
+
+// responseUserURL отвечает сокращением пользователя и его текущим оригинальным URL.
+func (s Server) responseUserURL(res http.ResponseWriter, short, original string) {
+	item := jsonobject.BatchItem{
+		ShortURL:    s.shortURL(short),
+		OriginalURL: original,
+	}
+	respb, err := item.MarshalJSON()
+	if err != nil {
+		responseError(res, fmt.Errorf("encoding response: %w", err))
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusOK)
+	res.Write(respb)
+}
+
+// userFromRequest возвращает ID пользователя, установленный middleware Auth.
+func userFromRequest(req *http.Request) (string, error) {
+	if err, _ := req.Context().Value(config.ErrorCtxKey).(error); err != nil {
+		return "", err
+	}
+	userID, _ := req.Context().Value(config.UserCtxKey).(string)
+	if userID == "" {
+		return "", errors.New("no user in context")
+	}
+	return userID, nil
+}
+
+// userURLErrorStatus возвращает статус ответа для ошибки операции над сокращением пользователя.
+func userURLErrorStatus(err error) int {
+	var uerr *cutter.UniqueURLError
+	switch {
+	case errors.Is(err, cutter.ErrorURLNotFound), errors.Is(err, cutter.ErrorRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, cutter.ErrorNotOwner):
+		return http.StatusForbidden
+	case errors.Is(err, cutter.ErrorNoHistory), errors.As(err, &uerr):
+		return http.StatusConflict
+	case isGone(err):
+		return http.StatusGone
+	case isPolicyError(err):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// linkOptionsFromQuery читает параметры сокращения из query-параметров запроса.
+func linkOptionsFromQuery(q url.Values) (jsonobject.LinkOptions, error) {
+	opts := jsonobject.LinkOptions{Alias: q.Get("alias"), Title: q.Get("title"), Domain: q.Get("domain")}
+	if v := q.Get("expires_at"); v != "" {
+		exp, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return opts, fmt.Errorf("parsing expires_at: %w", err)
+		}
+		opts.ExpiresAt = &exp
+	}
+	if v := q.Get("active_from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return opts, fmt.Errorf("parsing active_from: %w", err)
+		}
+		opts.ActiveFrom = &from
+	}
+	if v := q.Get("ttl"); v != "" {
+		ttl, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("parsing ttl: %w", err)
+		}
+		opts.TTL = ttl
+	}
+	if v := q.Get("max_clicks"); v != "" {
+		clicks, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, fmt.Errorf("parsing max_clicks: %w", err)
+		}
+		opts.MaxClicks = clicks
+	}
+	opts.PassQuery = q.Get("pass_query")
+	opts.RedirectType = q.Get("redirect_type")
+	if v := q.Get("pass_path"); v != "" {
+		passPath, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("parsing pass_path: %w", err)
+		}
+		opts.PassPath = passPath
+	}
+	return opts, nil
+}
+
+// isGone сообщает, что сокращение больше не работает.
+func isGone(err error) bool {
+	return errors.Is(err, cutter.ErrorDeletedURL) ||
+		errors.Is(err, cutter.ErrorExpiredURL) ||
+		errors.Is(err, cutter.ErrorClicksExhausted)
+}
+
+// isPolicyError сообщает, что URL отклонен политикой сервиса.
+func isPolicyError(err error) bool {
+	var perr *cutter.PolicyError
+	return errors.As(err, &perr)
 }
 
 func responseError(res http.ResponseWriter, err error) {
-	res.WriteHeader(http.StatusBadRequest)
+	responseStatusError(res, http.StatusBadRequest, err)
+}
+
+func responseStatusError(res http.ResponseWriter, status int, err error) {
+	res.WriteHeader(status)
 	res.Write([]byte(err.Error()))
 }
@@ -9,12 +9,17 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"image/png"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/url"
+	"os"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -50,10 +55,15 @@ func (errReader) Read(p []byte) (n int, err error) {
 }
 
 type TestConfig struct {
-	url           string
-	shortAddress  string
-	fileStoreName string
-	dbConnName    string
+	url            string
+	shortAddress   string
+	fileStoreName  string
+	dbConnName     string
+	uniqueness     string
+	trustedSubnet  string
+	trustedProxies []string
+	shortDomains   []string
+	comingSoonPage bool
 }
 
 var tconf *TestConfig
@@ -78,17 +88,69 @@ func (c TestConfig) GetUserContextKey() config.ContextKey {
 func (c TestConfig) GetEnableHTTPS() bool {
 	return false
 }
+
+func (c TestConfig) GetURLUniqueness() string {
+	return c.uniqueness
+}
+
+func (c TestConfig) GetTrustedSubnet() string {
+	return c.trustedSubnet
+}
+
+func (c TestConfig) GetTrustedProxies() []string {
+	return c.trustedProxies
+}
+
+func (c TestConfig) GetShortDomains() []string {
+	return c.shortDomains
+}
+
+func (c TestConfig) GetComingSoonPage() bool {
+	return c.comingSoonPage
+}
+
+func (c TestConfig) GetFileSync() string {
+	return config.FileSyncNever
+}
+
+func (c TestConfig) GetFileSyncInterval() time.Duration {
+	return time.Second
+}
+
+func (c TestConfig) GetFileCompactInterval() time.Duration {
+	return time.Hour
+}
+
 func initEnv() (serv *Server, testserver *httptest.Server) {
+	return initEnvUniqueness(config.URLUniqueGlobal)
+}
+
+func initEnvUniqueness(uniqueness string) (serv *Server, testserver *httptest.Server) {
+	f, err := os.CreateTemp("", "short-url-db-*.json")
+	if err != nil {
+		panic(err)
+	}
+	if err = f.Close(); err != nil {
+		panic(err)
+	}
 	tconf = &TestConfig{
 		url:           ":8080",
 		shortAddress:  "http://localhost:8080/",
-		fileStoreName: "/tmp/short-url-db.json"}
+		fileStoreName: f.Name(),
+		uniqueness:    uniqueness}
 
 	storage, err := store.New(context.Background(), tconf)
 	if err != nil {
 		panic(err)
 	}
-	cut := cutter.New(storage)
+	cut, err := cutter.New(storage, config.Config{})
+	if err != nil {
+		panic(err)
+	}
+	if err = cut.StartDeleteWorkers(context.Background(), 1); err != nil {
+		panic(err)
+	}
+	cut.StartClickWriter(context.Background())
 	serv = New(cut, tconf)
 	testserver = httptest.NewServer(serv.mux)
 	tconf.shortAddress = testserver.URL
@@ -295,7 +357,7 @@ func TestRedirectHandler(t *testing.T) {
 			},
 			expResp: expectedResponse{
 				code:        http.StatusBadRequest,
-				bodyMessage: "redirectHandler: fetching url fo redirect: getKeyByValue: while getting value by key:C222: no data found in urlMap for value C222"},
+				bodyMessage: "redirectHandler: fetching url fo redirect: redirect: while getting value by key:C222: no data found in urlMap for value C222"},
 		},
 		{
 			name: "positive",
@@ -334,6 +396,984 @@ func TestRedirectHandler(t *testing.T) {
 	}
 }
 
+func TestRedirectExpired(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	res, err := testserver.Client().Post(testserver.URL+"?ttl=1", "text/plain", strings.NewReader(positiveURL))
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	time.Sleep(1100 * time.Millisecond)
+	res, err = testserver.Client().Get(string(b))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, res.Body.Close())
+	}()
+	assert.Equal(t, http.StatusGone, res.StatusCode)
+	b, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), cutter.ErrorExpiredURL.Error())
+}
+
+func TestRedirectDeleted(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	do := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, testserver.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(b)
+	}
+
+	status, short := do(http.MethodPost, "/", positiveURL)
+	require.Equal(t, http.StatusCreated, status)
+	short = strings.TrimPrefix(short, testserver.URL+"/")
+	status, _ = do(http.MethodGet, "/"+short, "")
+	require.Equal(t, http.StatusTemporaryRedirect, status)
+
+	status, _ = do(http.MethodDelete, "/api/user/urls", `["`+short+`"]`)
+	require.Equal(t, http.StatusAccepted, status)
+	var body string
+	require.Eventually(t, func() bool {
+		status, body = do(http.MethodGet, "/"+short, "")
+		return status == http.StatusGone
+	}, time.Second, 10*time.Millisecond, "deleted url in file store")
+	assert.Contains(t, body, cutter.ErrorDeletedURL.Error())
+}
+
+func TestRetargetDeleted(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar}
+	do := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, testserver.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(b)
+	}
+
+	status, short := do(http.MethodPost, "/", positiveURL)
+	require.Equal(t, http.StatusCreated, status)
+	short = strings.TrimPrefix(short, testserver.URL+"/")
+	status, _ = do(http.MethodDelete, "/api/user/urls", `["`+short+`"]`)
+	require.Equal(t, http.StatusAccepted, status)
+	require.Eventually(t, func() bool {
+		status, _ = do(http.MethodGet, "/"+short, "")
+		return status == http.StatusGone
+	}, time.Second, 10*time.Millisecond)
+
+	status, body := do(http.MethodPatch, "/api/user/urls/"+short, `{"url":"http://mail.ru"}`)
+	assert.Equal(t, http.StatusGone, status, "deleted url is not retargeted")
+	assert.Contains(t, body, cutter.ErrorDeletedURL.Error())
+	status, _ = do(http.MethodGet, "/api/user/urls/"+short+"/rules", "")
+	assert.Equal(t, http.StatusGone, status, "rules of deleted url")
+}
+
+func TestRedirectClicksLimit(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	res, err := testserver.Client().Post(testserver.URL+"?max_clicks=1", "text/plain", strings.NewReader(positiveURL))
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	for _, code := range []int{http.StatusTemporaryRedirect, http.StatusGone} {
+		res, err = client.Get(string(b))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, code, res.StatusCode)
+	}
+}
+
+func TestPasswordProtected(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	body := fmt.Sprintf(`{"url":%q,"password":"secret"}`, positiveURL)
+	res, err := testserver.Client().Post(fmt.Sprintf(JSONPathPattern, testserver.URL), "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	var resp jsonobject.Response
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	require.NoError(t, resp.UnmarshalJSON(b))
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err = client.Get(resp.Result)
+	require.NoError(t, err)
+	b, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, res.Header.Get("Content-Type"), "text/html")
+	assert.Contains(t, string(b), `name="password"`)
+
+	res, err = client.PostForm(resp.Result, url.Values{"password": {"wrong"}})
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	res, err = client.PostForm(resp.Result, url.Values{"password": {"secret"}})
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+	assert.Equal(t, positiveURL, res.Header.Get("Location"))
+}
+
+func TestRedirectPassthrough(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	original := "https://ya.ru/landing?src=site"
+	res, err := testserver.Client().Post(testserver.URL+"?pass_query=keep&pass_path=true", "text/plain", strings.NewReader(original))
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err = client.Get(string(b) + "/extra/path?src=mail&ref=tg")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+	assert.Equal(t, "https://ya.ru/landing/extra/path?src=site&ref=tg", res.Header.Get("Location"))
+
+	res, err = testserver.Client().Post(testserver.URL+"?pass_query=merge", "text/plain", strings.NewReader(positiveURL))
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestRedirectType(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	tests := []struct {
+		redirectType string
+		bodyPattern  string
+		code         int
+	}{
+		{redirectType: "", code: http.StatusTemporaryRedirect},
+		{redirectType: "301", code: http.StatusMovedPermanently},
+		{redirectType: "302", code: http.StatusFound},
+		{redirectType: "308", code: http.StatusPermanentRedirect},
+		{redirectType: "meta-refresh", code: http.StatusOK, bodyPattern: `http-equiv="refresh" content="0; url=https://ya.ru/to-%s"`},
+		{redirectType: "interstitial", code: http.StatusOK, bodyPattern: `<a href="https://ya.ru/to-%s" rel="noopener noreferrer">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.redirectType, func(t *testing.T) {
+			original := "https://ya.ru/to-" + tt.redirectType
+			res, err := testserver.Client().Post(testserver.URL+"?redirect_type="+tt.redirectType, "text/plain", strings.NewReader(original))
+			require.NoError(t, err)
+			b, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			require.Equal(t, http.StatusCreated, res.StatusCode)
+
+			res, err = client.Get(string(b))
+			require.NoError(t, err)
+			b, err = io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, tt.code, res.StatusCode)
+			if tt.bodyPattern == "" {
+				assert.Equal(t, original, res.Header.Get("Location"))
+				return
+			}
+			assert.Contains(t, res.Header.Get("Content-Type"), "text/html")
+			assert.Contains(t, string(b), fmt.Sprintf(tt.bodyPattern, tt.redirectType))
+		})
+	}
+
+	res, err := testserver.Client().Post(testserver.URL+"?redirect_type=303", "text/plain", strings.NewReader(positiveURL))
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestPreview(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	res, err := testserver.Client().Post(testserver.URL+"?max_clicks=1&pass_query=keep&title="+url.QueryEscape("Весенняя распродажа"),
+		"text/plain", strings.NewReader("https://ya.ru/sale"))
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	shortURL := string(b)
+	short := strings.TrimPrefix(shortURL, testserver.URL)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	tests := []struct {
+		name        string
+		url         string
+		destination string
+		continueURL string
+	}{
+		{name: "suffix", url: shortURL + "+", destination: "https://ya.ru/sale", continueURL: short},
+		{name: "query", url: shortURL + "?preview=1&ref=tg", destination: "https://ya.ru/sale?ref=tg", continueURL: short + "?ref=tg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := client.Get(tt.url)
+			require.NoError(t, err)
+			b, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Contains(t, res.Header.Get("Content-Type"), "text/html")
+			page := string(b)
+			assert.Contains(t, page, "Весенняя распродажа")
+			assert.Contains(t, page, "<code>"+tt.destination+"</code>")
+			assert.Contains(t, page, fmt.Sprintf(`<time datetime="%s`, time.Now().UTC().Format(time.DateOnly)))
+			assert.Contains(t, page, "<dd>0</dd>")
+			assert.Contains(t, page, fmt.Sprintf(`<a href="%s" role="button">`, tt.continueURL))
+		})
+	}
+
+	// предпросмотр не списывает переходы: единственный переход остается доступным
+	res, err = client.Get(shortURL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+
+	res, err = client.Get(shortURL + "+")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusGone, res.StatusCode)
+}
+
+func TestQR(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	body := fmt.Sprintf(`{"url":%q,"alias":"qr-code"}`, positiveURL)
+	res, err := testserver.Client().Post(fmt.Sprintf(JSONPathPattern, testserver.URL)+"?qr=true", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var resp jsonobject.Response
+	require.NoError(t, resp.UnmarshalJSON(b))
+	assert.Equal(t, testserver.URL+"/api/qr/qr-code", resp.QR)
+
+	batch := `[{"correlation_id":"1","original_url":"http://qr.ru","alias":"qr-batch"}]`
+	res, err = testserver.Client().Post(fmt.Sprintf(JSONBatchPathPattern, testserver.URL)+"?qr=1", "application/json", strings.NewReader(batch))
+	require.NoError(t, err)
+	b, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var batchResp jsonobject.Batch
+	require.NoError(t, batchResp.UnmarshalJSON(b))
+	require.Len(t, batchResp, 1)
+	assert.Equal(t, testserver.URL+"/api/qr/qr-batch", batchResp[0].QR)
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		code        int
+	}{
+		{name: "png by default", query: "/api/qr/qr-code", code: http.StatusOK, contentType: "image/png"},
+		{name: "svg", query: "/api/qr/qr-code?format=svg&size=512&level=H", code: http.StatusOK, contentType: "image/svg+xml"},
+		{name: "size too small", query: "/api/qr/qr-code?size=10", code: http.StatusBadRequest},
+		{name: "unknown format", query: "/api/qr/qr-code?format=gif", code: http.StatusBadRequest},
+		{name: "unknown level", query: "/api/qr/qr-code?level=X", code: http.StatusBadRequest},
+		{name: "unknown short", query: "/api/qr/unknown", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := testserver.Client().Get(testserver.URL + tt.query)
+			require.NoError(t, err)
+			b, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, tt.code, res.StatusCode)
+			if tt.code != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.contentType, res.Header.Get("Content-Type"))
+			if tt.contentType == "image/png" {
+				img, err := png.Decode(bytes.NewReader(b))
+				require.NoError(t, err)
+				assert.LessOrEqual(t, img.Bounds().Dx(), 256)
+				return
+			}
+			assert.Contains(t, string(b), `<svg xmlns="http://www.w3.org/2000/svg"`)
+		})
+	}
+}
+
+func TestPerUserUniqueness(t *testing.T) {
+	_, testserver := initEnvUniqueness(config.URLUniqueUser)
+	defer testserver.Close()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	userA := &http.Client{Jar: jar}
+	// клиент без cookie каждый раз регистрируется как новый пользователь
+	userB := &http.Client{}
+
+	cut := func(client *http.Client) (int, string) {
+		res, err := client.Post(testserver.URL, "text/plain", strings.NewReader(positiveURL))
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(b)
+	}
+	status, shortA := cut(userA)
+	require.Equal(t, http.StatusCreated, status)
+	status, again := cut(userA)
+	assert.Equal(t, http.StatusConflict, status, "same user gets conflict")
+	assert.Equal(t, shortA, again)
+	status, shortB := cut(userB)
+	assert.Equal(t, http.StatusCreated, status, "other user gets own short url")
+	assert.NotEqual(t, shortA, shortB)
+}
+
+func TestPolicyRejected(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	for _, u := range []string{"javascript:alert(1)", "file:///etc/passwd", "http://127.0.0.1/admin"} {
+		res, err := testserver.Client().Post(testserver.URL, "text/plain", strings.NewReader(u))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode, u)
+
+		body := fmt.Sprintf(`[{"correlation_id":"1","original_url":%q}]`, u)
+		res, err = testserver.Client().Post(testserver.URL+"/api/shorten/batch", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode, u)
+	}
+}
+
+func TestRetarget(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	owner := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	do := func(client *http.Client, method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, testserver.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(b)
+	}
+
+	status, short := do(owner, http.MethodPost, "/", positiveURL)
+	require.Equal(t, http.StatusCreated, status)
+	short = strings.TrimPrefix(short, testserver.URL)
+	const newURL = "https://mail.ru/news"
+
+	status, body := do(owner, http.MethodPatch, "/api/user/urls"+short, `{"url":"`+newURL+`"}`)
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Contains(t, body, newURL)
+	status, body = do(owner, http.MethodGet, "/api/user/urls"+short+"/history", "")
+	require.Equal(t, http.StatusOK, status)
+	var history jsonobject.History
+	require.NoError(t, history.UnmarshalJSON([]byte(body)))
+	require.Len(t, history, 1)
+	assert.Equal(t, positiveURL, history[0].OriginalURL)
+	res, err := owner.Get(testserver.URL + short)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, newURL, res.Header.Get("Location"))
+
+	otherJar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	other := &http.Client{Jar: otherJar}
+	status, _ = do(other, http.MethodPost, "/", "http://other.ru")
+	require.Equal(t, http.StatusCreated, status)
+	status, _ = do(other, http.MethodPatch, "/api/user/urls"+short, `{"url":"http://other.ru/x"}`)
+	assert.Equal(t, http.StatusForbidden, status, "not owner")
+	status, _ = do(owner, http.MethodPatch, "/api/user/urls/unknown", `{"url":"http://other.ru"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = do(owner, http.MethodPatch, "/api/user/urls"+short, `{"url":"javascript:alert(1)"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+
+	status, body = do(owner, http.MethodPost, "/api/user/urls"+short+"/rollback", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, positiveURL)
+	status, _ = do(owner, http.MethodPost, "/api/user/urls"+short+"/rollback", "")
+	assert.Equal(t, http.StatusConflict, status, "history is empty")
+	status, _ = do(owner, http.MethodGet, "/api/user/urls"+short+"/history", "")
+	assert.Equal(t, http.StatusNoContent, status)
+}
+
+func TestRules(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	owner := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	do := func(method, path, body string, header http.Header) *http.Response {
+		req, err := http.NewRequest(method, testserver.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err := owner.Do(req)
+		require.NoError(t, err)
+		return res
+	}
+	call := func(method, path, body string) (int, string) {
+		res := do(method, path, body, nil)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(b)
+	}
+
+	status, short := call(http.MethodPost, "/?pass_path=true", "https://rules.ru/site")
+	require.Equal(t, http.StatusCreated, status)
+	short = strings.TrimPrefix(short, testserver.URL)
+	rules := "/api/user/urls" + short + "/rules"
+
+	status, _ = call(http.MethodGet, rules, "")
+	assert.Equal(t, http.StatusNoContent, status, "no rules")
+	status, body := call(http.MethodPost, rules, `{"devices":["mobile"],"url":"https://rules.ru/app"}`)
+	require.Equal(t, http.StatusCreated, status, body)
+	status, body = call(http.MethodPost, rules, `{"languages":["de"],"url":"https://rules.ru/de"}`)
+	require.Equal(t, http.StatusCreated, status, body)
+	status, body = call(http.MethodPut, rules+"/1", `{"header":{"name":"accept-language","op":"prefix","value":"de"},"url":"https://rules.ru/de"}`)
+	require.Equal(t, http.StatusOK, status, body)
+	var saved jsonobject.Rules
+	require.NoError(t, saved.UnmarshalJSON([]byte(body)))
+	require.Len(t, saved, 2)
+	assert.Equal(t, &jsonobject.HeaderMatch{Name: "Accept-Language", Op: "prefix", Value: "de"}, saved[1].Header)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{name: "mobile", header: http.Header{"User-Agent": {"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148"}},
+			want: "https://rules.ru/app/extra"},
+		{name: "language", header: http.Header{"Accept-Language": {"de-DE,de;q=0.9"}}, want: "https://rules.ru/de/extra"},
+		{name: "fallback", want: "https://rules.ru/site/extra"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := do(http.MethodGet, short+"/extra", "", tt.header)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+			assert.Equal(t, tt.want, res.Header.Get("Location"))
+		})
+	}
+
+	status, _ = call(http.MethodPost, rules, `{"url":"https://rules.ru/any"}`)
+	assert.Equal(t, http.StatusBadRequest, status, "rule without conditions")
+	status, _ = call(http.MethodPost, rules, `{"devices":["bot"],"url":"javascript:alert(1)"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	status, _ = call(http.MethodPut, rules+"/5", `{"devices":["bot"],"url":"https://rules.ru/bot"}`)
+	assert.Equal(t, http.StatusNotFound, status, "unknown rule")
+	status, _ = call(http.MethodDelete, rules+"/x", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = call(http.MethodGet, "/api/user/urls/unknown/rules", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, body = call(http.MethodDelete, rules+"/0", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.NotContains(t, body, "https://rules.ru/app")
+	status, _ = call(http.MethodPut, rules, `[]`)
+	assert.Equal(t, http.StatusNoContent, status, "all rules removed")
+	status, _ = call(http.MethodGet, rules, "")
+	assert.Equal(t, http.StatusNoContent, status)
+}
+
+func TestShortDomains(t *testing.T) {
+	const brand = "brand.test"
+	f, err := os.CreateTemp("", "short-url-db-*.json")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	conf := &TestConfig{fileStoreName: f.Name(), uniqueness: config.URLUniqueUser, shortDomains: []string{"http://" + brand}}
+	storage, err := store.New(context.Background(), conf)
+	require.NoError(t, err)
+	cut, err := cutter.New(storage, config.Config{ShortDomains: "http://" + brand})
+	require.NoError(t, err)
+	cut.StartClickWriter(context.Background())
+	testserver := httptest.NewServer(New(cut, conf).mux)
+	defer testserver.Close()
+	conf.shortAddress = testserver.URL
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	call := func(host, method, path, body string) (*http.Response, string) {
+		req, err := http.NewRequest(method, testserver.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		if host != "" {
+			req.Host = host
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res, string(b)
+	}
+
+	res, body := call("", http.MethodPost, "/?alias=sale&domain="+brand, "https://brand.ru/sale")
+	require.Equal(t, http.StatusCreated, res.StatusCode, body)
+	assert.Equal(t, "http://"+brand+"/sale", body)
+	res, body = call("", http.MethodPost, "/?alias=sale", "https://default.ru/sale")
+	require.Equal(t, http.StatusCreated, res.StatusCode, body)
+	assert.Equal(t, testserver.URL+"/sale", body, "same code on default domain")
+	res, body = call(brand, http.MethodPost, "/api/shorten", `{"url":"https://brand.ru/json"}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode, body)
+	assert.Contains(t, body, `"http://`+brand+`/`, "link is bound to request host")
+	res, _ = call("", http.MethodPost, "/api/shorten", `{"url":"https://brand.ru/unknown","domain":"unknown.test"}`)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, _ = call(brand, http.MethodGet, "/sale", "")
+	require.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+	assert.Equal(t, "https://brand.ru/sale", res.Header.Get("Location"))
+	res, _ = call("", http.MethodGet, "/sale", "")
+	require.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+	assert.Equal(t, "https://default.ru/sale", res.Header.Get("Location"))
+
+	res, body = call("", http.MethodPatch, "/api/user/urls/sale?domain="+brand, `{"url":"https://brand.ru/new"}`)
+	require.Equal(t, http.StatusOK, res.StatusCode, body)
+	assert.Contains(t, body, `"short_url":"http://`+brand+`/sale"`)
+	res, body = call("", http.MethodGet, "/api/user/urls/sale/history?domain="+brand, "")
+	require.Equal(t, http.StatusOK, res.StatusCode, body)
+	assert.Contains(t, body, "https://brand.ru/sale")
+	res, _ = call("", http.MethodGet, "/api/user/urls/sale/history", "")
+	assert.Equal(t, http.StatusNoContent, res.StatusCode, "default domain link was not retargeted")
+	res, _ = call("", http.MethodGet, "/api/qr/sale?domain="+brand, "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestScheduled(t *testing.T) {
+	serv, testserver := initEnv()
+	defer testserver.Close()
+	now := time.Now()
+	serv.cutter.(*cutter.App).SetClock(func() time.Time { return now })
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	get := func(path string) (int, string) {
+		res, err := client.Get(testserver.URL + path)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(b)
+	}
+
+	launch := now.Add(time.Hour).UTC().Truncate(time.Second)
+	res, err := client.Post(testserver.URL+"/?title=Launch&active_from="+launch.Format(time.RFC3339), "text/plain",
+		strings.NewReader("https://launch.ru"))
+	require.NoError(t, err)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(b))
+	short := strings.TrimPrefix(string(b), testserver.URL)
+
+	status, body := get(short)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.NotContains(t, body, "launch.ru")
+	status, _ = get(short + "+")
+	assert.Equal(t, http.StatusNotFound, status, "preview before launch")
+
+	tconf.comingSoonPage = true
+	status, body = get(short)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Contains(t, body, "Launch")
+	assert.Contains(t, body, launch.Format(time.RFC3339))
+	assert.NotContains(t, body, "launch.ru")
+
+	now = launch
+	status, _ = get(short)
+	assert.Equal(t, http.StatusTemporaryRedirect, status)
+}
+
+func TestVariants(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	call := func(method, path, body string) *http.Response {
+		req, err := http.NewRequest(method, testserver.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		return res
+	}
+
+	res := call(http.MethodPost, "/api/shorten", `{"url":"https://ab.ru","sticky_variant":true,
+		"variants":[{"url":"https://ab.ru/a","weight":1},{"name":"new","url":"https://ab.ru/b","weight":1}]}`)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(b))
+	var resp jsonobject.Response
+	require.NoError(t, resp.UnmarshalJSON(b))
+	short := strings.TrimPrefix(resp.Result, testserver.URL)
+
+	res = call(http.MethodGet, short, "")
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+	first := res.Header.Get("Location")
+	assert.Contains(t, []string{"https://ab.ru/a", "https://ab.ru/b"}, first)
+	require.Len(t, res.Cookies(), 1)
+	cookie := res.Cookies()[0]
+	assert.Equal(t, "urlcut_variant_"+strings.TrimPrefix(short, "/"), cookie.Name)
+	assert.Equal(t, short, cookie.Path)
+	assert.True(t, cookie.HttpOnly)
+	for i := 0; i < 5; i++ {
+		res = call(http.MethodGet, short, "")
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, first, res.Header.Get("Location"), "variant sticks to visitor")
+	}
+
+	var stats jsonobject.LinkStats
+	require.Eventually(t, func() bool {
+		res := call(http.MethodGet, "/api/user/urls"+short+"/stats", "")
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode == http.StatusOK && stats.UnmarshalJSON(b) == nil && stats.Total == 6
+	}, 3*time.Second, 50*time.Millisecond)
+	require.Len(t, stats.Variants, 1)
+	assert.Equal(t, int64(6), stats.Variants[0].Clicks)
+
+	res = call(http.MethodPost, "/api/shorten", `{"url":"https://ab.ru/one","variants":[{"url":"https://ab.ru/a","weight":1}]}`)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "single variant")
+}
+
+func TestTrashRestore(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar}
+	do := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, testserver.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(b)
+	}
+
+	status, short := do(http.MethodPost, "/", positiveURL)
+	require.Equal(t, http.StatusCreated, status)
+	short = strings.TrimPrefix(short, testserver.URL+"/")
+	status, _ = do(http.MethodGet, "/api/user/urls/trash", "")
+	assert.Equal(t, http.StatusNoContent, status)
+	status, body := do(http.MethodGet, "/api/user/urls", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `[{"original_url":"`+positiveURL+`","short_url":"`+testserver.URL+"/"+short+`"}]`, body)
+
+	status, _ = do(http.MethodDelete, "/api/user/urls", `["`+short+`"]`)
+	require.Equal(t, http.StatusAccepted, status)
+	var trash jsonobject.Batch
+	require.Eventually(t, func() bool {
+		status, body := do(http.MethodGet, "/api/user/urls/trash", "")
+		return status == http.StatusOK && trash.UnmarshalJSON([]byte(body)) == nil
+	}, time.Second, 10*time.Millisecond)
+	status, _ = do(http.MethodGet, "/"+short, "")
+	assert.Equal(t, http.StatusGone, status, "deleted url")
+	status, _ = do(http.MethodGet, "/api/user/urls", "")
+	assert.Equal(t, http.StatusNoContent, status, "deleted url is not listed")
+	require.Len(t, trash, 1)
+	assert.Equal(t, testserver.URL+"/"+short, trash[0].ShortURL)
+	assert.Equal(t, positiveURL, trash[0].OriginalURL)
+	assert.NotNil(t, trash[0].DeletedAt)
+
+	status, body = do(http.MethodPost, "/api/user/urls/restore", `["`+short+`","unknown"]`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `["`+short+`"]`, body)
+	status, _ = do(http.MethodGet, "/api/user/urls/trash", "")
+	assert.Equal(t, http.StatusNoContent, status)
+	status, _ = do(http.MethodPost, "/api/user/urls/restore", `["`+short+`"]`)
+	assert.Equal(t, http.StatusNoContent, status, "already restored")
+
+	status, _ = do(http.MethodPost, "/api/user/urls/restore", `not json`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	res, err := http.Get(testserver.URL + "/api/user/urls/trash")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestDeleteJob(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	newClient := func() *http.Client {
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+		return &http.Client{Jar: jar}
+	}
+	do := func(client *http.Client, method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, testserver.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(b)
+	}
+	owner, other := newClient(), newClient()
+	status, own := do(owner, http.MethodPost, "/", positiveURL)
+	require.Equal(t, http.StatusCreated, status)
+	status, foreign := do(other, http.MethodPost, "/", "http://other.ru")
+	require.Equal(t, http.StatusCreated, status)
+	own = strings.TrimPrefix(own, testserver.URL+"/")
+	foreign = strings.TrimPrefix(foreign, testserver.URL+"/")
+
+	status, body := do(owner, http.MethodDelete, "/api/user/urls", `["`+own+`","`+foreign+`","unknown"]`)
+	require.Equal(t, http.StatusAccepted, status)
+	var job jsonobject.DeleteJob
+	require.NoError(t, job.UnmarshalJSON([]byte(body)))
+	require.NotEmpty(t, job.ID)
+	assert.Equal(t, cutter.JobPending, job.State)
+
+	require.Eventually(t, func() bool {
+		status, body := do(owner, http.MethodGet, "/api/user/urls/delete-jobs/"+job.ID, "")
+		return status == http.StatusOK && job.UnmarshalJSON([]byte(body)) == nil && job.State == cutter.JobDone
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), job.Deleted)
+	assert.Equal(t, jsonobject.ShortIds{"unknown"}, job.NotFound)
+	assert.Equal(t, jsonobject.ShortIds{foreign}, job.NotOwned)
+
+	status, _ = do(other, http.MethodGet, "/api/user/urls/delete-jobs/"+job.ID, "")
+	assert.Equal(t, http.StatusNotFound, status, "job of another user")
+	status, _ = do(owner, http.MethodGet, "/api/user/urls/delete-jobs/unknown", "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestLinkStats(t *testing.T) {
+	_, testserver := initEnv()
+	defer testserver.Close()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	owner := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	do := func(client *http.Client, method, path string, header http.Header) (int, string) {
+		req, err := http.NewRequest(method, testserver.URL+path, strings.NewReader(positiveURL))
+		require.NoError(t, err)
+		for k := range header {
+			req.Header.Set(k, header.Get(k))
+		}
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, string(b)
+	}
+
+	status, short := do(owner, http.MethodPost, "/", nil)
+	require.Equal(t, http.StatusCreated, status)
+	short = strings.TrimPrefix(short, testserver.URL)
+	visits := []http.Header{
+		{"Referer": {"https://google.com/"}, "User-Agent": {"curl/8.0"}, "X-Real-Ip": {"10.0.0.1"}},
+		{"User-Agent": {"curl/8.0"}, "X-Real-Ip": {"10.0.0.1"}},
+		{"X-Forwarded-For": {"10.0.0.2, 192.168.0.1"}},
+	}
+	for _, h := range visits {
+		status, _ = do(owner, http.MethodGet, short, h)
+		require.Equal(t, http.StatusTemporaryRedirect, status)
+	}
+
+	var stats jsonobject.LinkStats
+	require.Eventually(t, func() bool {
+		status, body := do(owner, http.MethodGet, "/api/user/urls"+short+"/stats", nil)
+		return status == http.StatusOK && stats.UnmarshalJSON([]byte(body)) == nil && stats.Total == 3
+	}, 3*time.Second, 50*time.Millisecond)
+	assert.Equal(t, int64(1), stats.Unique, "proxy headers are ignored without trusted proxies")
+	require.Len(t, stats.Daily, 1)
+	assert.Equal(t, int64(3), stats.Daily[0].Clicks)
+	assert.Equal(t, jsonobject.ClickCount{Name: "(direct)", Clicks: 2}, stats.Referrers[0])
+	assert.Contains(t, stats.Browsers, jsonobject.ClickCount{Name: "curl", Clicks: 2})
+
+	otherJar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	other := &http.Client{Jar: otherJar}
+	status, _ = do(other, http.MethodPost, "/", nil)
+	require.Equal(t, http.StatusConflict, status, "cookie is issued anyway")
+	status, _ = do(other, http.MethodGet, "/api/user/urls"+short+"/stats", nil)
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = do(owner, http.MethodGet, "/api/user/urls/unknown/stats", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		realIP     string
+		forwarded  string
+		want       string
+	}{
+		{name: "no proxies", remoteAddr: "10.0.0.1:52000", want: "10.0.0.1"},
+		{name: "headers without trusted proxy", remoteAddr: "10.0.0.1:52000", realIP: "1.2.3.4", forwarded: "5.6.7.8",
+			want: "10.0.0.1"},
+		{name: "connection not from trusted proxy", proxies: []string{"192.168.0.0/24"}, remoteAddr: "10.0.0.1:52000",
+			realIP: "1.2.3.4", want: "10.0.0.1"},
+		{name: "X-Real-IP from trusted proxy", proxies: []string{"192.168.0.0/24"}, remoteAddr: "192.168.0.2:52000",
+			realIP: "1.2.3.4", forwarded: "5.6.7.8", want: "1.2.3.4"},
+		{name: "X-Forwarded-For skips trusted hops", proxies: []string{"192.168.0.0/24", "172.16.0.0/12"},
+			remoteAddr: "192.168.0.2:52000", forwarded: "9.9.9.9, 5.6.7.8, 172.16.0.3", want: "5.6.7.8"},
+		{name: "no headers from trusted proxy", proxies: []string{"192.168.0.0/24"}, remoteAddr: "192.168.0.2:52000",
+			want: "192.168.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Server{config: TestConfig{trustedProxies: tt.proxies}}
+			req := httptest.NewRequest(http.MethodGet, "/short", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			assert.Equal(t, tt.want, s.clientIP(req))
+		})
+	}
+}
+
+func TestPurgeStatsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tests := []struct {
+		name     string
+		subnet   string
+		remoteIP string
+		realIP   string
+		code     int
+	}{
+		{name: "positive", subnet: "192.168.0.0/24", remoteIP: "192.168.0.10", code: http.StatusOK},
+		{name: "negative - subnet not configured", remoteIP: "192.168.0.10", code: http.StatusForbidden},
+		{name: "negative - ip out of subnet", subnet: "192.168.0.0/24", remoteIP: "10.0.0.1", code: http.StatusForbidden},
+		{name: "negative - spoofed X-Real-IP", subnet: "192.168.0.0/24", remoteIP: "10.0.0.1", realIP: "192.168.0.10",
+			code: http.StatusForbidden},
+		{name: "positive - X-Real-IP is ignored", subnet: "192.168.0.0/24", remoteIP: "192.168.0.10", realIP: "10.0.0.1",
+			code: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := mocks.NewMockICutter(ctrl)
+			c := mocks.NewMockConfiger(ctrl)
+			c.EXPECT().GetTrustedSubnet().Return(tt.subnet).AnyTimes()
+			a.EXPECT().PurgeStats().Return(jsonobject.PurgeStats{LastPurged: 2, TotalPurged: 5}).MaxTimes(1)
+
+			s := New(a, c)
+			request := httptest.NewRequest(http.MethodGet, "/api/internal/purge", nil)
+			request.RemoteAddr = tt.remoteIP + ":52000"
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+			w := httptest.NewRecorder()
+			s.TrustedSubnet(http.HandlerFunc(s.purgeStatsHandler)).ServeHTTP(w, request)
+			res := w.Result()
+			defer func() {
+				require.NoError(t, res.Body.Close())
+			}()
+			assert.Equal(t, tt.code, res.StatusCode)
+			if tt.code != http.StatusOK {
+				return
+			}
+			var stats jsonobject.PurgeStats
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, stats.UnmarshalJSON(body))
+			assert.Equal(t, int64(5), stats.TotalPurged)
+		})
+	}
+}
+
 func TestCutterJSONHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -434,6 +1474,21 @@ func TestCutterJSONHandler(t *testing.T) {
 				cutterError:  &errorUnique,
 			},
 		},
+		{
+			name: "negative - alias taken",
+			request: postRequest{
+				httpMethod: http.MethodPost,
+				body:       strings.NewReader(`{"url":"http://mail.ru/","alias":"spring-sale"}`),
+				jsonHeader: true},
+			expResp: expectedPostResponse{
+				code:        http.StatusConflict,
+				bodyMessage: "cutterJsonHandler: " + cutter.ErrorShortURLTaken.Error()},
+			mock: mockParams{
+				shortAddress: serv.config.GetShortAddress(),
+				cutterResult: "",
+				cutterError:  cutter.ErrorShortURLTaken,
+			},
+		},
 		{
 			name: "positive",
 			request: postRequest{
@@ -454,7 +1509,8 @@ func TestCutterJSONHandler(t *testing.T) {
 			a := mocks.NewMockICutter(ctrl)
 			c := mocks.NewMockConfiger(ctrl)
 			c.EXPECT().GetShortAddress().Return(tt.mock.shortAddress).MaxTimes(1)
-			a.EXPECT().Cut(gomock.Any(), gomock.Any()).Return(tt.mock.cutterResult, tt.mock.cutterError).MaxTimes(1)
+			c.EXPECT().GetShortDomains().Return(nil).AnyTimes()
+			a.EXPECT().Cut(gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.mock.cutterResult, tt.mock.cutterError).MaxTimes(1)
 
 			s := New(a, c)
 			request, err := http.NewRequest(tt.request.httpMethod, url, tt.request.body)
@@ -634,6 +1690,7 @@ func TestCutterJSONBatchHandler(t *testing.T) {
 			a := mocks.NewMockICutter(ctrl)
 			c := mocks.NewMockConfiger(ctrl)
 			c.EXPECT().GetShortAddress().Return(tt.mock.shortAddress).MaxTimes(1)
+			c.EXPECT().GetShortDomains().Return(nil).AnyTimes()
 			a.EXPECT().UploadBatch(gomock.Any(), gomock.Any()).Return(tt.mock.uploadResult, tt.mock.uploadError).MaxTimes(1)
 			s := New(a, c)
 			//init request
@@ -823,7 +1880,7 @@ func BenchmarkCutterJSONHandler(b *testing.B) {
 	a := mocks.NewMockICutter(ctrl)
 	c := mocks.NewMockConfiger(ctrl)
 	s := New(a, c)
-	a.EXPECT().Cut(gomock.Any(), gomock.Any()).Return("returnString", nil).AnyTimes()
+	a.EXPECT().Cut(gomock.Any(), gomock.Any(), gomock.Any()).Return("returnString", nil).AnyTimes()
 	_, testserver := initEnv()
 	defer testserver.Close()
 	b.StartTimer()
@@ -841,7 +1898,7 @@ func BenchmarkCutterHandler(b *testing.B) {
 	s := New(a, c)
 	_, testserver := initEnv()
 	defer testserver.Close()
-	a.EXPECT().Cut(gomock.Any(), gomock.Any()).Return("returnString", nil).AnyTimes()
+	a.EXPECT().Cut(gomock.Any(), gomock.Any(), gomock.Any()).Return("returnString", nil).AnyTimes()
 	c.EXPECT().GetShortAddress().Return(testserver.URL).AnyTimes()
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
@@ -910,7 +1967,7 @@ func BenchmarkDeleteUserUrlsHandler(b *testing.B) {
 	s := New(a, c)
 	_, testserver := initEnv()
 	defer testserver.Close()
-	a.EXPECT().DeleteUrls(gomock.Any(), gomock.Any()).AnyTimes()
+	a.EXPECT().DeleteUrls(gomock.Any(), gomock.Any(), gomock.Any()).Return(jsonobject.DeleteJob{}, nil).AnyTimes()
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
 		s.deleteUserUrlsHandler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, testserver.URL, strings.NewReader(positiveURL)))
@@ -0,0 +1,273 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dmad1989/urlcut/internal/config"
+	"github.com/dmad1989/urlcut/internal/logging"
+)
+
+// appendLog журнал, постоянно открытый на дозапись.
+// Строки одной записи собираются в буфере и передаются ОС одним вызовом,
+// на диск (fsync) журнал сбрасывается согласно политике config.FileSyncAlways, config.FileSyncInterval, config.FileSyncNever.
+type appendLog struct {
+	file   logFile
+	w      *bufio.Writer
+	name   string
+	policy string
+	mu     sync.Mutex
+	// size размер файла после последней успешной дозаписи
+	size int64
+	// lines количество строк, дописанных после открытия или перезаписи журнала
+	lines int
+	// unsynced в журнал дописаны строки, еще не сброшенные на диск
+	unsynced bool
+}
+
+// logFile файл журнала, в тестах подменяется для имитации сбоев диска.
+type logFile interface {
+	io.Writer
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+// openLog открывает журнал name на дозапись, при отсутствии - создает его.
+func openLog(name, policy string) (*appendLog, error) {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("open log %s: %w", name, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("stat log %s: %w", name, err)
+	}
+	return &appendLog{
+		file:   file,
+		w:      bufio.NewWriter(file),
+		name:   name,
+		policy: policy,
+		size:   info.Size(),
+	}, nil
+}
+
+// append дописывает в журнал строки lines одной записью.
+// При политике config.FileSyncAlways запись сбрасывается на диск до возврата.
+// Если запись или сброс не удались, журнал обрезается до конца предыдущей записи:
+// вызывающий считает запись несостоявшейся, и она не должна появиться после перезапуска.
+func (l *appendLog) append(lines ...[]byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var n int64
+	for _, line := range lines {
+		l.w.Write(line)
+		l.w.WriteByte('\n')
+		n += int64(len(line)) + 1
+	}
+	if err := l.w.Flush(); err != nil {
+		return l.rollback(fmt.Errorf("append to %s: %w", l.name, err))
+	}
+	if l.policy == config.FileSyncAlways {
+		if err := l.file.Sync(); err != nil {
+			return l.rollback(fmt.Errorf("sync %s: %w", l.name, err))
+		}
+	} else {
+		l.unsynced = true
+	}
+	l.size += n
+	l.lines += len(lines)
+	return nil
+}
+
+// rollback отменяет неудавшуюся дозапись: сбрасывает буфер с ошибкой и обрезает файл до l.size.
+// Возвращает err, дополненную ошибкой обрезки, если она произошла.
+func (l *appendLog) rollback(err error) error {
+	l.w.Reset(l.file)
+	if errTrunc := l.file.Truncate(l.size); errTrunc != nil {
+		return errors.Join(err, fmt.Errorf("truncate %s: %w", l.name, errTrunc))
+	}
+	return err
+}
+
+// sync сбрасывает на диск строки, дописанные после предыдущего сброса.
+func (l *appendLog) sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.syncLocked()
+}
+
+func (l *appendLog) syncLocked() error {
+	if !l.unsynced {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", l.name, err)
+	}
+	l.unsynced = false
+	return nil
+}
+
+// pending сообщает, что в журнал дописаны строки после открытия или перезаписи.
+func (l *appendLog) pending() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lines > 0
+}
+
+// truncate очищает журнал, например после сохранения его записей в снимок.
+func (l *appendLog) truncate() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.file.Truncate(0); err != nil {
+		return fmt.Errorf("truncate %s: %w", l.name, err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", l.name, err)
+	}
+	l.size, l.lines, l.unsynced = 0, 0, false
+	return nil
+}
+
+// replace атомарно перезаписывает журнал содержимым, которое пишет write, см. replaceFile,
+// и продолжает дозапись в новый файл. При ошибке дозапись продолжается в прежний файл.
+func (l *appendLog) replace(write func(w *bufio.Writer) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := replaceFile(l.name, write); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(l.name, os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("reopen %s: %w", l.name, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat %s: %w", l.name, err)
+	}
+	l.file.Close()
+	l.file = file
+	l.w.Reset(file)
+	l.size, l.lines, l.unsynced = info.Size(), 0, false
+	return nil
+}
+
+// close сбрасывает журнал на диск и закрывает его.
+func (l *appendLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.syncLocked(); err != nil {
+		l.file.Close()
+		return err
+	}
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", l.name, err)
+	}
+	return nil
+}
+
+// readLog читает файл name построчно и передает каждую строку в decode.
+// Возвращает количество прочитанных строк.
+//
+// Оборванная при сбое последняя запись (не разбирается decode) пропускается с предупреждением в лог,
+// а файл обрезается до конца предыдущей записи, чтобы следующая дозапись начиналась с новой строки.
+// Нечитаемая запись в середине файла - ошибка: пропуск такой записи потерял бы данные незаметно.
+func readLog(name string, decode func(data []byte) error) (int, error) {
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return 0, fmt.Errorf("open %s: %w", name, err)
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+	var n int
+	var offset int64 // конец последней прочитанной записи
+	for {
+		line, err := r.ReadBytes('\n')
+		isLast := errors.Is(err, io.EOF)
+		if err != nil && !isLast {
+			return n, fmt.Errorf("read %s: %w", name, err)
+		}
+		if data := bytes.TrimSpace(line); len(data) > 0 {
+			if errDecode := decode(data); errDecode != nil {
+				if !isLast && !isTail(r) {
+					return n, fmt.Errorf("%s: record at offset %d: %w", name, offset, errDecode)
+				}
+				logging.Log.Warnw("readLog: skipped corrupt trailing record", "file", name, "offset", offset, "error", errDecode)
+				if err = file.Truncate(offset); err != nil {
+					return n, fmt.Errorf("truncate %s: %w", name, err)
+				}
+				return n, nil
+			}
+			n++
+		}
+		offset += int64(len(line))
+		if !isLast {
+			continue
+		}
+		if len(line) > 0 {
+			// последняя запись цела, но перевод строки не успел записаться
+			if _, err = file.WriteAt([]byte{'\n'}, offset); err != nil {
+				return n, fmt.Errorf("terminate %s: %w", name, err)
+			}
+		}
+		return n, nil
+	}
+}
+
+// isTail сообщает, что после текущей строки в r остались только пробельные символы.
+func isTail(r *bufio.Reader) bool {
+	rest, err := io.ReadAll(r)
+	return err == nil && len(bytes.TrimSpace(rest)) == 0
+}
+
+// replaceFile перезаписывает файл содержимым, которое пишет write.
+// Содержимое пишется во временный файл рядом, сбрасывается на диск и заменяет файл переименованием,
+// поэтому при сбое остается либо прежний, либо новый файл целиком.
+func replaceFile(name string, write func(w *bufio.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	if err = write(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync temp file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("replace file: %w", err)
+	}
+	return syncDir(filepath.Dir(name))
+}
+
+// syncDir сбрасывает на диск каталог dir, чтобы переименование файла в нем пережило сбой.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("open dir: %w", err)
+	}
+	defer d.Close()
+	if err = d.Sync(); err != nil {
+		return fmt.Errorf("sync dir: %w", err)
+	}
+	return nil
+}
@@ -8,23 +8,66 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/dmad1989/urlcut/internal/config"
 	"github.com/dmad1989/urlcut/internal/cutter"
 	"github.com/dmad1989/urlcut/internal/jsonobject"
 	"github.com/dmad1989/urlcut/internal/logging"
 )
 
+// logFileSuffix суффикс имени журнала изменений записей, журнал лежит рядом с файлом сокращений.
+// Файл сокращений - снимок записей на момент последнего компактирования, журнал - изменения после него.
+const logFileSuffix = ".log"
+
+// jobsFileSuffix суффикс имени журнала задач удаления, журнал лежит рядом с файлом сокращений.
+const jobsFileSuffix = ".jobs"
+
+// clicksFileSuffix суффикс имени файла переходов по сокращениям, файл лежит рядом с файлом сокращений.
+const clicksFileSuffix = ".clicks"
+
 type configer interface {
 	GetFileStoreName() string
 	GetDBConnName() string
+	GetURLUniqueness() string
+	GetFileSync() string
+	GetFileSyncInterval() time.Duration
+	GetFileCompactInterval() time.Duration
+}
+
+// urlKey ключ уникальности URL: scope пустой при глобальной уникальности или ID пользователя.
+type urlKey struct {
+	scope string
+	url   string
 }
 
 type storage struct {
-	urlMap    map[string]string
-	revertMap map[string]string
-	fileName  string
-	rw        sync.RWMutex
+	urlMap    map[urlKey]string           // URL - сокращение
+	revertMap map[string]*jsonobject.Item // сокращение - запись
+	jobs      map[string]jsonobject.DeleteJob
+	clicks    map[string][]jsonobject.ClickEvent // сокращение - переходы по нему
+	// itemsLog журнал изменений записей: каждая строка - новое состояние записи
+	itemsLog *appendLog
+	// jobsLog журнал задач удаления: каждая строка - новое состояние задачи
+	jobsLog *appendLog
+	// clicksLog файл переходов: каждая строка - один переход
+	clicksLog *appendLog
+	// stop останавливает обслуживание журналов, см. maintain
+	stop     chan struct{}
+	fileName string
+	// maintained завершение обслуживания журналов
+	maintained sync.WaitGroup
+	rw         sync.RWMutex
+	lastID     atomic.Int64
+	// jobsMu защищает jobs отдельно от записей, чтобы опрос задач не ждал удаления
+	jobsMu sync.RWMutex
+	// clicksMu защищает clicks, берется после rw
+	clicksMu sync.RWMutex
+	// perUser URL уникален в пределах пользователя, а не глобально
+	perUser bool
 }
 
 // New находит или создает файл, инициализирует Map - для хранения.
@@ -38,8 +81,11 @@ func New(ctx context.Context, c configer) (*storage, error) {
 	res := storage{
 		rw:        sync.RWMutex{},
 		fileName:  fn,
-		urlMap:    make(map[string]string),
-		revertMap: make(map[string]string),
+		urlMap:    make(map[urlKey]string),
+		revertMap: make(map[string]*jsonobject.Item),
+		jobs:      make(map[string]jsonobject.DeleteJob),
+		clicks:    make(map[string][]jsonobject.ClickEvent),
+		perUser:   c.GetURLUniqueness() == config.URLUniqueUser,
 	}
 
 	if fn != "" {
@@ -47,13 +93,108 @@ func New(ctx context.Context, c configer) (*storage, error) {
 			return nil, fmt.Errorf("create file storage: %w", err)
 		}
 
-		if err := res.readFromFile(); err != nil {
-			return nil, fmt.Errorf("read from file storage: %w", err)
+		if err := res.openFiles(c.GetFileSync()); err != nil {
+			return nil, err
 		}
+		res.stop = make(chan struct{})
+		res.maintained.Add(1)
+		go res.maintain(ctx, c.GetFileSync(), c.GetFileSyncInterval(), c.GetFileCompactInterval())
 	}
 	return &res, nil
 }
 
+// openFiles загружает записи, задачи удаления и переходы из файлов и открывает их журналы на дозапись
+// с политикой сброса на диск policy.
+// Изменения записей, накопленные в журнале прошлым запуском, переносятся в снимок.
+func (s *storage) openFiles(policy string) error {
+	logged, err := s.readFromFile()
+	if err != nil {
+		return fmt.Errorf("read from file storage: %w", err)
+	}
+	if s.itemsLog, err = openLog(s.fileName+logFileSuffix, policy); err != nil {
+		return fmt.Errorf("open file storage: %w", err)
+	}
+	if logged > 0 {
+		if err = s.compact(); err != nil {
+			return fmt.Errorf("compact file storage: %w", err)
+		}
+	}
+	if err = s.readJobs(s.fileName + jobsFileSuffix); err != nil {
+		return fmt.Errorf("read delete jobs journal: %w", err)
+	}
+	if s.jobsLog, err = openLog(s.fileName+jobsFileSuffix, policy); err != nil {
+		return fmt.Errorf("open delete jobs journal: %w", err)
+	}
+	if err = s.compactJobs(); err != nil {
+		return fmt.Errorf("compact delete jobs journal: %w", err)
+	}
+	if err = s.readClicks(s.fileName + clicksFileSuffix); err != nil {
+		return fmt.Errorf("read clicks: %w", err)
+	}
+	if s.clicksLog, err = openLog(s.fileName+clicksFileSuffix, policy); err != nil {
+		return fmt.Errorf("open clicks: %w", err)
+	}
+	return nil
+}
+
+// maintain обслуживает журналы, пока не отменен ctx или не закрыто хранилище:
+// при политике config.FileSyncInterval сбрасывает их на диск раз в syncInterval,
+// раз в compactInterval компактирует файл сокращений и журнал задач удаления.
+func (s *storage) maintain(ctx context.Context, policy string, syncInterval, compactInterval time.Duration) {
+	defer s.maintained.Done()
+	var syncs <-chan time.Time
+	if policy == config.FileSyncInterval {
+		ticker := time.NewTicker(syncInterval)
+		defer ticker.Stop()
+		syncs = ticker.C
+	}
+	compactions := time.NewTicker(compactInterval)
+	defer compactions.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-syncs:
+			for _, l := range []*appendLog{s.itemsLog, s.jobsLog, s.clicksLog} {
+				if err := l.sync(); err != nil {
+					logging.Log.Errorw("store.maintain: sync", "error", err)
+				}
+			}
+		case <-compactions.C:
+			if err := s.compactLogs(); err != nil {
+				logging.Log.Errorw("store.maintain: compact", "error", err)
+			}
+		}
+	}
+}
+
+// compactLogs переносит накопленные в журналах изменения в снимок файла сокращений и журнал задач удаления.
+// Если изменений не было, файлы не перезаписываются.
+func (s *storage) compactLogs() error {
+	if s.fileName == "" {
+		return nil
+	}
+	s.rw.Lock()
+	if s.itemsLog.pending() {
+		if err := s.compact(); err != nil {
+			s.rw.Unlock()
+			return fmt.Errorf("store.compactLogs: %w", err)
+		}
+	}
+	s.rw.Unlock()
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	if !s.jobsLog.pending() {
+		return nil
+	}
+	if err := s.compactJobs(); err != nil {
+		return fmt.Errorf("store.compactLogs: %w", err)
+	}
+	return nil
+}
+
 // Ping не поддерживается для данного типа хранилища.
 //
 // Deprecated: не пддерживайется для хранилища - файла.
@@ -61,17 +202,21 @@ func (s *storage) Ping(ctx context.Context) error {
 	return errors.New("unsupported store method")
 }
 
-// CloseDB не поддерживается для данного типа хранилища.
-//
-// Deprecated: не пддерживайется для хранилища - файла.
+// CloseDB останавливает обслуживание журналов, сбрасывает их на диск и закрывает.
 func (s *storage) CloseDB() error {
-	return nil
+	if s.fileName == "" {
+		return nil
+	}
+	close(s.stop)
+	s.maintained.Wait()
+	return errors.Join(s.itemsLog.close(), s.jobsLog.close(), s.clicksLog.close())
 }
 
 // GetShortURL ищет по URL его сокращение.
+// В режиме уникальности по пользователю ищутся только сокращения текущего пользователя.
 func (s *storage) GetShortURL(ctx context.Context, url string) (string, error) {
 	s.rw.RLock()
-	generated, isFound := s.urlMap[url]
+	generated, isFound := s.urlMap[s.key(userFromCtx(ctx), url)]
 	s.rw.RUnlock()
 	if !isFound {
 		return "", nil
@@ -79,154 +224,872 @@ func (s *storage) GetShortURL(ctx context.Context, url string) (string, error) {
 	return generated, nil
 }
 
-// Add добавляет в файл пару URL - сокращение.
-func (s *storage) Add(ctx context.Context, original, short string) error {
+// Add добавляет в файл пару URL - сокращение и срок его действия.
+func (s *storage) Add(ctx context.Context, original, short string, opts jsonobject.LinkOptions) error {
 	s.rw.Lock()
 	defer s.rw.Unlock()
-	generated, isFound := s.urlMap[original]
+	userID := userFromCtx(ctx)
+	key := s.key(userID, original)
+	generated, isFound := s.urlMap[key]
 	if isFound {
 		return cutter.NewUniqueURLError(generated, fmt.Errorf("url already added"))
 	}
-	s.urlMap[original] = short
-	s.revertMap[short] = original
-	if s.fileName != "" {
-		id := len(s.urlMap) + 1
-		if err := writeItem(s.fileName, jsonobject.Item{ID: id, ShortURL: short, OriginalURL: original}); err != nil {
-			return fmt.Errorf("store.add: write items: %w", err)
-		}
+	if _, isFound = s.revertMap[short]; isFound {
+		return fmt.Errorf("store.add: %w", cutter.NewShortURLTakenError(short))
+	}
+	item := s.newItem(userID, original, short, opts, time.Now().UTC())
+	// запись попадает в индексы только после сохранения в файл
+	if err := s.writeItem(*item); err != nil {
+		return fmt.Errorf("store.add: write items: %w", err)
 	}
+	s.urlMap[key] = short
+	s.revertMap[short] = item
 	return nil
 }
 
-// GetOriginalURL находит по переданному сокращению оригинальный URL.
-func (s *storage) GetOriginalURL(ctx context.Context, value string) (string, error) {
+// newItem создает запись сокращения short для URL original пользователя userID.
+func (s *storage) newItem(userID, original, short string, opts jsonobject.LinkOptions, now time.Time) *jsonobject.Item {
+	item := &jsonobject.Item{
+		CreatedAt:     &now,
+		ID:            int(s.lastID.Add(1)),
+		ShortURL:      short,
+		OriginalURL:   original,
+		ExpiresAt:     opts.ExpiresAt,
+		ActiveFrom:    opts.ActiveFrom,
+		PasswordHash:  opts.PasswordHash,
+		AuthorID:      userID,
+		PassQuery:     opts.PassQuery,
+		PassPath:      opts.PassPath,
+		RedirectType:  opts.RedirectType,
+		Title:         opts.Title,
+		Variants:      opts.Variants,
+		StickyVariant: opts.StickyVariant,
+	}
+	if opts.MaxClicks > 0 {
+		clicks := opts.MaxClicks
+		item.ClicksLeft = &clicks
+	}
+	return item
+}
+
+// GetOriginalURL находит по переданному сокращению запись с оригинальным URL.
+func (s *storage) GetOriginalURL(ctx context.Context, value string) (jsonobject.Item, error) {
 	s.rw.RLock()
 	defer s.rw.RUnlock()
-	res, isFound := s.revertMap[value]
+	item, isFound := s.revertMap[value]
 	if !isFound {
-		return "", fmt.Errorf("no data found in urlMap for value %s", value)
+		return jsonobject.Item{}, fmt.Errorf("no data found in urlMap for value %s", value)
+	}
+	if item.Deleted() {
+		return jsonobject.Item{}, cutter.ErrorDeletedURL
+	}
+	if item.ExpiresAt != nil && !item.ExpiresAt.After(time.Now()) {
+		return jsonobject.Item{}, cutter.ErrorExpiredURL
+	}
+	res := *item
+	if item.ClicksLeft != nil {
+		if *item.ClicksLeft <= 0 {
+			return jsonobject.Item{}, cutter.ErrorClicksExhausted
+		}
+		// счетчик изменяется в UseClick, поэтому наружу отдается копия
+		clicks := *item.ClicksLeft
+		res.ClicksLeft = &clicks
 	}
 	return res, nil
 }
 
+// UseClick списывает один переход у сокращения с ограничением переходов.
+// Изменение сохраняется в файл новой строкой, при чтении файла последняя строка заменяет предыдущие.
+func (s *storage) UseClick(ctx context.Context, short string) error {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	item, isFound := s.revertMap[short]
+	if !isFound {
+		return fmt.Errorf("no data found in urlMap for value %s", short)
+	}
+	if item.ClicksLeft == nil {
+		return nil
+	}
+	if *item.ClicksLeft <= 0 {
+		return cutter.ErrorClicksExhausted
+	}
+	clicks := *item.ClicksLeft - 1
+	if err := s.save(item, func(i *jsonobject.Item) { i.ClicksLeft = &clicks }); err != nil {
+		return fmt.Errorf("store.UseClick: %w", err)
+	}
+	return nil
+}
+
 // UploadBatch загружает слайс BatchItem в файл.
+// Пакет записывается целиком или не записывается вовсе: все сокращения проверяются под одной блокировкой
+// до записи, занятое сокращение возвращает *cutter.ShortURLTakenError без изменений в хранилище.
+// Для уже добавленных URL выдается их прежнее сокращение.
 func (s *storage) UploadBatch(ctx context.Context, batch jsonobject.Batch) (jsonobject.Batch, error) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	userID := userFromCtx(ctx)
+	now := time.Now().UTC()
+	added := make(map[urlKey]*jsonobject.Item, len(batch))
+	shorts := make(map[string]struct{}, len(batch))
+	items := make([]jsonobject.Item, 0, len(batch))
 	for i := 0; i < len(batch); i++ {
-		short, err := s.GetShortURL(ctx, batch[i].OriginalURL)
-		if err != nil {
-			return batch, fmt.Errorf("upload batch check: %w", err)
-		}
-		if short != "" {
+		key := s.key(userID, batch[i].OriginalURL)
+		if short, isFound := s.urlMap[key]; isFound {
 			batch[i].ShortURL = short
-		} else {
-			err = s.Add(ctx, batch[i].OriginalURL, batch[i].ShortURL)
-			if err != nil {
-				return batch, fmt.Errorf("UploadBatch: store add: %w", err)
-			}
+			continue
 		}
+		if item, isFound := added[key]; isFound {
+			batch[i].ShortURL = item.ShortURL
+			continue
+		}
+		short := batch[i].ShortURL
+		_, isTaken := s.revertMap[short]
+		if _, isDuplicate := shorts[short]; isTaken || isDuplicate {
+			return batch, fmt.Errorf("UploadBatch: %w", cutter.NewShortURLTakenError(short))
+		}
+		item := s.newItem(userID, batch[i].OriginalURL, short, batch[i].LinkOptions, now)
+		added[key] = item
+		shorts[short] = struct{}{}
+		items = append(items, *item)
+	}
+	if err := s.writeItems(items...); err != nil {
+		return batch, fmt.Errorf("UploadBatch: write items: %w", err)
+	}
+	for key, item := range added {
+		s.urlMap[key] = item.ShortURL
+		s.revertMap[item.ShortURL] = item
+	}
+	for i := 0; i < len(batch); i++ {
 		batch[i].OriginalURL = ""
 	}
 	return batch, nil
 }
 
-// GetUserURLs не поддерживается для данного типа хранилища.
-//
-// Deprecated: не пддерживайется для хранилища - файла.
+// GetUserURLs выдает действующие сокращения пользователя из контекста в порядке создания.
+// Удаленные и отмеченные истекшими сокращения не выдаются.
+// Для вариантов URL заполняется количество переходов на каждый вариант.
 func (s *storage) GetUserURLs(ctx context.Context) (jsonobject.Batch, error) {
-	return nil, nil
+	userID := userFromCtx(ctx)
+	if userID == "" {
+		return nil, errors.New("GetUserUrls, no user in context")
+	}
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	var items []*jsonobject.Item
+	for _, item := range s.revertMap {
+		if item.AuthorID == userID && !item.Deleted() && !item.Expired {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	s.clicksMu.RLock()
+	defer s.clicksMu.RUnlock()
+	res := make(jsonobject.Batch, 0, len(items))
+	for _, item := range items {
+		res = append(res, jsonobject.BatchItem{
+			ShortURL:    item.ShortURL,
+			OriginalURL: item.OriginalURL,
+			LinkOptions: jsonobject.LinkOptions{
+				ExpiresAt:     item.ExpiresAt,
+				ActiveFrom:    item.ActiveFrom,
+				Variants:      s.variantClicks(item),
+				StickyVariant: item.StickyVariant,
+			},
+		})
+	}
+	return res, nil
 }
 
-// DeleteURLs не поддерживается для данного типа хранилища.
-//
-// Deprecated: не пддерживайется для хранилища - файла.
-func (s *storage) DeleteURLs(ctx context.Context, userID string, ids []string) error {
-	return errors.New("unsupported store method")
+// variantClicks возвращает копию вариантов URL записи item с количеством переходов на каждый вариант.
+// Вызывается под блокировкой clicksMu.
+func (s *storage) variantClicks(item *jsonobject.Item) jsonobject.Variants {
+	if len(item.Variants) == 0 {
+		return nil
+	}
+	counts := make(map[string]int64, len(item.Variants))
+	for _, e := range s.clicks[item.ShortURL] {
+		if e.Variant != "" {
+			counts[e.Variant]++
+		}
+	}
+	res := make(jsonobject.Variants, len(item.Variants))
+	for i, v := range item.Variants {
+		v.Clicks = counts[v.Name]
+		res[i] = v
+	}
+	return res
+}
+
+// DeleteURLs отмечает удаленными сокращения от имени их пользователей.
+// Возвращает результат удаления каждого сокращения в порядке items.
+// Изменения сохраняются в файл новыми строками.
+func (s *storage) DeleteURLs(ctx context.Context, items []jsonobject.DeleteItem) ([]jsonobject.DeleteStatus, error) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	res := make([]jsonobject.DeleteStatus, len(items))
+	now := time.Now()
+	for i, d := range items {
+		item, err := s.ownItem(d.UserID, d.Short)
+		switch {
+		case errors.Is(err, cutter.ErrorURLNotFound):
+			res[i] = jsonobject.DeleteNotFound
+			continue
+		case errors.Is(err, cutter.ErrorNotOwner):
+			res[i] = jsonobject.DeleteNotOwned
+			continue
+		case item.Deleted():
+			res[i] = jsonobject.DeleteSkipped
+			continue
+		}
+		deletedAt := now
+		if err = s.save(item, func(i *jsonobject.Item) { i.DeletedAt = &deletedAt }); err != nil {
+			return nil, fmt.Errorf("store.DeleteURLs: %w", err)
+		}
+		res[i] = jsonobject.DeleteDone
+	}
+	return res, nil
+}
+
+// CreateDeleteJob сохраняет новую задачу удаления.
+// Задача дописывается в журнал вместе со списком сокращений.
+func (s *storage) CreateDeleteJob(ctx context.Context, job jsonobject.DeleteJob) error {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	if _, isFound := s.jobs[job.ID]; isFound {
+		return fmt.Errorf("store.CreateDeleteJob: job %s already exists", job.ID)
+	}
+	if err := s.writeJob(job, true); err != nil {
+		return fmt.Errorf("store.CreateDeleteJob: %w", err)
+	}
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// UpdateDeleteJob сохраняет состояние задачи удаления.
+// Список сокращений задачи не меняется и в журнал повторно не пишется.
+func (s *storage) UpdateDeleteJob(ctx context.Context, job jsonobject.DeleteJob) error {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	prev, isFound := s.jobs[job.ID]
+	if !isFound {
+		return fmt.Errorf("store.UpdateDeleteJob: %w", cutter.ErrorJobNotFound)
+	}
+	job.Codes = prev.Codes
+	if err := s.writeJob(job, false); err != nil {
+		return fmt.Errorf("store.UpdateDeleteJob: %w", err)
+	}
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// GetDeleteJob выдает задачу удаления по ID.
+func (s *storage) GetDeleteJob(ctx context.Context, id string) (jsonobject.DeleteJob, error) {
+	s.jobsMu.RLock()
+	defer s.jobsMu.RUnlock()
+	job, isFound := s.jobs[id]
+	if !isFound {
+		return jsonobject.DeleteJob{}, cutter.ErrorJobNotFound
+	}
+	return job, nil
+}
+
+// ClaimDeleteJob выдает самую раннюю задачу в очереди и переводит ее в состояние cutter.JobRunning.
+// Если очередь пуста - возвращает cutter.ErrorNoPendingJobs.
+func (s *storage) ClaimDeleteJob(ctx context.Context) (jsonobject.DeleteJob, error) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	var next *jsonobject.DeleteJob
+	for id := range s.jobs {
+		job := s.jobs[id]
+		if job.State != cutter.JobPending {
+			continue
+		}
+		if next == nil || job.CreatedAt.Before(next.CreatedAt) ||
+			job.CreatedAt.Equal(next.CreatedAt) && job.ID < next.ID {
+			next = &job
+		}
+	}
+	if next == nil {
+		return jsonobject.DeleteJob{}, cutter.ErrorNoPendingJobs
+	}
+	next.State = cutter.JobRunning
+	next.UpdatedAt = time.Now()
+	if err := s.writeJob(*next, false); err != nil {
+		return jsonobject.DeleteJob{}, fmt.Errorf("store.ClaimDeleteJob: %w", err)
+	}
+	s.jobs[next.ID] = *next
+	return *next, nil
+}
+
+// RequeueDeleteJobs возвращает в очередь задачи, выполнение которых было прервано.
+// Возвращает количество таких задач.
+func (s *storage) RequeueDeleteJobs(ctx context.Context) (int64, error) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	var n int64
+	for id, job := range s.jobs {
+		if job.State != cutter.JobRunning {
+			continue
+		}
+		job.State = cutter.JobPending
+		job.UpdatedAt = time.Now()
+		if err := s.writeJob(job, false); err != nil {
+			return n, fmt.Errorf("store.RequeueDeleteJobs: %w", err)
+		}
+		s.jobs[id] = job
+		n++
+	}
+	return n, nil
+}
+
+// writeJob дописывает состояние задачи в журнал, withCodes - вместе со списком сокращений.
+// Без файла хранилища задачи хранятся только в памяти. Вызывается под блокировкой jobsMu.
+func (s *storage) writeJob(job jsonobject.DeleteJob, withCodes bool) error {
+	if s.jobsLog == nil {
+		return nil
+	}
+	entry := jsonobject.DeleteJobEntry{UserID: job.UserID, Job: job}
+	if withCodes {
+		entry.Codes = job.Codes
+	}
+	data, err := entry.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("marshal job: %w", err)
+	}
+	return s.jobsLog.append(data)
+}
+
+// readJobs загружает задачи удаления из журнала name: последняя строка задачи задает ее состояние.
+func (s *storage) readJobs(name string) error {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	_, err := readLog(name, func(data []byte) error {
+		var entry jsonobject.DeleteJobEntry
+		if err := entry.UnmarshalJSON(data); err != nil {
+			return fmt.Errorf("unmarshal: %w", err)
+		}
+		job := entry.Job
+		job.UserID = entry.UserID
+		job.Codes = entry.Codes
+		if prev, isFound := s.jobs[job.ID]; isFound && len(job.Codes) == 0 {
+			job.Codes = prev.Codes
+		}
+		s.jobs[job.ID] = job
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("readJobs: %w", err)
+	}
+	return nil
+}
+
+// compactJobs перезаписывает журнал задач удаления до одной строки на задачу. Вызывается под блокировкой jobsMu.
+func (s *storage) compactJobs() error {
+	jobs := make([]jsonobject.DeleteJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	err := s.jobsLog.replace(func(w *bufio.Writer) error {
+		for _, job := range jobs {
+			data, err := jsonobject.DeleteJobEntry{UserID: job.UserID, Codes: job.Codes, Job: job}.MarshalJSON()
+			if err != nil {
+				return fmt.Errorf("marshal job: %w", err)
+			}
+			w.Write(data)
+			w.WriteByte('\n')
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("compactJobs: %w", err)
+	}
+	return nil
 }
 
-// readFromFile открывает файл на чтение.
-// содержимое файла загружается в map.
-func (s *storage) readFromFile() error {
+// GetDeletedURLs выдает удаленные сокращения пользователя userID, начиная с удаленных последними.
+func (s *storage) GetDeletedURLs(ctx context.Context, userID string) (jsonobject.Batch, error) {
 	s.rw.RLock()
 	defer s.rw.RUnlock()
+	var res jsonobject.Batch
+	for _, item := range s.revertMap {
+		if item.AuthorID != userID || !item.Deleted() {
+			continue
+		}
+		deletedAt := *item.DeletedAt
+		res = append(res, jsonobject.BatchItem{
+			ShortURL:    item.ShortURL,
+			OriginalURL: item.OriginalURL,
+			DeletedAt:   &deletedAt,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].DeletedAt.After(*res[j].DeletedAt)
+	})
+	return res, nil
+}
+
+// RestoreURLs снимает отметку удаления с сокращений пользователя userID.
+// Чужие, не удаленные и несуществующие сокращения пропускаются.
+// Возвращает восстановленные сокращения.
+func (s *storage) RestoreURLs(ctx context.Context, userID string, ids []string) (jsonobject.ShortIds, error) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	var res jsonobject.ShortIds
+	for _, id := range ids {
+		item, err := s.ownItem(userID, id)
+		if err != nil || !item.Deleted() {
+			continue
+		}
+		if err = s.save(item, func(i *jsonobject.Item) { i.DeletedAt = nil }); err != nil {
+			return res, fmt.Errorf("store.RestoreURLs: %w", err)
+		}
+		res = append(res, id)
+	}
+	return res, nil
+}
+
+// NextID выдает следующее значение счетчика записей.
+// Счетчик общий с идентификаторами записей в файле, поэтому после перезапуска значения не повторяются.
+func (s *storage) NextID(ctx context.Context) (int64, error) {
+	return s.lastID.Add(1), nil
+}
+
+// MarkExpired отмечает записи с истекшим сроком действия.
+// Возвращает количество отмеченных записей.
+func (s *storage) MarkExpired(ctx context.Context) (int64, error) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	now := time.Now()
+	var n int64
+	for _, item := range s.revertMap {
+		if !item.Expired && item.ExpiresAt != nil && !item.ExpiresAt.After(now) {
+			item.Expired = true
+			n++
+		}
+	}
+	return n, nil
+}
+
+// PurgeDeleted окончательно удаляет записи, удаленные пользователями раньше before.
+// Если записи удалены, файл перезаписывается (компактируется): в нем остается по одной строке на каждую запись.
+// Возвращает количество удаленных записей.
+func (s *storage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	var purged []*jsonobject.Item
+	for _, item := range s.revertMap {
+		if item.Deleted() && item.DeletedAt.Before(before) {
+			purged = append(purged, item)
+		}
+	}
+	if len(purged) == 0 {
+		return 0, nil
+	}
+	for _, item := range purged {
+		delete(s.revertMap, item.ShortURL)
+		if key := s.key(item.AuthorID, item.OriginalURL); s.urlMap[key] == item.ShortURL {
+			delete(s.urlMap, key)
+		}
+	}
+	if s.itemsLog != nil {
+		if err := s.compact(); err != nil {
+			return int64(len(purged)), fmt.Errorf("store.PurgeDeleted: %w", err)
+		}
+	}
+	if err := s.purgeClicks(purged); err != nil {
+		return int64(len(purged)), fmt.Errorf("store.PurgeDeleted: %w", err)
+	}
+	return int64(len(purged)), nil
+}
+
+// purgeClicks удаляет переходы окончательно удаленных записей и перезаписывает файл переходов.
+// Вызывается под блокировкой rw.
+func (s *storage) purgeClicks(purged []*jsonobject.Item) error {
+	s.clicksMu.Lock()
+	defer s.clicksMu.Unlock()
+	n := 0
+	for _, item := range purged {
+		n += len(s.clicks[item.ShortURL])
+		delete(s.clicks, item.ShortURL)
+	}
+	if n == 0 || s.clicksLog == nil {
+		return nil
+	}
+	if err := s.compactClicks(); err != nil {
+		return fmt.Errorf("purge clicks: %w", err)
+	}
+	return nil
+}
 
-	c, err := newConsumer(s.fileName)
+// compact перезаписывает снимок текущим состоянием записей и очищает журнал изменений. Вызывается под блокировкой.
+// Если сбой произойдет после замены снимка, но до очистки журнала, при загрузке журнал применится повторно:
+// строки журнала - полные состояния записей, поэтому результат не изменится, а окончательно удаленные записи
+// вернутся удаленными и будут удалены снова.
+func (s *storage) compact() error {
+	items := make([]*jsonobject.Item, 0, len(s.revertMap))
+	for _, item := range s.revertMap {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	err := replaceFile(s.fileName, func(w *bufio.Writer) error {
+		for _, item := range items {
+			data, err := item.MarshalJSON()
+			if err != nil {
+				return fmt.Errorf("marshal item: %w", err)
+			}
+			w.Write(data)
+			w.WriteByte('\n')
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("readFromFile: open file %w", err)
+		return fmt.Errorf("compact: %w", err)
+	}
+	if err = s.itemsLog.truncate(); err != nil {
+		return fmt.Errorf("compact: %w", err)
+	}
+	return nil
+}
+
+// AddClicks сохраняет переходы по сокращениям.
+// Переходы дописываются в файл переходов одной записью.
+func (s *storage) AddClicks(ctx context.Context, events []jsonobject.ClickEvent) error {
+	s.clicksMu.Lock()
+	defer s.clicksMu.Unlock()
+	if s.clicksLog != nil {
+		lines := make([][]byte, len(events))
+		for i, e := range events {
+			data, err := e.MarshalJSON()
+			if err != nil {
+				return fmt.Errorf("store.AddClicks: marshal click: %w", err)
+			}
+			lines[i] = data
+		}
+		if err := s.clicksLog.append(lines...); err != nil {
+			return fmt.Errorf("store.AddClicks: %w", err)
+		}
 	}
-	items, err := c.ReadItems()
+	for _, e := range events {
+		s.clicks[e.Short] = append(s.clicks[e.Short], e)
+	}
+	return nil
+}
+
+// GetClickStats выдает статистику переходов по сокращению пользователя userID.
+// Браузеры и операционные системы не заполняются: вместо них выдаются переходы по User-Agent.
+func (s *storage) GetClickStats(ctx context.Context, userID, short string) (jsonobject.LinkStats, error) {
+	s.rw.RLock()
+	_, err := s.ownItem(userID, short)
+	s.rw.RUnlock()
 	if err != nil {
-		return fmt.Errorf("readFromFile: read file: %w", err)
+		return jsonobject.LinkStats{}, fmt.Errorf("store.GetClickStats: %w", err)
 	}
-	for _, item := range items {
-		s.urlMap[item.ShortURL] = item.OriginalURL
-		s.revertMap[item.OriginalURL] = item.ShortURL
+	s.clicksMu.RLock()
+	defer s.clicksMu.RUnlock()
+	return clickStats(s.clicks[short]), nil
+}
+
+// CountClicks выдает количество переходов по сокращению short.
+func (s *storage) CountClicks(ctx context.Context, short string) (int64, error) {
+	s.clicksMu.RLock()
+	defer s.clicksMu.RUnlock()
+	return int64(len(s.clicks[short])), nil
+}
+
+// clickStats считает статистику по списку переходов.
+func clickStats(events []jsonobject.ClickEvent) jsonobject.LinkStats {
+	visitors := make(map[string]struct{})
+	days := make(map[string]int64)
+	referrers := make(map[string]int64)
+	agents := make(map[string]int64)
+	variants := make(map[string]int64)
+	for _, e := range events {
+		if e.IPHash != "" {
+			visitors[e.IPHash] = struct{}{}
+		}
+		days[e.At.UTC().Format(time.DateOnly)]++
+		referrers[e.Referrer]++
+		agents[e.UserAgent]++
+		if e.Variant != "" {
+			variants[e.Variant]++
+		}
 	}
+	res := jsonobject.LinkStats{
+		Total:      int64(len(events)),
+		Unique:     int64(len(visitors)),
+		Referrers:  clickCounts(referrers),
+		UserAgents: clickCounts(agents),
+	}
+	if len(variants) > 0 {
+		res.Variants = clickCounts(variants)
+	}
+	for date, clicks := range days {
+		res.Daily = append(res.Daily, jsonobject.DailyClicks{Date: date, Clicks: clicks})
+	}
+	sort.Slice(res.Daily, func(i, j int) bool { return res.Daily[i].Date < res.Daily[j].Date })
+	return res
+}
 
+// clickCounts переводит счетчики переходов в список.
+func clickCounts(counts map[string]int64) []jsonobject.ClickCount {
+	res := make([]jsonobject.ClickCount, 0, len(counts))
+	for name, clicks := range counts {
+		res = append(res, jsonobject.ClickCount{Name: name, Clicks: clicks})
+	}
+	return res
+}
+
+// readClicks загружает переходы из файла name.
+func (s *storage) readClicks(name string) error {
+	s.clicksMu.Lock()
+	defer s.clicksMu.Unlock()
+	_, err := readLog(name, func(data []byte) error {
+		var e jsonobject.ClickEvent
+		if err := e.UnmarshalJSON(data); err != nil {
+			return fmt.Errorf("unmarshal: %w", err)
+		}
+		s.clicks[e.Short] = append(s.clicks[e.Short], e)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("readClicks: %w", err)
+	}
 	return nil
 }
 
-// Consumer открывает файл на чтение и читает из него.
-type Consumer struct {
-	file    *os.File
-	scanner *bufio.Scanner
+// compactClicks перезаписывает файл переходов текущим состоянием. Вызывается под блокировкой clicksMu.
+func (s *storage) compactClicks() error {
+	return s.clicksLog.replace(func(w *bufio.Writer) error {
+		for _, events := range s.clicks {
+			for _, e := range events {
+				data, err := e.MarshalJSON()
+				if err != nil {
+					return fmt.Errorf("marshal click: %w", err)
+				}
+				w.Write(data)
+				w.WriteByte('\n')
+			}
+		}
+		return nil
+	})
 }
 
-func newConsumer(filename string) (*Consumer, error) {
-	file, err := os.OpenFile(filename, os.O_RDONLY|os.O_CREATE, 0666)
+// UpdateURL заменяет оригинальный URL сокращения пользователя userID.
+// Прежний URL сохраняется в истории записи. Изменение сохраняется в файл новой строкой.
+func (s *storage) UpdateURL(ctx context.Context, userID, short, original string) error {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	item, err := s.ownLiveItem(userID, short)
 	if err != nil {
-		return nil, fmt.Errorf("newConsumer: open file: %w", err)
+		return fmt.Errorf("store.UpdateURL: %w", err)
 	}
+	history := append(item.History[:len(item.History):len(item.History)],
+		jsonobject.HistoryItem{ChangedAt: time.Now(), OriginalURL: item.OriginalURL})
+	if err = s.retarget(item, original, history); err != nil {
+		return fmt.Errorf("store.UpdateURL: %w", err)
+	}
+	return nil
+}
 
-	return &Consumer{
-		file:    file,
-		scanner: bufio.NewScanner(file),
-	}, nil
+// GetURLHistory выдает прежние URL сокращения пользователя userID в порядке замены.
+func (s *storage) GetURLHistory(ctx context.Context, userID, short string) (jsonobject.History, error) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	item, err := s.ownItem(userID, short)
+	if err != nil {
+		return nil, fmt.Errorf("store.GetURLHistory: %w", err)
+	}
+	res := make(jsonobject.History, len(item.History))
+	copy(res, item.History)
+	return res, nil
 }
 
-// ReadItems читает каждую строку в jsonobject.Item.
-// Возвращает слайс jsonobject.Item.
-func (c *Consumer) ReadItems() ([]jsonobject.Item, error) {
-	items := []jsonobject.Item{}
-	for c.scanner.Scan() {
-		data := c.scanner.Bytes()
-		item := jsonobject.Item{}
-		err := item.UnmarshalJSON(data)
-		if err != nil {
-			return nil, fmt.Errorf("unmarshal from file: %w", err)
-		}
-		items = append(items, item)
+// RollbackURL возвращает сокращению пользователя userID последний URL из истории.
+// Восстановленный URL удаляется из истории.
+func (s *storage) RollbackURL(ctx context.Context, userID, short string) (string, error) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	item, err := s.ownLiveItem(userID, short)
+	if err != nil {
+		return "", fmt.Errorf("store.RollbackURL: %w", err)
+	}
+	n := len(item.History)
+	if n == 0 {
+		return "", cutter.ErrorNoHistory
 	}
-	if err := c.scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan file error: %w", err)
+	original := item.History[n-1].OriginalURL
+	if err = s.retarget(item, original, item.History[:n-1:n-1]); err != nil {
+		return "", fmt.Errorf("store.RollbackURL: %w", err)
 	}
-	return items, nil
+	return original, nil
 }
 
-// writeItem открывает файл на запись и записывает 1 строку информации.
-func writeItem(fname string, i jsonobject.Item) error {
-	data, err := i.MarshalJSON()
+// GetRules выдает правила перехода сокращения пользователя userID.
+func (s *storage) GetRules(ctx context.Context, userID, short string) (jsonobject.Rules, error) {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	item, err := s.ownLiveItem(userID, short)
 	if err != nil {
-		return fmt.Errorf("marshal item: %w", err)
+		return nil, fmt.Errorf("store.GetRules: %w", err)
 	}
+	return append(jsonobject.Rules(nil), item.Rules...), nil
+}
 
-	file, err := os.OpenFile(fname, os.O_WRONLY|os.O_APPEND, 0666)
+// UpdateRules заменяет правила перехода сокращения пользователя userID результатом change.
+// change получает копию текущих правил и выполняется под блокировкой, поэтому изменения не теряются
+// при одновременных запросах. Изменение сохраняется в файл новой строкой.
+func (s *storage) UpdateRules(ctx context.Context, userID, short string,
+	change func(jsonobject.Rules) (jsonobject.Rules, error)) (jsonobject.Rules, error) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	item, err := s.ownLiveItem(userID, short)
 	if err != nil {
-		return fmt.Errorf("writeItem: open file: %w", err)
+		return nil, fmt.Errorf("store.UpdateRules: %w", err)
 	}
-	defer func() {
-		err = file.Close()
-		if err != nil {
-			logging.Log.Fatalf("file.Close() in writeItem: %w", err)
-		}
-	}()
-	data = append(data, '\n')
-	_, err = file.Write(data)
+	rules, err := change(append(jsonobject.Rules(nil), item.Rules...))
+	if err != nil {
+		return nil, fmt.Errorf("store.UpdateRules: %w", err)
+	}
+	if err = s.save(item, func(i *jsonobject.Item) { i.Rules = rules }); err != nil {
+		return nil, fmt.Errorf("store.UpdateRules: %w", err)
+	}
+	return append(jsonobject.Rules(nil), rules...), nil
+}
+
+// ownItem находит запись сокращения и проверяет, что она принадлежит userID.
+// Вызывается под блокировкой.
+func (s *storage) ownItem(userID, short string) (*jsonobject.Item, error) {
+	item, isFound := s.revertMap[short]
+	switch {
+	case !isFound:
+		return nil, cutter.ErrorURLNotFound
+	case item.AuthorID != userID:
+		return nil, cutter.ErrorNotOwner
+	}
+	return item, nil
+}
+
+// ownLiveItem находит неудаленную запись сокращения пользователя userID, см. ownItem.
+// Удаленная запись возвращает cutter.ErrorDeletedURL: изменять ее можно только после восстановления.
+// Вызывается под блокировкой.
+func (s *storage) ownLiveItem(userID, short string) (*jsonobject.Item, error) {
+	item, err := s.ownItem(userID, short)
+	if err != nil {
+		return nil, err
+	}
+	if item.Deleted() {
+		return nil, cutter.ErrorDeletedURL
+	}
+	return item, nil
+}
+
+// retarget заменяет URL записи, обновляет индекс URL и дописывает запись в файл.
+// Вызывается под блокировкой.
+func (s *storage) retarget(item *jsonobject.Item, original string, history jsonobject.History) error {
+	newKey := s.key(item.AuthorID, original)
+	if existing, isFound := s.urlMap[newKey]; isFound && existing != item.ShortURL {
+		return cutter.NewUniqueURLError(existing, fmt.Errorf("url already added"))
+	}
+	updated := *item
+	updated.OriginalURL = original
+	updated.History = history
+	if err := s.writeItem(updated); err != nil {
+		return fmt.Errorf("write item: %w", err)
+	}
+	if oldKey := s.key(item.AuthorID, item.OriginalURL); s.urlMap[oldKey] == item.ShortURL {
+		delete(s.urlMap, oldKey)
+	}
+	s.urlMap[newKey] = item.ShortURL
+	*item = updated
+	return nil
+}
+
+// save применяет изменение к копии записи, дописывает ее в файл и только затем заменяет запись в памяти.
+// Вызывается под блокировкой.
+func (s *storage) save(item *jsonobject.Item, change func(*jsonobject.Item)) error {
+	updated := *item
+	change(&updated)
+	if err := s.writeItem(updated); err != nil {
+		return fmt.Errorf("write item: %w", err)
+	}
+	*item = updated
+	return nil
+}
+
+// key возвращает ключ уникальности URL с учетом режима уникальности.
+func (s *storage) key(userID, url string) urlKey {
+	if !s.perUser {
+		return urlKey{url: url}
+	}
+	return urlKey{scope: userID, url: url}
+}
+
+// userFromCtx возвращает ID пользователя из контекста вызова.
+func userFromCtx(ctx context.Context) string {
+	userID, _ := ctx.Value(config.UserCtxKey).(string)
+	return userID
+}
+
+// readFromFile загружает записи в map: сначала из снимка, затем из журнала изменений.
+// Более поздняя строка записи заменяет предыдущие. Возвращает количество строк журнала.
+func (s *storage) readFromFile() (int, error) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if _, err := readLog(s.fileName, s.loadItem); err != nil {
+		return 0, fmt.Errorf("readFromFile: %w", err)
+	}
+	n, err := readLog(s.fileName+logFileSuffix, s.loadItem)
 	if err != nil {
-		return fmt.Errorf("writeItem: write in file: %w", err)
+		return n, fmt.Errorf("readFromFile: %w", err)
+	}
+	return n, nil
+}
+
+// loadItem загружает в map запись из строки файла data. Вызывается под блокировкой.
+func (s *storage) loadItem(data []byte) error {
+	item := &jsonobject.Item{}
+	if err := item.UnmarshalJSON(data); err != nil {
+		return fmt.Errorf("unmarshal item: %w", err)
+	}
+	// более поздняя строка заменяет запись, URL которой мог измениться
+	if prev, isFound := s.revertMap[item.ShortURL]; isFound {
+		if oldKey := s.key(prev.AuthorID, prev.OriginalURL); s.urlMap[oldKey] == prev.ShortURL {
+			delete(s.urlMap, oldKey)
+		}
+	}
+	s.urlMap[s.key(item.AuthorID, item.OriginalURL)] = item.ShortURL
+	s.revertMap[item.ShortURL] = item
+	if int64(item.ID) > s.lastID.Load() {
+		s.lastID.Store(int64(item.ID))
 	}
 	return nil
 }
 
+// writeItem дописывает состояние записи в журнал изменений.
+// Без файла хранилища записи хранятся только в памяти. Вызывается под блокировкой rw.
+func (s *storage) writeItem(item jsonobject.Item) error {
+	return s.writeItems(item)
+}
+
+// writeItems дописывает состояния записей в журнал изменений одной записью.
+func (s *storage) writeItems(items ...jsonobject.Item) error {
+	if s.itemsLog == nil || len(items) == 0 {
+		return nil
+	}
+	lines := make([][]byte, len(items))
+	for i, item := range items {
+		data, err := item.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("marshal item: %w", err)
+		}
+		lines[i] = data
+	}
+	return s.itemsLog.append(lines...)
+}
+
 // createIfNeeded находит файл с именем fileName по пути path.
 // если файл или путь не найдены - создаст их.
 func createIfNeeded(path string, fileName string) error {
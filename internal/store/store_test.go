@@ -0,0 +1,432 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/config"
+	"github.com/dmad1989/urlcut/internal/cutter"
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+	"github.com/dmad1989/urlcut/internal/storetest"
+)
+
+func TestPurgeDeleted(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json")}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, "user")
+	for _, short := range []string{"old", "fresh", "kept"} {
+		require.NoError(t, s.Add(ctx, "http://"+short+".ru", short, jsonobject.LinkOptions{}))
+	}
+	_, err = s.DeleteURLs(ctx, []jsonobject.DeleteItem{{UserID: "user", Short: "old"}})
+	require.NoError(t, err)
+	before := time.Now()
+	_, err = s.DeleteURLs(ctx, []jsonobject.DeleteItem{{UserID: "user", Short: "fresh"}})
+	require.NoError(t, err)
+	_, err = s.GetOriginalURL(ctx, "fresh")
+	assert.ErrorIs(t, err, cutter.ErrorDeletedURL)
+
+	n, err := s.PurgeDeleted(ctx, before)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = s.PurgeDeleted(ctx, before)
+	require.NoError(t, err)
+	assert.Zero(t, n, "already purged")
+
+	data, err := os.ReadFile(conf.FileStoreName)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2, "file must be compacted to one line per item")
+
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	_, err = reloaded.GetOriginalURL(ctx, "old")
+	assert.Error(t, err, "purged item must not come back")
+	trash, err := reloaded.GetDeletedURLs(ctx, "user")
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, "fresh", trash[0].ShortURL)
+	short, err := reloaded.GetShortURL(ctx, "http://old.ru")
+	require.NoError(t, err)
+	assert.Empty(t, short, "purged url can be cut again")
+}
+
+func TestDeleteJobsJournal(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json")}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.Background()
+	now := time.Now()
+	for i, id := range []string{"first", "second"} {
+		require.NoError(t, s.CreateDeleteJob(ctx, jsonobject.DeleteJob{
+			ID:        id,
+			UserID:    "user",
+			State:     cutter.JobPending,
+			Codes:     jsonobject.ShortIds{id + "-a", id + "-b"},
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		}))
+	}
+	job, err := s.ClaimDeleteJob(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "first", job.ID, "oldest job first")
+	assert.Equal(t, cutter.JobRunning, job.State)
+	job.Processed = 1
+	job.Deleted = 1
+	require.NoError(t, s.UpdateDeleteJob(ctx, job))
+
+	// перезапуск во время выполнения задачи
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	n, err := reloaded.RequeueDeleteJobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	job, err = reloaded.ClaimDeleteJob(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "first", job.ID)
+	assert.Equal(t, "user", job.UserID)
+	assert.Equal(t, jsonobject.ShortIds{"first-a", "first-b"}, job.Codes, "codes must survive restart")
+	assert.Equal(t, 1, job.Processed)
+
+	job, err = reloaded.ClaimDeleteJob(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "second", job.ID)
+	_, err = reloaded.ClaimDeleteJob(ctx)
+	assert.ErrorIs(t, err, cutter.ErrorNoPendingJobs)
+}
+
+func TestUserURLs(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json")}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, "user")
+	other := context.WithValue(context.Background(), config.UserCtxKey, "other")
+	launch := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	variants := jsonobject.Variants{{Name: "A", URL: "http://ya.ru/a", Weight: 1}, {Name: "B", URL: "http://ya.ru/b", Weight: 1}}
+	require.NoError(t, s.Add(ctx, "http://ya.ru", "first", jsonobject.LinkOptions{Variants: variants}))
+	require.NoError(t, s.Add(ctx, "http://mail.ru", "second", jsonobject.LinkOptions{ActiveFrom: &launch}))
+	require.NoError(t, s.Add(ctx, "http://deleted.ru", "deleted", jsonobject.LinkOptions{}))
+	require.NoError(t, s.Add(other, "http://other.ru", "foreign", jsonobject.LinkOptions{}))
+	require.NoError(t, s.AddClicks(ctx, []jsonobject.ClickEvent{
+		{Short: "first", At: time.Now(), Variant: "B"},
+		{Short: "first", At: time.Now(), Variant: "B"},
+	}))
+	res, err := s.DeleteURLs(ctx, []jsonobject.DeleteItem{{UserID: "user", Short: "deleted"}})
+	require.NoError(t, err)
+	assert.Equal(t, []jsonobject.DeleteStatus{jsonobject.DeleteDone}, res)
+
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	urls, err := reloaded.GetUserURLs(ctx)
+	require.NoError(t, err)
+	require.Len(t, urls, 2)
+	assert.Equal(t, "first", urls[0].ShortURL, "in creation order")
+	assert.Equal(t, "http://ya.ru", urls[0].OriginalURL)
+	assert.Equal(t, []int64{0, 2}, []int64{urls[0].Variants[0].Clicks, urls[0].Variants[1].Clicks})
+	assert.Equal(t, "second", urls[1].ShortURL)
+	require.NotNil(t, urls[1].ActiveFrom)
+	assert.True(t, launch.Equal(*urls[1].ActiveFrom))
+
+	_, err = reloaded.GetOriginalURL(ctx, "deleted")
+	assert.ErrorIs(t, err, cutter.ErrorDeletedURL, "deletion survives reload")
+	urls, err = reloaded.GetUserURLs(other)
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	assert.Equal(t, "foreign", urls[0].ShortURL)
+	_, err = reloaded.GetUserURLs(context.Background())
+	assert.Error(t, err, "no user in context")
+}
+
+func TestUserURLsContract(t *testing.T) {
+	s, err := New(context.Background(), config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json")})
+	require.NoError(t, err)
+	storetest.UserURLs(t, s)
+}
+
+func TestUploadBatch(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json")}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, "user")
+	require.NoError(t, s.Add(ctx, "http://ya.ru", "taken", jsonobject.LinkOptions{}))
+
+	_, err = s.UploadBatch(ctx, jsonobject.Batch{
+		{ID: "1", OriginalURL: "http://mail.ru", ShortURL: "first"},
+		{ID: "2", OriginalURL: "http://ok.ru", ShortURL: "taken"},
+	})
+	var taken *cutter.ShortURLTakenError
+	require.ErrorAs(t, err, &taken)
+	assert.Equal(t, "taken", taken.Short)
+	short, err := s.GetShortURL(ctx, "http://mail.ru")
+	require.NoError(t, err)
+	assert.Empty(t, short, "batch is not written partially")
+
+	res, err := s.UploadBatch(ctx, jsonobject.Batch{
+		{ID: "1", OriginalURL: "http://mail.ru", ShortURL: "first"},
+		{ID: "2", OriginalURL: "http://ya.ru", ShortURL: "second"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "taken"}, []string{res[0].ShortURL, res[1].ShortURL}, "added url keeps its short")
+	require.NoError(t, s.CloseDB())
+
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	urls, err := reloaded.GetUserURLs(ctx)
+	require.NoError(t, err)
+	assert.Len(t, urls, 2)
+}
+
+func TestClickStats(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json")}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, "user")
+	variants := jsonobject.Variants{{Name: "A", URL: "http://ya.ru/a", Weight: 70}, {Name: "B", URL: "http://ya.ru/b", Weight: 30}}
+	require.NoError(t, s.Add(ctx, "http://ya.ru", "short", jsonobject.LinkOptions{Variants: variants, StickyVariant: true}))
+
+	day := time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC)
+	require.NoError(t, s.AddClicks(ctx, []jsonobject.ClickEvent{
+		{Short: "short", At: day, Referrer: "https://google.com/", UserAgent: "curl/8.0", IPHash: "a", Variant: "A"},
+		{Short: "short", At: day.Add(2 * time.Hour), UserAgent: "curl/8.0", IPHash: "a", Variant: "A"},
+		{Short: "short", At: day.Add(3 * time.Hour), IPHash: "b"},
+		{Short: "other", At: day},
+	}))
+
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	item, err := reloaded.GetOriginalURL(ctx, "short")
+	require.NoError(t, err)
+	assert.Equal(t, variants, item.Variants)
+	assert.True(t, item.StickyVariant)
+	stats, err := reloaded.GetClickStats(ctx, "user", "short")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Total)
+	assert.Equal(t, int64(2), stats.Unique)
+	assert.Equal(t, []jsonobject.DailyClicks{{Date: "2024-06-01", Clicks: 1}, {Date: "2024-06-02", Clicks: 2}}, stats.Daily)
+	assert.ElementsMatch(t, []jsonobject.ClickCount{{Name: "https://google.com/", Clicks: 1}, {Name: "", Clicks: 2}}, stats.Referrers)
+	assert.ElementsMatch(t, []jsonobject.ClickCount{{Name: "curl/8.0", Clicks: 2}, {Name: "", Clicks: 1}}, stats.UserAgents)
+	assert.Equal(t, []jsonobject.ClickCount{{Name: "A", Clicks: 2}}, stats.Variants)
+	clicks, err := reloaded.CountClicks(ctx, "short")
+	require.NoError(t, err)
+	assert.Equal(t, stats.Total, clicks)
+
+	_, err = reloaded.GetClickStats(ctx, "another", "short")
+	assert.ErrorIs(t, err, cutter.ErrorNotOwner)
+	_, err = reloaded.GetClickStats(ctx, "user", "unknown")
+	assert.ErrorIs(t, err, cutter.ErrorURLNotFound)
+
+	_, err = reloaded.DeleteURLs(ctx, []jsonobject.DeleteItem{{UserID: "user", Short: "short"}})
+	require.NoError(t, err)
+	_, err = reloaded.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Add(ctx, "http://mail.ru", "short", jsonobject.LinkOptions{}))
+	stats, err = reloaded.GetClickStats(ctx, "user", "short")
+	require.NoError(t, err)
+	assert.Zero(t, stats.Total, "clicks of purged link are removed")
+	data, err := os.ReadFile(conf.FileStoreName + clicksFileSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"), "clicks file is compacted")
+}
+
+func TestRules(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json")}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, "user")
+	require.NoError(t, s.Add(ctx, "http://site.ru", "site", jsonobject.LinkOptions{}))
+	rule := jsonobject.Rule{Devices: []string{"mobile"}, URL: "http://app.ru"}
+
+	_, err = s.UpdateRules(ctx, "other", "site", nil)
+	assert.ErrorIs(t, err, cutter.ErrorNotOwner)
+	_, err = s.GetRules(ctx, "user", "unknown")
+	assert.ErrorIs(t, err, cutter.ErrorURLNotFound)
+	_, err = s.UpdateRules(ctx, "user", "site", func(jsonobject.Rules) (jsonobject.Rules, error) {
+		return nil, cutter.ErrorRuleNotFound
+	})
+	assert.ErrorIs(t, err, cutter.ErrorRuleNotFound)
+
+	res, err := s.UpdateRules(ctx, "user", "site", func(rules jsonobject.Rules) (jsonobject.Rules, error) {
+		assert.Empty(t, rules)
+		return append(rules, rule), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, jsonobject.Rules{rule}, res)
+	res[0].URL = "http://changed.ru"
+	item, err := s.GetOriginalURL(ctx, "site")
+	require.NoError(t, err)
+	assert.Equal(t, jsonobject.Rules{rule}, item.Rules, "result is a copy")
+
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	res, err = reloaded.GetRules(ctx, "user", "site")
+	require.NoError(t, err)
+	assert.Equal(t, jsonobject.Rules{rule}, res, "rules are saved to file")
+}
+
+func TestSnapshotLog(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json"), FileSync: config.FileSyncAlways}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, "user")
+	require.NoError(t, s.Add(ctx, "http://ya.ru", "first", jsonobject.LinkOptions{}))
+	require.NoError(t, s.Add(ctx, "http://mail.ru", "second", jsonobject.LinkOptions{}))
+	require.NoError(t, s.UpdateURL(ctx, "user", "first", "http://ya.ru/new"))
+	lineCount := func(name string) int {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		return strings.Count(string(data), "\n")
+	}
+	assert.Zero(t, lineCount(conf.FileStoreName), "changes are appended to the log")
+	assert.Equal(t, 3, lineCount(conf.FileStoreName+logFileSuffix))
+
+	require.NoError(t, s.compactLogs())
+	assert.Equal(t, 2, lineCount(conf.FileStoreName), "one snapshot line per item")
+	assert.Zero(t, lineCount(conf.FileStoreName+logFileSuffix), "log is empty after compaction")
+	require.NoError(t, s.Add(ctx, "http://ok.ru", "third", jsonobject.LinkOptions{}))
+	require.NoError(t, s.CloseDB())
+
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	defer reloaded.CloseDB()
+	for short, original := range map[string]string{"first": "http://ya.ru/new", "second": "http://mail.ru", "third": "http://ok.ru"} {
+		item, err := reloaded.GetOriginalURL(ctx, short)
+		require.NoError(t, err)
+		assert.Equal(t, original, item.OriginalURL)
+	}
+	assert.Equal(t, 3, lineCount(conf.FileStoreName), "log of previous run is moved to snapshot")
+	assert.Zero(t, lineCount(conf.FileStoreName+logFileSuffix))
+}
+
+func TestCorruptTrailingRecord(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json")}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, "user")
+	require.NoError(t, s.Add(ctx, "http://ya.ru", "first", jsonobject.LinkOptions{}))
+	require.NoError(t, s.AddClicks(ctx, []jsonobject.ClickEvent{{Short: "first", At: time.Now()}}))
+	require.NoError(t, s.CloseDB())
+	appendRaw := func(name, data string) {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0666)
+		require.NoError(t, err)
+		_, err = f.WriteString(data)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+	// запись оборвана при сбое
+	appendRaw(conf.FileStoreName+logFileSuffix, `{"uuid":2,"short_url":"second","original_u`)
+	appendRaw(conf.FileStoreName+clicksFileSuffix, `{"at":"2024-06`)
+
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	_, err = reloaded.GetOriginalURL(ctx, "first")
+	assert.NoError(t, err)
+	_, err = reloaded.GetOriginalURL(ctx, "second")
+	assert.Error(t, err, "corrupt record is skipped")
+	clicks, err := reloaded.CountClicks(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), clicks)
+	require.NoError(t, reloaded.AddClicks(ctx, []jsonobject.ClickEvent{{Short: "first", At: time.Now()}}))
+	require.NoError(t, reloaded.CloseDB())
+
+	reloaded, err = New(context.Background(), conf)
+	require.NoError(t, err, "file is truncated to the last whole record before appending")
+	clicks, err = reloaded.CountClicks(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), clicks)
+	require.NoError(t, reloaded.CloseDB())
+
+	appendRaw(conf.FileStoreName+clicksFileSuffix, "broken\n"+`{"short_url":"first"}`+"\n")
+	_, err = New(context.Background(), conf)
+	assert.Error(t, err, "corrupt record in the middle of the file")
+}
+
+// partialWriter записывает в w половину данных и возвращает ошибку, как при нехватке места на диске.
+type partialWriter struct {
+	w io.Writer
+}
+
+func (p partialWriter) Write(data []byte) (int, error) {
+	n, _ := p.w.Write(data[:len(data)/2])
+	return n, errors.New("no space left on device")
+}
+
+func TestFailedAppend(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json")}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, "user")
+	require.NoError(t, s.Add(ctx, "http://ya.ru", "first", jsonobject.LinkOptions{}))
+	require.NoError(t, s.Add(ctx, "http://once.ru", "once", jsonobject.LinkOptions{MaxClicks: 1}))
+
+	s.itemsLog.w = bufio.NewWriter(partialWriter{w: s.itemsLog.file})
+	err = s.Add(ctx, "http://mail.ru", "second", jsonobject.LinkOptions{})
+	require.Error(t, err)
+	short, err := s.GetShortURL(ctx, "http://mail.ru")
+	require.NoError(t, err)
+	assert.Empty(t, short, "failed add is not published")
+	_, err = s.GetOriginalURL(ctx, "second")
+	assert.Error(t, err, "failed add is not published")
+
+	s.itemsLog.w = bufio.NewWriter(partialWriter{w: s.itemsLog.file})
+	require.Error(t, s.UseClick(ctx, "once"))
+	_, err = s.GetOriginalURL(ctx, "once")
+	assert.NoError(t, err, "failed click is not counted")
+
+	// после ошибки журнал обрезан до последней целой записи и снова пишется
+	require.NoError(t, s.Add(ctx, "http://mail.ru", "second", jsonobject.LinkOptions{}))
+	require.NoError(t, s.CloseDB())
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	for _, short := range []string{"first", "once", "second"} {
+		_, err = reloaded.GetOriginalURL(ctx, short)
+		assert.NoError(t, err, short)
+	}
+}
+
+// failingSync файл журнала, который не сбрасывается на диск.
+type failingSync struct {
+	*os.File
+}
+
+func (f failingSync) Sync() error {
+	return errors.New("input/output error")
+}
+
+func TestFailedSync(t *testing.T) {
+	conf := config.Config{FileStoreName: filepath.Join(t.TempDir(), "urls.json"), FileSync: config.FileSyncAlways}
+	s, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, "user")
+	require.NoError(t, s.Add(ctx, "http://ya.ru", "first", jsonobject.LinkOptions{}))
+	logName := conf.FileStoreName + logFileSuffix
+	before, err := os.ReadFile(logName)
+	require.NoError(t, err)
+
+	file := s.itemsLog.file
+	s.itemsLog.file = failingSync{File: file.(*os.File)}
+	require.Error(t, s.Add(ctx, "http://mail.ru", "second", jsonobject.LinkOptions{}))
+	short, err := s.GetShortURL(ctx, "http://mail.ru")
+	require.NoError(t, err)
+	assert.Empty(t, short, "failed add is not published")
+	after, err := os.ReadFile(logName)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after), "failed add is not left in the log")
+
+	s.itemsLog.file = file
+	require.NoError(t, s.CloseDB())
+	reloaded, err := New(context.Background(), conf)
+	require.NoError(t, err)
+	_, err = reloaded.GetOriginalURL(ctx, "second")
+	assert.Error(t, err, "failed add does not come back after restart")
+	_, err = reloaded.GetOriginalURL(ctx, "first")
+	assert.NoError(t, err)
+}
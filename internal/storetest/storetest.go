@@ -0,0 +1,53 @@
+// Package storetest содержит общие проверки поведения хранилищ cutter.Store.
+// Одни и те же сценарии прогоняются для файлового хранилища и для хранилища в БД.
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmad1989/urlcut/internal/config"
+	"github.com/dmad1989/urlcut/internal/cutter"
+	"github.com/dmad1989/urlcut/internal/jsonobject"
+)
+
+// UserURLs проверяет, что GetUserURLs возвращает только неудаленные сокращения пользователя.
+// Коды, URL и пользователи уникальны для каждого запуска, поэтому хранилище может быть общим.
+func UserURLs(t *testing.T, s cutter.Store) {
+	t.Helper()
+	run := time.Now().UnixNano()
+	user := fmt.Sprintf("storetest-user-%d", run)
+	other := fmt.Sprintf("storetest-other-%d", run)
+	code := func(name string) string { return fmt.Sprintf("st%d-%s", run, name) }
+	url := func(name string) string { return fmt.Sprintf("http://storetest.example/%d/%s", run, name) }
+	ctx := context.WithValue(context.Background(), config.UserCtxKey, user)
+	otherCtx := context.WithValue(context.Background(), config.UserCtxKey, other)
+
+	require.NoError(t, s.Add(ctx, url("first"), code("first"), jsonobject.LinkOptions{}))
+	require.NoError(t, s.Add(ctx, url("second"), code("second"), jsonobject.LinkOptions{}))
+	require.NoError(t, s.Add(ctx, url("deleted"), code("deleted"), jsonobject.LinkOptions{}))
+	require.NoError(t, s.Add(otherCtx, url("foreign"), code("foreign"), jsonobject.LinkOptions{}))
+	res, err := s.DeleteURLs(ctx, []jsonobject.DeleteItem{{UserID: user, Short: code("deleted")}})
+	require.NoError(t, err)
+	assert.Equal(t, []jsonobject.DeleteStatus{jsonobject.DeleteDone}, res)
+
+	urls, err := s.GetUserURLs(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{code("first"), code("second")}, shortsOf(urls), "deleted url is not listed")
+	urls, err = s.GetUserURLs(otherCtx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{code("foreign")}, shortsOf(urls))
+}
+
+func shortsOf(urls jsonobject.Batch) []string {
+	res := make([]string, len(urls))
+	for i, u := range urls {
+		res[i] = u.ShortURL
+	}
+	return res
+}
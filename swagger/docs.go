@@ -20,24 +20,188 @@ const docTemplate = `{
         "/": {
             "post": {
                 "consumes": [
-                    "application/json"
+                    "plain/text"
                 ],
                 "produces": [
                     "plain/text"
                 ],
                 "tags": [
-                    "UserURLs"
+                    "Cut"
+                ],
+                "summary": "Запрос на сокращение URL",
+                "operationId": "cutterText",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Желаемое сокращение",
+                        "name": "alias",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Момент окончания действия сокращения, RFC3339",
+                        "name": "expires_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Момент начала работы сокращения, RFC3339",
+                        "name": "active_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Срок действия сокращения в секундах",
+                        "name": "ttl",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество переходов, после которого сокращение перестает работать",
+                        "name": "max_clicks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Передача параметров запроса перехода: keep, override или append",
+                        "name": "pass_query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Дописывать к URL путь после сокращения",
+                        "name": "pass_path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Способ перехода: 301, 302, 307, 308, meta-refresh или interstitial",
+                        "name": "redirect_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сокращения для страницы предпросмотра",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Домен сокращения из доменов сервиса, по умолчанию - домен запроса",
+                        "name": "domain",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Запрос на удаление сокращеных URL",
-                "operationId": "deleteUserUrls",
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "201": {
+                        "description": "Сокращенный URL",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "URL уже сокращен или сокращение (alias) занято",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "URL отклонен политикой сервиса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/internal/purge": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "Статистика окончательного удаления сокращений",
+                "operationId": "purgeStats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jsonobject.PurgeStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "IP не входит в доверенную подсеть",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/qr/{short}": {
+            "get": {
+                "description": "Кодирует в QR-код полный сокращенный URL. Сторона PNG кратна размеру символа и не превышает size.",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "Operate"
+                ],
+                "summary": "QR-код сокращенного URL",
+                "operationId": "qr",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сторона изображения в пикселях, от 64 до 2048, по умолчанию 256",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Формат изображения: png или svg, по умолчанию png",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Уровень коррекции ошибок: L, M, Q или H, по умолчанию M",
+                        "name": "level",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Изображение QR-кода",
+                        "schema": {
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -45,6 +209,18 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted, url has expired или url click limit is exhausted",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -62,6 +238,23 @@ const docTemplate = `{
                 ],
                 "summary": "Запрос на сокращение URL",
                 "operationId": "cutterJSON",
+                "parameters": [
+                    {
+                        "description": "URL и параметры сокращения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/jsonobject.Request"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Добавить в ответ адрес QR-кода",
+                        "name": "qr",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "201": {
                         "description": "Created",
@@ -80,6 +273,18 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "409": {
+                        "description": "Сокращение (alias) уже занято",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "URL отклонен политикой сервиса",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -97,6 +302,26 @@ const docTemplate = `{
                 ],
                 "summary": "Запрос на сокращение списка URL",
                 "operationId": "cutterBatch",
+                "parameters": [
+                    {
+                        "description": "Список URL и параметров сокращения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/jsonobject.BatchItem"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Добавить в ответ адреса QR-кодов",
+                        "name": "qr",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "201": {
                         "description": "Created",
@@ -118,6 +343,18 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "409": {
+                        "description": "Сокращение (alias) уже занято",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "URL отклонен политикой сервиса",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -161,36 +398,49 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/ping": {
-            "get": {
+            },
+            "delete": {
+                "description": "Удаление выполняется асинхронно, состояние задачи доступно по ее ID, см. deleteJob",
                 "consumes": [
-                    "*/*"
+                    "application/json"
                 ],
                 "produces": [
-                    "plain/text"
+                    "application/json"
                 ],
                 "tags": [
-                    "Info"
+                    "UserURLs"
+                ],
+                "summary": "Запрос на удаление сокращеных URL",
+                "operationId": "deleteUserUrls",
+                "parameters": [
+                    {
+                        "description": "Сокращения для удаления: коды на домене запроса или домен/код",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 ],
-                "summary": "Проверка соединения с БД",
-                "operationId": "ping",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Созданная задача удаления",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/jsonobject.DeleteJob"
                         }
                     },
-                    "401": {
-                        "description": "Ошибка авторизации",
+                    "400": {
+                        "description": "Ошибка",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Ошибка",
+                    "401": {
+                        "description": "Ошибка авторизации",
                         "schema": {
                             "type": "string"
                         }
@@ -198,28 +448,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/{path}": {
+        "/api/user/urls/delete-jobs/{id}": {
             "get": {
-                "consumes": [
-                    "plain/text"
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "Operate"
+                    "UserURLs"
                 ],
-                "summary": "Переход по сокращеному URL",
-                "operationId": "redirect",
+                "summary": "Состояние задачи удаления сокращений пользователя",
+                "operationId": "deleteJob",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Сокращенный url",
-                        "name": "path",
+                        "description": "ID задачи",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "307": {
-                        "description": "Переход по сокращенному URL"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jsonobject.DeleteJob"
+                        }
                     },
                     "400": {
                         "description": "Ошибка",
@@ -233,42 +486,1444 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "410": {
-                        "description": "url was deleted",
+                    "404": {
+                        "description": "Задача не найдена",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "jsonobject.BatchItem": {
-            "type": "object",
-            "properties": {
-                "correlation_id": {
-                    "type": "string",
-                    "example": "1"
-                },
-                "original_url": {
-                    "description": "URL для сокращения",
-                    "type": "string",
-                    "example": "http://ya.ru"
-                },
-                "short_url": {
-                    "description": "Сокращенный URL",
-                    "type": "string",
-                    "example": "http://localhost:8080/rjhsha"
-                }
-            }
         },
-        "jsonobject.Response": {
-            "type": "object",
-            "properties": {
-                "result": {
-                    "type": "string",
-                    "example": "http://localhost:8080/rjhsha"
+        "/api/user/urls/restore": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Восстановление удаленных сокращений пользователя",
+                "operationId": "restore",
+                "parameters": [
+                    {
+                        "description": "Сокращения для восстановления: коды на домене запроса или домен/код",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Восстановленные сокращения",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Ни одно сокращение не восстановлено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/urls/trash": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Удаленные сокращения пользователя, начиная с удаленных последними",
+                "operationId": "trash",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/jsonobject.BatchItem"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Удаленных сокращений нет",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/urls/{short}": {
+            "patch": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Замена оригинального URL сокращения пользователя",
+                "operationId": "retarget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый URL",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/jsonobject.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jsonobject.BatchItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Сокращение создано другим пользователем",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение не найдено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "URL уже сокращен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "URL отклонен политикой сервиса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/urls/{short}/history": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Прежние оригинальные URL сокращения пользователя, начиная с самого позднего",
+                "operationId": "history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/jsonobject.HistoryItem"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "URL сокращения не менялся",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Сокращение создано другим пользователем",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение не найдено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/urls/{short}/rollback": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Возврат сокращению пользователя предыдущего оригинального URL",
+                "operationId": "rollback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jsonobject.BatchItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Сокращение создано другим пользователем",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение не найдено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "URL сокращения не менялся или прежний URL уже сокращен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/urls/{short}/rules": {
+            "get": {
+                "description": "При переходе выполняется первое правило, все условия которого выполнены;\nесли ни одно правило не подошло - переход на оригинальный URL сокращения.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Правила перехода сокращения пользователя в порядке проверки",
+                "operationId": "rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/jsonobject.Rule"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Правил перехода нет",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Сокращение создано другим пользователем",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение не найдено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Пустой список удаляет все правила. URL правил проверяются политикой сервиса, как при сокращении.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Замена всех правил перехода сокращения пользователя",
+                "operationId": "setRules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Правила перехода в порядке проверки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/jsonobject.Rule"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/jsonobject.Rule"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Правила удалены",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Сокращение создано другим пользователем",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение не найдено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "URL правила отклонен политикой сервиса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Добавление правила перехода в конец списка правил сокращения пользователя",
+                "operationId": "addRule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Правило перехода",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/jsonobject.Rule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/jsonobject.Rule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Сокращение создано другим пользователем",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение не найдено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "URL правила отклонен политикой сервиса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/urls/{short}/rules/{index}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Замена правила перехода сокращения пользователя",
+                "operationId": "updateRule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер правила в списке, с нуля",
+                        "name": "index",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Правило перехода",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/jsonobject.Rule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/jsonobject.Rule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Сокращение создано другим пользователем",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение или правило не найдено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "URL правила отклонен политикой сервиса",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Следующие правила сдвигаются на место удаленного.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Удаление правила перехода сокращения пользователя",
+                "operationId": "deleteRule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер правила в списке, с нуля",
+                        "name": "index",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Оставшиеся правила",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/jsonobject.Rule"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Правил больше нет",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Сокращение создано другим пользователем",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение или правило не найдено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/urls/{short}/stats": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UserURLs"
+                ],
+                "summary": "Статистика переходов по сокращению пользователя",
+                "operationId": "stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращение",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jsonobject.LinkStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Сокращение создано другим пользователем",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение не найдено",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ping": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "plain/text"
+                ],
+                "tags": [
+                    "Info"
+                ],
+                "summary": "Проверка соединения с БД",
+                "operationId": "ping",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/{path}": {
+            "get": {
+                "description": "Путь после сокращения (/{path}/...) и параметры запроса передаются в URL по настройкам сокращения.\nСокращение ищется на домене сокращений из заголовка Host: один код может существовать на разных доменах.\nURL перехода выбирается по правилам перехода сокращения, см. /api/user/urls/{short}/rules.\nЕсли правила не подошли, для сокращения с вариантами URL вариант выбирается случайно по весам;\nпри sticky_variant выбранный вариант закрепляется за посетителем cookie urlcut_variant_{path}.\nКод редиректа или страница перехода выбираются по redirect_type сокращения, по умолчанию - 307.\nЗапрос /{path}+ или с параметром preview=1 вместо перехода отдает страницу предпросмотра.",
+                "consumes": [
+                    "plain/text"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Operate"
+                ],
+                "summary": "Переход по сокращеному URL",
+                "operationId": "redirect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращенный url",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Показать страницу предпросмотра вместо перехода",
+                        "name": "preview",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Форма ввода пароля для сокращения с паролем, страница перехода meta-refresh, interstitial или предпросмотра",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "301": {
+                        "description": "Переход по сокращенному URL, redirect_type 301"
+                    },
+                    "302": {
+                        "description": "Переход по сокращенному URL, redirect_type 302"
+                    },
+                    "307": {
+                        "description": "Переход по сокращенному URL"
+                    },
+                    "308": {
+                        "description": "Переход по сокращенному URL, redirect_type 308"
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Ошибка авторизации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение еще не работает (active_from) - пустой ответ или страница \\\"скоро\\",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted, url has expired или url click limit is exhausted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Operate"
+                ],
+                "summary": "Переход по сокращенному URL с паролем",
+                "operationId": "unlock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сокращенный url",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Пароль сокращения",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница перехода для redirect_type meta-refresh и interstitial",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "303": {
+                        "description": "Переход по сокращенному URL"
+                    },
+                    "400": {
+                        "description": "Ошибка",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Форма ввода пароля с сообщением о неверном пароле",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Сокращение еще не работает (active_from)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "url was deleted, url has expired или url click limit is exhausted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "too many wrong password attempts",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "jsonobject.BatchItem": {
+            "type": "object",
+            "properties": {
+                "active_from": {
+                    "description": "Момент, до которого сокращение не работает: переход отвечает 404 или страницей \"скоро\"",
+                    "type": "string",
+                    "example": "2024-05-01T09:00:00Z"
+                },
+                "alias": {
+                    "description": "Желаемое сокращение вместо сгенерированного",
+                    "type": "string",
+                    "example": "spring-sale"
+                },
+                "correlation_id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "deleted_at": {
+                    "description": "Момент удаления сокращения, заполняется только в корзине",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "domain": {
+                    "description": "Домен сокращения из доменов сокращений сервиса. Пусто - домен запроса, если он из доменов сервиса,\nиначе домен по умолчанию",
+                    "type": "string",
+                    "example": "go.brand.com"
+                },
+                "expires_at": {
+                    "description": "Момент, после которого сокращение перестает работать",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "max_clicks": {
+                    "description": "Количество переходов, после которого сокращение перестает работать, 0 - без ограничения",
+                    "type": "integer",
+                    "example": 1
+                },
+                "original_url": {
+                    "description": "URL для сокращения",
+                    "type": "string",
+                    "example": "http://ya.ru"
+                },
+                "pass_path": {
+                    "description": "Дописывать к URL сокращения путь после сокращения: /abc/extra переходит на URL/extra",
+                    "type": "boolean",
+                    "example": true
+                },
+                "pass_query": {
+                    "description": "Передача параметров запроса перехода в URL сокращения, если параметр уже есть в URL:\nkeep - остается значение из URL, override - заменяется значением перехода, append - добавляется к нему.\nПусто - параметры перехода не передаются",
+                    "type": "string",
+                    "example": "keep"
+                },
+                "password": {
+                    "description": "Пароль для перехода по сокращению",
+                    "type": "string",
+                    "example": "secret"
+                },
+                "qr": {
+                    "description": "Адрес QR-кода сокращенного URL, заполняется по запросу",
+                    "type": "string",
+                    "example": "http://localhost:8080/api/qr/rjhsha"
+                },
+                "redirect_type": {
+                    "description": "Способ перехода: HTTP-редирект 301, 302, 307 или 308, HTML-страница meta-refresh или interstitial\nсо ссылкой для перехода. Пусто - способ по умолчанию сервиса",
+                    "type": "string",
+                    "example": "301"
+                },
+                "scheduled": {
+                    "description": "Сокращение еще не работает: active_from не наступил",
+                    "type": "boolean",
+                    "example": true
+                },
+                "short_url": {
+                    "description": "Сокращенный URL",
+                    "type": "string",
+                    "example": "http://localhost:8080/rjhsha"
+                },
+                "sticky_variant": {
+                    "description": "Закреплять выбранный вариант за посетителем через cookie, чтобы он не переключался между вариантами",
+                    "type": "boolean",
+                    "example": true
+                },
+                "title": {
+                    "description": "Название сокращения, показывается на странице предпросмотра",
+                    "type": "string",
+                    "example": "Весенняя распродажа"
+                },
+                "ttl": {
+                    "description": "Срок действия сокращения в секундах, альтернатива ExpiresAt, не больше 100 лет",
+                    "type": "integer",
+                    "example": 86400
+                },
+                "variants": {
+                    "description": "Варианты URL для A/B-теста: переход выполняется на один из вариантов, выбранный по весам.\nПусто - переход на URL сокращения",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jsonobject.Variant"
+                    }
+                }
+            }
+        },
+        "jsonobject.ClickCount": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "name": {
+                    "type": "string",
+                    "example": "https://ya.ru/"
+                }
+            }
+        },
+        "jsonobject.DailyClicks": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-06-01"
+                }
+            }
+        },
+        "jsonobject.DeleteJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Момент создания задачи",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "deleted": {
+                    "description": "Количество удаленных сокращений",
+                    "type": "integer",
+                    "example": 2
+                },
+                "error": {
+                    "description": "Ошибка, прервавшая задачу в состоянии failed",
+                    "type": "string",
+                    "example": ""
+                },
+                "id": {
+                    "description": "ID задачи",
+                    "type": "string",
+                    "example": "0b6f4a3e-6c1e-4a8e-9d3b-4a1c2f0e7d5a"
+                },
+                "not_found": {
+                    "description": "Сокращения, которых нет в хранилище",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "unknown"
+                    ]
+                },
+                "not_owned": {
+                    "description": "Сокращения, созданные другими пользователями",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "foreign"
+                    ]
+                },
+                "processed": {
+                    "description": "Количество обработанных сокращений",
+                    "type": "integer",
+                    "example": 3
+                },
+                "state": {
+                    "description": "Состояние: pending, running, done или failed",
+                    "type": "string",
+                    "example": "done"
+                },
+                "updated_at": {
+                    "description": "Момент последнего изменения состояния",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                }
+            }
+        },
+        "jsonobject.HeaderMatch": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Имя заголовка",
+                    "type": "string",
+                    "example": "X-Country"
+                },
+                "op": {
+                    "description": "Сравнение без учета регистра: equals, prefix, contains или exists - заголовок передан; пусто - equals",
+                    "type": "string",
+                    "example": "equals"
+                },
+                "value": {
+                    "description": "Значение для сравнения",
+                    "type": "string",
+                    "example": "RU"
+                }
+            }
+        },
+        "jsonobject.HistoryItem": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "description": "Момент, когда URL был заменен",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "original_url": {
+                    "description": "Прежний URL",
+                    "type": "string",
+                    "example": "http://ya.ru"
+                }
+            }
+        },
+        "jsonobject.LinkStats": {
+            "type": "object",
+            "properties": {
+                "browsers": {
+                    "description": "Переходы по браузерам",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jsonobject.ClickCount"
+                    }
+                },
+                "daily": {
+                    "description": "Переходы по дням, начиная с самого раннего",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jsonobject.DailyClicks"
+                    }
+                },
+                "os": {
+                    "description": "Переходы по операционным системам",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jsonobject.ClickCount"
+                    }
+                },
+                "referrers": {
+                    "description": "Самые частые источники перехода, прямые переходы - \"(direct)\"",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jsonobject.ClickCount"
+                    }
+                },
+                "total": {
+                    "description": "Количество переходов",
+                    "type": "integer",
+                    "example": 100
+                },
+                "unique": {
+                    "description": "Количество уникальных посетителей (по IP)",
+                    "type": "integer",
+                    "example": 40
+                },
+                "variants": {
+                    "description": "Переходы по вариантам URL, только для сокращений с вариантами",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jsonobject.ClickCount"
+                    }
+                }
+            }
+        },
+        "jsonobject.PurgeStats": {
+            "type": "object",
+            "properties": {
+                "last_purged": {
+                    "description": "Количество сокращений, удаленных последним запуском",
+                    "type": "integer",
+                    "example": 10
+                },
+                "last_run_at": {
+                    "description": "Момент последнего запуска, отсутствует до первого запуска",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "total_purged": {
+                    "description": "Количество сокращений, удаленных с момента старта сервиса",
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "jsonobject.Request": {
+            "type": "object",
+            "properties": {
+                "active_from": {
+                    "description": "Момент, до которого сокращение не работает: переход отвечает 404 или страницей \"скоро\"",
+                    "type": "string",
+                    "example": "2024-05-01T09:00:00Z"
+                },
+                "alias": {
+                    "description": "Желаемое сокращение вместо сгенерированного",
+                    "type": "string",
+                    "example": "spring-sale"
+                },
+                "domain": {
+                    "description": "Домен сокращения из доменов сокращений сервиса. Пусто - домен запроса, если он из доменов сервиса,\nиначе домен по умолчанию",
+                    "type": "string",
+                    "example": "go.brand.com"
+                },
+                "expires_at": {
+                    "description": "Момент, после которого сокращение перестает работать",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "max_clicks": {
+                    "description": "Количество переходов, после которого сокращение перестает работать, 0 - без ограничения",
+                    "type": "integer",
+                    "example": 1
+                },
+                "pass_path": {
+                    "description": "Дописывать к URL сокращения путь после сокращения: /abc/extra переходит на URL/extra",
+                    "type": "boolean",
+                    "example": true
+                },
+                "pass_query": {
+                    "description": "Передача параметров запроса перехода в URL сокращения, если параметр уже есть в URL:\nkeep - остается значение из URL, override - заменяется значением перехода, append - добавляется к нему.\nПусто - параметры перехода не передаются",
+                    "type": "string",
+                    "example": "keep"
+                },
+                "password": {
+                    "description": "Пароль для перехода по сокращению",
+                    "type": "string",
+                    "example": "secret"
+                },
+                "redirect_type": {
+                    "description": "Способ перехода: HTTP-редирект 301, 302, 307 или 308, HTML-страница meta-refresh или interstitial\nсо ссылкой для перехода. Пусто - способ по умолчанию сервиса",
+                    "type": "string",
+                    "example": "301"
+                },
+                "sticky_variant": {
+                    "description": "Закреплять выбранный вариант за посетителем через cookie, чтобы он не переключался между вариантами",
+                    "type": "boolean",
+                    "example": true
+                },
+                "title": {
+                    "description": "Название сокращения, показывается на странице предпросмотра",
+                    "type": "string",
+                    "example": "Весенняя распродажа"
+                },
+                "ttl": {
+                    "description": "Срок действия сокращения в секундах, альтернатива ExpiresAt, не больше 100 лет",
+                    "type": "integer",
+                    "example": 86400
+                },
+                "url": {
+                    "type": "string",
+                    "example": "http://ya.ru"
+                },
+                "variants": {
+                    "description": "Варианты URL для A/B-теста: переход выполняется на один из вариантов, выбранный по весам.\nПусто - переход на URL сокращения",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jsonobject.Variant"
+                    }
+                }
+            }
+        },
+        "jsonobject.Response": {
+            "type": "object",
+            "properties": {
+                "qr": {
+                    "description": "Адрес QR-кода сокращенного URL, заполняется по запросу",
+                    "type": "string",
+                    "example": "http://localhost:8080/api/qr/rjhsha"
+                },
+                "result": {
+                    "type": "string",
+                    "example": "http://localhost:8080/rjhsha"
+                }
+            }
+        },
+        "jsonobject.Rule": {
+            "type": "object",
+            "properties": {
+                "devices": {
+                    "description": "Классы устройства клиента по User-Agent: mobile, tablet, desktop или bot",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "mobile"
+                    ]
+                },
+                "header": {
+                    "description": "Условие на заголовок запроса перехода",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/jsonobject.HeaderMatch"
+                        }
+                    ]
+                },
+                "languages": {
+                    "description": "Языки, подходящие под наиболее предпочтительный язык клиента из Accept-Language:\nязык без региона (en) подходит и для всех его регионов (en-US, en-GB)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ru"
+                    ]
+                },
+                "time": {
+                    "description": "Условие на время перехода",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/jsonobject.TimeWindow"
+                        }
+                    ]
+                },
+                "url": {
+                    "description": "Адрес перехода",
+                    "type": "string",
+                    "example": "https://apps.apple.com/app/id1"
+                }
+            }
+        },
+        "jsonobject.TimeWindow": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "Ежедневный интервал HH:MM, начало включается, конец - нет; начало позже конца - интервал через полночь",
+                    "type": "string",
+                    "example": "09:00"
+                },
+                "location": {
+                    "description": "Часовой пояс IANA для From, To и Weekdays, пусто - UTC",
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                },
+                "not_after": {
+                    "description": "Момент окончания действия правила",
+                    "type": "string",
+                    "example": "2024-07-01T00:00:00Z"
+                },
+                "not_before": {
+                    "description": "Момент начала действия правила",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "18:00"
+                },
+                "weekdays": {
+                    "description": "Дни недели: mon, tue, wed, thu, fri, sat, sun",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "mon"
+                    ]
+                }
+            }
+        },
+        "jsonobject.Variant": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "description": "Количество переходов на вариант, заполняется в списке сокращений пользователя",
+                    "type": "integer",
+                    "example": 10
+                },
+                "name": {
+                    "description": "Название варианта, по умолчанию - буква по порядку: A, B, C...",
+                    "type": "string",
+                    "example": "A"
+                },
+                "url": {
+                    "description": "Адрес перехода",
+                    "type": "string",
+                    "example": "https://ya.ru/landing-a"
+                },
+                "weight": {
+                    "description": "Вес варианта: доля переходов на вариант равна его весу, деленному на сумму весов",
+                    "type": "integer",
+                    "example": 70
                 }
             }
         }